@@ -0,0 +1,82 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func benchContainer(name string, image string) corev1.Container {
+	return corev1.Container{
+		Name:  name,
+		Image: image,
+		Args:  []string{"--config", "/etc/app/config.yaml"},
+		Env: []corev1.EnvVar{
+			{Name: "LOG_LEVEL", Value: "info"},
+			{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		},
+		Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/etc/app"}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/ready", Port: intstr.FromString("http")}},
+		},
+	}
+}
+
+func benchPodTemplate(image string) *corev1.PodTemplateSpec {
+	pts := &corev1.PodTemplateSpec{}
+	pts.Labels = map[string]string{"app": "bench"}
+	pts.Spec.Containers = []corev1.Container{benchContainer("app", image), benchContainer("sidecar", "envoy")}
+	pts.Spec.Volumes = []corev1.Volume{
+		{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "bench"}}}},
+	}
+	pts.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "bench"}}
+
+	return pts
+}
+
+func BenchmarkMergeK8s(b *testing.B) {
+	current := benchPodTemplate("nginx:1.0")
+	expected := benchPodTemplate("nginx:2.0")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst := current.DeepCopy()
+		if err := MergeK8s(dst, dst, expected); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWithPodTemplateSpecMerge(b *testing.B) {
+	base := benchPodTemplate("nginx:1.0")
+	override := benchPodTemplate("nginx:2.0")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewPodTemplateBuilder().
+			WithPodTemplateSpec(base).
+			WithPodTemplateSpec(override, Merge)
+	}
+}
+
+func BenchmarkContainerMerge(b *testing.B) {
+	current := benchContainer("app", "nginx:1.0")
+	expected := benchContainer("app", "nginx:2.0")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewContainerBuilder().
+			WithContainer(current.DeepCopy()).
+			WithContainer(&expected, Merge)
+	}
+}
@@ -0,0 +1,151 @@
+package k8sbuilder
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuildFunc is a function that build one object
+type BuildFunc func() (client.Object, error)
+
+// BuilderSet permit to build many related objects together, like statefulset and its governing service
+// Build run builders in the same order they are added. BuildAll run them concurrently, so builders must be independent,
+// unless the set is marked as Sequential.
+type BuilderSet struct {
+	builders    []BuildFunc
+	parallelism int
+	sequential  bool
+	namespace   string
+	labels      map[string]string
+	annotations map[string]string
+}
+
+// NewBuilderSet permit to init builder set
+func NewBuilderSet(builders ...BuildFunc) *BuilderSet {
+	return &BuilderSet{
+		builders: builders,
+	}
+}
+
+// Add permit to add builders on the set
+func (h *BuilderSet) Add(builders ...BuildFunc) *BuilderSet {
+	h.builders = append(h.builders, builders...)
+
+	return h
+}
+
+// WithParallelism permit to set the max number of builders run at the same time by BuildAll
+// Default to GOMAXPROCS
+func (h *BuilderSet) WithParallelism(parallelism int) *BuilderSet {
+	h.parallelism = parallelism
+
+	return h
+}
+
+// Sequential permit to mark the set as having builders that depend on the objects built before them
+// BuildAll then run builders one by one, in the same order they are added.
+func (h *BuilderSet) Sequential() *BuilderSet {
+	h.sequential = true
+
+	return h
+}
+
+// WithMetadata permit to propagate common namespace, labels and annotations on all built objects
+// The namespace is not set on cluster scoped objects, like ClusterRole.
+// They are applied with PropagateMetadata once all objects are built, so labels and annotations already set by builders are keeped.
+func (h *BuilderSet) WithMetadata(namespace string, labels, annotations map[string]string) *BuilderSet {
+	h.namespace = namespace
+	h.labels = labels
+	h.annotations = annotations
+
+	return h
+}
+
+// Build permit to build all objects
+// It stop on the first error
+func (h *BuilderSet) Build() (objects []client.Object, err error) {
+	objects = make([]client.Object, 0, len(h.builders))
+	for i, builder := range h.builders {
+		o, err := builder()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error when build object %d", i)
+		}
+		objects = append(objects, o)
+	}
+	PropagateMetadata(objects, h.namespace, h.labels, h.annotations)
+
+	return objects, nil
+}
+
+// BuildAll permit to build all objects concurrently, with bounded parallelism
+// Builders must be independent, because they are not run in order, except if the set is Sequential.
+// Objects are returned in the same order they are added.
+// It not stop on the first error: all errors are aggregated. Builders not started yet are skipped when context is canceled.
+// A builder that panic not stop the others, its panic is returned as error.
+func (h *BuilderSet) BuildAll(ctx context.Context) (objects []client.Object, err error) {
+	parallelism := h.parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	objects = make([]client.Object, len(h.builders))
+	errs := make([]error, len(h.builders))
+	sem := make(chan struct{}, parallelism)
+	wg := sync.WaitGroup{}
+
+loop:
+	for i, builder := range h.builders {
+		if ctx.Err() != nil {
+			errs[i] = errors.Wrap(ctx.Err(), "Error when build objects")
+			break
+		}
+		select {
+		case <-ctx.Done():
+			errs[i] = errors.Wrap(ctx.Err(), "Error when build objects")
+			break loop
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, builder BuildFunc) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			objects[i], errs[i] = runBuilder(i, builder)
+		}(i, builder)
+
+		// Next builder wait the previous one, so it can use the objects it built
+		if h.sequential {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+
+	if err = utilerrors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+	PropagateMetadata(objects, h.namespace, h.labels, h.annotations)
+
+	return objects, nil
+}
+
+// runBuilder permit to run builder, and return its panic as error
+func runBuilder(i int, builder BuildFunc) (o client.Object, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = nil
+			err = errors.Errorf("Error when build object %d: panic: %v", i, r)
+		}
+	}()
+
+	if o, err = builder(); err != nil {
+		return nil, errors.Wrapf(err, "Error when build object %d", i)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,128 @@
+package k8sbuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestBuilderSetBuildAll(t *testing.T) {
+	var running, maxRunning int32
+	builder := func(name string) BuildFunc {
+		return func() (client.Object, error) {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+		}
+	}
+
+	set := NewBuilderSet().WithParallelism(2)
+	for i := 0; i < 6; i++ {
+		set.Add(builder(fmt.Sprintf("cm-%d", i)))
+	}
+	objects, err := set.BuildAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, objects, 6)
+	for i, o := range objects {
+		assert.Equal(t, fmt.Sprintf("cm-%d", i), o.GetName())
+	}
+	assert.LessOrEqual(t, maxRunning, int32(2))
+
+	// When errors, all are aggregated
+	objects, err = NewBuilderSet(
+		builder("cm"),
+		func() (client.Object, error) { return nil, errors.New("first") },
+		func() (client.Object, error) { return nil, errors.New("second") },
+	).BuildAll(context.Background())
+	assert.Nil(t, objects)
+	assert.ErrorContains(t, err, "Error when build object 1: first")
+	assert.ErrorContains(t, err, "Error when build object 2: second")
+
+	// When context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewBuilderSet(builder("cm")).WithParallelism(1).BuildAll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBuilderSetBuildAllSequential(t *testing.T) {
+	var built client.Object
+	set := NewBuilderSet(
+		func() (client.Object, error) {
+			time.Sleep(5 * time.Millisecond)
+			built = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm"}}
+			return built, nil
+		},
+		func() (client.Object, error) {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: built.GetName()}}, nil
+		},
+	).Sequential()
+
+	objects, err := set.BuildAll(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "cm", objects[1].GetName())
+}
+
+func TestBuilderSetBuildAllPanic(t *testing.T) {
+	objects, err := NewBuilderSet(
+		func() (client.Object, error) {
+			return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm"}}, nil
+		},
+		func() (client.Object, error) {
+			var o client.Object
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: o.GetName()}}, nil
+		},
+	).BuildAll(context.Background())
+	assert.Nil(t, objects)
+	assert.ErrorContains(t, err, "Error when build object 1: panic:")
+}
+
+func TestBuilderSetWithMetadata(t *testing.T) {
+	builders := []BuildFunc{
+		func() (client.Object, error) {
+			return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Labels: map[string]string{"env": "dev"}}}, nil
+		},
+		func() (client.Object, error) {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret"}}, nil
+		},
+	}
+	labels := map[string]string{"app": "test", "env": "prod"}
+	annotations := map[string]string{"owner": "ops"}
+
+	check := func(objects []client.Object) {
+		assert.Len(t, objects, 2)
+		for _, o := range objects {
+			assert.Equal(t, "default", o.GetNamespace())
+			assert.Equal(t, "test", o.GetLabels()["app"])
+			assert.Equal(t, annotations, o.GetAnnotations())
+		}
+		assert.Equal(t, "dev", objects[0].GetLabels()["env"])
+		assert.Equal(t, "prod", objects[1].GetLabels()["env"])
+	}
+
+	// Build
+	objects, err := NewBuilderSet(builders...).WithMetadata("default", labels, annotations).Build()
+	assert.NoError(t, err)
+	check(objects)
+
+	// BuildAll
+	objects, err = NewBuilderSet(builders...).WithMetadata("default", labels, annotations).BuildAll(context.Background())
+	assert.NoError(t, err)
+	check(objects)
+}
@@ -0,0 +1,62 @@
+package k8sbuilder
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnnotationCertificateFingerprint is the annotation that contain the sha256 fingerprint of certificate
+	AnnotationCertificateFingerprint = "k8sbuilder.io/certificate-fingerprint"
+
+	// AnnotationCertificateNotAfter is the annotation that contain the expiration date of certificate, in RFC3339
+	AnnotationCertificateNotAfter = "k8sbuilder.io/certificate-not-after"
+)
+
+// CertificateInfo is the information read from certificate
+type CertificateInfo struct {
+	// Fingerprint is the sha256 of the DER certificate, in hexadecimal
+	Fingerprint string
+	NotBefore   time.Time
+	NotAfter    time.Time
+	Subject     string
+	DNSNames    []string
+}
+
+// ParseCertificate permit to read the first certificate of PEM
+// With certificate chain, the first one is the leaf certificate
+func ParseCertificate(certPEM []byte) (info *CertificateInfo, err error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("No PEM certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when parse certificate")
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	return &CertificateInfo{
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		Subject:     cert.Subject.String(),
+		DNSNames:    cert.DNSNames,
+	}, nil
+}
+
+// Annotations permit to get the fingerprint and expiration date as annotations
+// Copy them on pod template, so pods are rolled out when certificate is rotated
+func (h *CertificateInfo) Annotations() map[string]string {
+	return map[string]string{
+		AnnotationCertificateFingerprint: h.Fingerprint,
+		AnnotationCertificateNotAfter:    h.NotAfter.UTC().Format(time.RFC3339),
+	}
+}
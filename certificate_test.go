@@ -0,0 +1,63 @@
+package k8sbuilder
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     []string{"test.example.com"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateAnnotations(t *testing.T) {
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	certPEM := generateCertificate(t, notAfter)
+
+	info, err := ParseCertificate(certPEM)
+	assert.NoError(t, err)
+	assert.Equal(t, notAfter, info.NotAfter)
+	assert.Equal(t, "CN=test", info.Subject)
+	assert.Equal(t, []string{"test.example.com"}, info.DNSNames)
+	assert.Len(t, info.Fingerprint, 64)
+
+	s, err := NewSecretBuilder().
+		WithDefaults(&Defaults{}).
+		WithTLS(certPEM, []byte("key")).
+		WithCertificateAnnotations().
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, info.Fingerprint, s.Annotations[AnnotationCertificateFingerprint])
+	assert.Equal(t, "2030-01-01T00:00:00Z", s.Annotations[AnnotationCertificateNotAfter])
+
+	// When not certificate
+	_, err = NewSecretBuilder().
+		WithTLS([]byte("not a certificate"), []byte("key")).
+		WithCertificateAnnotations().
+		Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,90 @@
+package k8sbuilder
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getLiveObject permit to read the live object and strip it
+func getLiveObject(ctx context.Context, c client.Reader, key client.ObjectKey, o client.Object) (err error) {
+	if err = c.Get(ctx, key, o); err != nil {
+		return errors.Wrapf(err, "Error when get %T %s", o, key.String())
+	}
+	Sanitize(o)
+
+	return nil
+}
+
+// NewDeploymentBuilderFromCluster permit to init deployment builder with the live deployment as base
+// So changes are applied incrementally on current state. See Sanitize.
+func NewDeploymentBuilderFromCluster(ctx context.Context, c client.Reader, key client.ObjectKey) (DeploymentBuilder, error) {
+	d := &appsv1.Deployment{}
+	if err := getLiveObject(ctx, c, key, d); err != nil {
+		return nil, err
+	}
+
+	return &DeploymentBuilderDefault{
+		objectMeta:  newObjectMeta(d),
+		deployment:  d,
+		podTemplate: NewPodTemplateBuilder().WithPodTemplateSpec(&d.Spec.Template),
+	}, nil
+}
+
+// NewStatefulSetBuilderFromCluster permit to init statefulset builder with the live statefulset as base
+// So changes are applied incrementally on current state. See Sanitize.
+func NewStatefulSetBuilderFromCluster(ctx context.Context, c client.Reader, key client.ObjectKey) (StatefulSetBuilder, error) {
+	sts := &appsv1.StatefulSet{}
+	if err := getLiveObject(ctx, c, key, sts); err != nil {
+		return nil, err
+	}
+
+	return &StatefulSetBuilderDefault{
+		objectMeta:  newObjectMeta(sts),
+		statefulSet: sts,
+		podTemplate: NewPodTemplateBuilder().WithPodTemplateSpec(&sts.Spec.Template),
+	}, nil
+}
+
+// NewConfigMapBuilderFromCluster permit to init configmap builder with the live configmap as base
+func NewConfigMapBuilderFromCluster(ctx context.Context, c client.Reader, key client.ObjectKey) (ConfigMapBuilder, error) {
+	cm := &corev1.ConfigMap{}
+	if err := getLiveObject(ctx, c, key, cm); err != nil {
+		return nil, err
+	}
+
+	return &ConfigMapBuilderDefault{
+		objectMeta: newObjectMeta(cm),
+		configMap:  cm,
+	}, nil
+}
+
+// NewSecretBuilderFromCluster permit to init secret builder with the live secret as base
+func NewSecretBuilderFromCluster(ctx context.Context, c client.Reader, key client.ObjectKey) (SecretBuilder, error) {
+	s := &corev1.Secret{}
+	if err := getLiveObject(ctx, c, key, s); err != nil {
+		return nil, err
+	}
+
+	return &SecretBuilderDefault{
+		objectMeta: newObjectMeta(s),
+		secret:     s,
+	}, nil
+}
+
+// NewServiceBuilderFromCluster permit to init service builder with the live service as base
+// The cluster IPs allocated by API server are keeped, because of they can't be changed.
+func NewServiceBuilderFromCluster(ctx context.Context, c client.Reader, key client.ObjectKey) (ServiceBuilder, error) {
+	s := &corev1.Service{}
+	if err := getLiveObject(ctx, c, key, s); err != nil {
+		return nil, err
+	}
+
+	return &ServiceBuilderDefault{
+		objectMeta: newObjectMeta(s),
+		service:    s,
+	}, nil
+}
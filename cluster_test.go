@@ -0,0 +1,87 @@
+package k8sbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewDeploymentBuilderFromCluster(t *testing.T) {
+	live := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Generation:  2,
+			Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "team": "search"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					SchedulerName:   corev1.DefaultSchedulerName,
+					DNSPolicy:       corev1.DNSClusterFirst,
+					SecurityContext: &corev1.PodSecurityContext{},
+					Containers: []corev1.Container{{
+						Name:                     "app",
+						Image:                    "app:1",
+						TerminationMessagePath:   corev1.TerminationMessagePathDefault,
+						TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(live).Build()
+
+	b, err := NewDeploymentBuilderFromCluster(context.Background(), c, client.ObjectKeyFromObject(live))
+	assert.NoError(t, err)
+	d, err := b.
+		WithDefaults(&Defaults{}).
+		WithReplicas(3).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithContainers([]corev1.Container{{Name: "app", Image: "app:2"}}, Merge)
+		}).
+		Build()
+	assert.NoError(t, err)
+
+	assert.Empty(t, d.ResourceVersion)
+	assert.Zero(t, d.Generation)
+	assert.Equal(t, map[string]string{"team": "search"}, d.Annotations)
+	assert.Equal(t, appsv1.DeploymentStatus{}, d.Status)
+	assert.Equal(t, int32(3), *d.Spec.Replicas)
+	assert.Empty(t, d.Spec.Template.Spec.SchedulerName)
+	assert.Empty(t, d.Spec.Template.Spec.DNSPolicy)
+	assert.Nil(t, d.Spec.Template.Spec.SecurityContext)
+	assert.Equal(t, []corev1.Container{{Name: "app", Image: "app:2"}}, d.Spec.Template.Spec.Containers)
+
+	// Not found
+	_, err = NewDeploymentBuilderFromCluster(context.Background(), c, client.ObjectKey{Namespace: "default", Name: "other"})
+	assert.Error(t, err)
+}
+
+func TestNewConfigMapBuilderFromCluster(t *testing.T) {
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Data:       map[string]string{"key": "value"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(live).Build()
+
+	b, err := NewConfigMapBuilderFromCluster(context.Background(), c, client.ObjectKeyFromObject(live))
+	assert.NoError(t, err)
+	cm, err := b.
+		WithDefaults(&Defaults{}).
+		WithData(map[string]string{"other": "value"}, Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Empty(t, cm.ResourceVersion)
+	assert.Equal(t, map[string]string{"key": "value", "other": "value"}, cm.Data)
+}
@@ -0,0 +1,56 @@
+// Command k8sbuilder permit to render a builder pipeline without writing Go code.
+// It read a pipeline file that describe base manifests and ordered merge layers,
+// and print the rendered resources as YAML on stdout.
+//
+// Pipeline file sample:
+//
+//	resources:
+//	  - base/deployment.yaml
+//	layers:
+//	  - path: overlays/prod.yaml
+//	    option: merge
+//
+// Layer resources are matched with base resources from apiVersion, kind, namespace and name.
+// The overwriteIfDefaultValue option only add new resources: layer resources that already exist are dropped.
+// Paths are relative to the pipeline file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var (
+		pipelineFile string
+		redact       bool
+	)
+
+	flag.StringVar(&pipelineFile, "f", "", "The pipeline file to render")
+	flag.BoolVar(&redact, "redact", false, "Replace secret values by a hash placeholder, to share the output safely")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -f pipeline.yaml\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if pipelineFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	pipeline, err := LoadPipeline(pipelineFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error when load pipeline: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if redact {
+		pipeline.Redact = true
+	}
+
+	if err = pipeline.Render(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error when render pipeline: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
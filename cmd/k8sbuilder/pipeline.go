@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/disaster37/k8sbuilder"
+	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// Layer is a set of manifests merged on the pipeline resources
+type Layer struct {
+	// Path is the manifest file of the layer
+	Path string `json:"path"`
+
+	// Option is the way to apply the layer: overwrite, overwriteIfDefaultValue or merge
+	// Default to overwrite. With overwriteIfDefaultValue, the layer only add new resources:
+	// its resources that already exist are dropped, and the existing ones are keeped as is.
+	Option k8sbuilder.WithOption `json:"option,omitempty"`
+}
+
+// Pipeline describe the base manifests and the ordered layers to render
+type Pipeline struct {
+	// Resources is the list of base manifest files
+	Resources []string `json:"resources"`
+
+	// Layers is the ordered list of layers to apply on resources
+	Layers []Layer `json:"layers,omitempty"`
+
+	// Redact is true to replace secret values by a hash placeholder on rendered resources
+	Redact bool `json:"redact,omitempty"`
+
+	dir string
+}
+
+// LoadPipeline permit to read pipeline file
+func LoadPipeline(path string) (pipeline *Pipeline, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error when read file %s", path)
+	}
+
+	pipeline = &Pipeline{}
+	if err = yaml.UnmarshalStrict(data, pipeline); err != nil {
+		return nil, errors.Wrapf(err, "Error when decode pipeline file %s", path)
+	}
+	pipeline.dir = filepath.Dir(path)
+
+	for _, layer := range pipeline.Layers {
+		switch layer.Option {
+		case "", k8sbuilder.Overwrite, k8sbuilder.OverwriteIfDefaultValue, k8sbuilder.Merge:
+		default:
+			return nil, errors.Errorf("Option %s not supported on layer %s", layer.Option, layer.Path)
+		}
+	}
+
+	return pipeline, nil
+}
+
+// Render permit to apply all layers on resources and write the result as YAML
+func (h *Pipeline) Render(w io.Writer) (err error) {
+	objects, err := h.Build()
+	if err != nil {
+		return err
+	}
+
+	for i, o := range objects {
+		if h.Redact {
+			o = k8sbuilder.RedactSecret(o)
+		}
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return errors.Wrap(err, "Error when marshal resource")
+		}
+		if i > 0 {
+			if _, err = io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Build permit to apply all layers on resources
+// Layer resources that not exist yet are added. The existing ones are replaced (overwrite), merged (merge)
+// or keeped as is (overwriteIfDefaultValue).
+func (h *Pipeline) Build() (objects []runtime.Object, err error) {
+	objects = make([]runtime.Object, 0)
+
+	for _, path := range h.Resources {
+		manifests, err := readManifests(filepath.Join(h.dir, path))
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, manifests...)
+	}
+
+	for _, layer := range h.Layers {
+		var opts []k8sbuilder.WithOption
+		if layer.Option != "" {
+			opts = append(opts, layer.Option)
+		}
+
+		manifests, err := readManifests(filepath.Join(h.dir, layer.Path))
+		if err != nil {
+			return nil, err
+		}
+
+	loopManifest:
+		for _, manifest := range manifests {
+			manifestKey, err := objectKey(manifest)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, o := range objects {
+				key, err := objectKey(o)
+				if err != nil {
+					return nil, err
+				}
+				if key != manifestKey {
+					continue
+				}
+
+				// Overwrite
+				if k8sbuilder.IsOverwrite(opts) {
+					objects[i] = manifest
+				}
+
+				// Merge
+				// With OverwriteIfDefaultValue, the existing resource is keeped and the layer one is dropped
+				if k8sbuilder.IsMerge(opts) {
+					if err = mergeObject(o, manifest); err != nil {
+						return nil, errors.Wrapf(err, "Error when merge %s from layer %s", manifestKey, layer.Path)
+					}
+				}
+
+				continue loopManifest
+			}
+
+			// Not yet exist
+			objects = append(objects, manifest)
+		}
+	}
+
+	return objects, nil
+}
+
+// readManifests permit to read all resources from YAML or JSON file
+func readManifests(path string) (objects []runtime.Object, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error when open file %s", path)
+	}
+	defer f.Close()
+
+	objects = make([]runtime.Object, 0)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		data, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, errors.Wrapf(err, "Error when read file %s", path)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		o, err := decode(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error when decode resource from file %s", path)
+		}
+		objects = append(objects, o)
+	}
+
+	return objects, nil
+}
+
+// decode permit to decode resource as typed object if known by scheme, else as unstructured object
+func decode(data []byte) (o runtime.Object, err error) {
+	o, _, err = scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err == nil {
+		return o, nil
+	}
+	if !runtime.IsNotRegisteredError(err) {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{}
+	if err = yaml.Unmarshal(data, &u.Object); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// objectKey permit to get the key used to match resources between layers
+func objectKey(o runtime.Object) (key string, err error) {
+	accessor, err := meta.Accessor(o)
+	if err != nil {
+		return "", err
+	}
+	gvk := o.GetObjectKind().GroupVersionKind()
+
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.GroupVersion().String(), gvk.Kind, accessor.GetNamespace(), accessor.GetName()), nil
+}
+
+// mergeObject permit to merge layer on object
+// Typed resources are merged with strategic merge patch, unstructured resources are deep merged
+func mergeObject(dst, layer runtime.Object) (err error) {
+	if u, ok := dst.(*unstructured.Unstructured); ok {
+		return mergo.Merge(&u.Object, layer.(*unstructured.Unstructured).Object, mergo.WithOverride)
+	}
+
+	return k8sbuilder.MergeK8s(dst, dst, layer)
+}
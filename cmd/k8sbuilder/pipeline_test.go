@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	baseManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+  labels:
+    app: test
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: app:1.0.0
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  key: base
+---
+apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: custom
+  namespace: default
+spec:
+  size: 1
+  tier: base
+`
+
+	layerManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: sidecar
+          image: envoy
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: default
+data:
+  other: layer
+---
+apiVersion: example.com/v1
+kind: Custom
+metadata:
+  name: custom
+  namespace: default
+spec:
+  size: 3
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+  namespace: default
+stringData:
+  password: secret
+`
+)
+
+// writePipeline permit to write the base manifest, the layer and the pipeline file on temporary directory
+func writePipeline(t *testing.T, option string) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte(baseManifest), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "layer.yaml"), []byte(layerManifest), 0600))
+
+	pipeline := "resources:\n  - base.yaml\nlayers:\n  - path: layer.yaml\n"
+	if option != "" {
+		pipeline += "    option: " + option + "\n"
+	}
+	path := filepath.Join(dir, "pipeline.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(pipeline), 0600))
+
+	return path
+}
+
+// buildPipeline permit to load and build the pipeline
+func buildPipeline(t *testing.T, option string) []runtime.Object {
+	pipeline, err := LoadPipeline(writePipeline(t, option))
+	assert.NoError(t, err)
+	objects, err := pipeline.Build()
+	assert.NoError(t, err)
+	assert.Len(t, objects, 4)
+
+	// New resources from layer are added
+	assert.Equal(t, "secret", objects[3].(*corev1.Secret).Name)
+
+	return objects
+}
+
+func TestPipelineOverwrite(t *testing.T) {
+	for _, option := range []string{"", "overwrite"} {
+		objects := buildPipeline(t, option)
+
+		dpl := objects[0].(*appsv1.Deployment)
+		assert.Equal(t, int32(3), *dpl.Spec.Replicas)
+		assert.Empty(t, dpl.Labels)
+		assert.Len(t, dpl.Spec.Template.Spec.Containers, 1)
+		assert.Equal(t, "sidecar", dpl.Spec.Template.Spec.Containers[0].Name)
+
+		assert.Equal(t, map[string]string{"other": "layer"}, objects[1].(*corev1.ConfigMap).Data)
+
+		assert.Equal(t, map[string]any{"size": float64(3)}, objects[2].(*unstructured.Unstructured).Object["spec"])
+	}
+}
+
+func TestPipelineOverwriteIfDefaultValue(t *testing.T) {
+	objects := buildPipeline(t, "overwriteIfDefaultValue")
+
+	// Layer resources that already exist are dropped
+	dpl := objects[0].(*appsv1.Deployment)
+	assert.Equal(t, int32(1), *dpl.Spec.Replicas)
+	assert.Equal(t, map[string]string{"app": "test"}, dpl.Labels)
+	assert.Len(t, dpl.Spec.Template.Spec.Containers, 1)
+	assert.Equal(t, "app", dpl.Spec.Template.Spec.Containers[0].Name)
+
+	assert.Equal(t, map[string]string{"key": "base"}, objects[1].(*corev1.ConfigMap).Data)
+
+	assert.Equal(t, map[string]any{"size": float64(1), "tier": "base"}, objects[2].(*unstructured.Unstructured).Object["spec"])
+}
+
+func TestPipelineMerge(t *testing.T) {
+	objects := buildPipeline(t, "merge")
+
+	// Typed resources are merged with strategic merge patch: fields not set on layer are keeped,
+	// and lists items not set on layer are removed
+	dpl := objects[0].(*appsv1.Deployment)
+	assert.Equal(t, int32(3), *dpl.Spec.Replicas)
+	assert.Equal(t, map[string]string{"app": "test"}, dpl.Labels)
+	assert.Len(t, dpl.Spec.Template.Spec.Containers, 1)
+	assert.Equal(t, "sidecar", dpl.Spec.Template.Spec.Containers[0].Name)
+
+	assert.Equal(t, map[string]string{"key": "base", "other": "layer"}, objects[1].(*corev1.ConfigMap).Data)
+
+	// Unstructured resources are deep merged
+	assert.Equal(t, map[string]any{"size": float64(3), "tier": "base"}, objects[2].(*unstructured.Unstructured).Object["spec"])
+}
+
+func TestPipelineRender(t *testing.T) {
+	pipeline, err := LoadPipeline(writePipeline(t, "merge"))
+	assert.NoError(t, err)
+	pipeline.Redact = true
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, pipeline.Render(buf))
+	assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("---\n")))
+	assert.NotContains(t, buf.String(), "password: secret")
+}
+
+func TestLoadPipelineInvalidOption(t *testing.T) {
+	_, err := LoadPipeline(writePipeline(t, "unknown"))
+	assert.ErrorContains(t, err, "Option unknown not supported on layer layer.yaml")
+}
@@ -1,5 +1,11 @@
 package k8sbuilder
 
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
 
 
 const (
@@ -10,9 +16,35 @@ const (
 
 type WithOption string
 
+// Validator permit to validate built objects
+type Validator interface {
+	Validate(o runtime.Object) error
+}
+
+// Operation is a call done on builder, with its arguments
+// It can be serialized as JSON to record and replay the calls
 type Operation struct {
-	Name string
-	Args []any
+	Name string `json:"name"`
+	Args []any  `json:"args"`
+}
+
+// maxArgSummaryLength is the max length of each argument on operation summary, in runes
+const maxArgSummaryLength = 64
+
+// String permit to get a short summary of the operation and its arguments
+// It's used to give context on Build errors
+func (o Operation) String() string {
+	args := make([]string, 0, len(o.Args))
+	for _, arg := range o.Args {
+		// Truncate on runes, to not cut multi-bytes characters
+		summary := []rune(fmt.Sprintf("%+v", arg))
+		if len(summary) > maxArgSummaryLength {
+			summary = append(summary[:maxArgSummaryLength], []rune("...")...)
+		}
+		args = append(args, string(summary))
+	}
+
+	return fmt.Sprintf("%s(%s)", o.Name, strings.Join(args, ", "))
 }
 
 // IsOverwrite permit to know if i should overwrite or not, base on options
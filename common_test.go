@@ -0,0 +1,29 @@
+package k8sbuilder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationString(t *testing.T) {
+	o := Operation{
+		Name: "withName",
+		Args: []any{"test", []WithOption{Merge}},
+	}
+	assert.Equal(t, "withName(test, [merge])", o.String())
+
+	o = Operation{
+		Name: "withName",
+		Args: []any{strings.Repeat("a", 100)},
+	}
+	assert.Equal(t, "withName("+strings.Repeat("a", 64)+"...)", o.String())
+
+	// Multi-bytes characters are not cut
+	o = Operation{
+		Name: "withName",
+		Args: []any{strings.Repeat("é", 100)},
+	}
+	assert.Equal(t, "withName("+strings.Repeat("é", 64)+"...)", o.String())
+}
@@ -0,0 +1,165 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationConfigHash is the annotation that contain the combined hash of all tracked sources
+	AnnotationConfigHash = "k8sbuilder.io/config-hash"
+
+	// AnnotationConfigHashes is the annotation that contain the hash of each tracked source, as JSON object
+	AnnotationConfigHashes = "k8sbuilder.io/config-hashes"
+)
+
+// ConfigTracker permit to track the configuration sources used by workload, like ConfigMaps and Secrets
+// It compute the hash of each source and the combined hash, and expose them as annotations.
+// Set the annotations on pod template to roll out pods when one source change, and use Changed to know which one.
+type ConfigTracker struct {
+	sources map[string]any
+}
+
+// NewConfigTracker permit to get empty config tracker
+func NewConfigTracker() *ConfigTracker {
+	return &ConfigTracker{
+		sources: map[string]any{},
+	}
+}
+
+// Add permit to track arbitrary source, like a spec
+// The source with the same name is replaced
+func (h *ConfigTracker) Add(name string, source any) *ConfigTracker {
+	h.sources[name] = source
+
+	return h
+}
+
+// AddConfigMap permit to track the data of ConfigMap, with name configmap/<name>
+func (h *ConfigTracker) AddConfigMap(cm *corev1.ConfigMap) *ConfigTracker {
+	if cm == nil {
+		return h
+	}
+
+	return h.Add("configmap/"+cm.Name, []any{cm.Data, cm.BinaryData})
+}
+
+// AddSecret permit to track the data of Secret, with name secret/<name>
+// Only hash are exposed, so secret data never leak on annotations
+// StringData is folded into Data, like API server do, so the hash is the same before and after the Secret is applied.
+func (h *ConfigTracker) AddSecret(s *corev1.Secret) *ConfigTracker {
+	if s == nil {
+		return h
+	}
+
+	return h.Add("secret/"+s.Name, secretData(s))
+}
+
+// secretEntry is one key of Secret data
+type secretEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// secretData permit to get the data of Secret with StringData folded into Data, sorted by key
+// StringData take precedence over Data on the same key, like on API server.
+func secretData(s *corev1.Secret) (entries []secretEntry) {
+	data := make(map[string][]byte, len(s.Data)+len(s.StringData))
+	for key, value := range s.Data {
+		data[key] = value
+	}
+	for key, value := range s.StringData {
+		data[key] = []byte(value)
+	}
+
+	entries = make([]secretEntry, 0, len(data))
+	for key, value := range data {
+		entries = append(entries, secretEntry{Key: key, Value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+// Hashes permit to get the hash of each tracked source, by source name
+func (h *ConfigTracker) Hashes() (hashes map[string]string, err error) {
+	hashes = make(map[string]string, len(h.sources))
+	for name, source := range h.sources {
+		if hashes[name], err = Checksum(source); err != nil {
+			return nil, errors.Wrapf(err, "Error when compute hash of %s", name)
+		}
+	}
+
+	return hashes, nil
+}
+
+// Hash permit to get the combined hash of all tracked sources
+func (h *ConfigTracker) Hash() (hash string, err error) {
+	hashes, err := h.Hashes()
+	if err != nil {
+		return "", err
+	}
+
+	// JSON encoding sort map keys, so hash not depend of sources order
+	return Checksum(hashes)
+}
+
+// Annotations permit to get the combined hash and the hash of each source as annotations
+func (h *ConfigTracker) Annotations() (annotations map[string]string, err error) {
+	hashes, err := h.Hashes()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := Checksum(hashes)
+	if err != nil {
+		return nil, err
+	}
+	hashesByte, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal hashes")
+	}
+
+	return map[string]string{
+		AnnotationConfigHash:   hash,
+		AnnotationConfigHashes: string(hashesByte),
+	}, nil
+}
+
+// Changed permit to get the name of sources that changed since the annotations were set on object, sorted by name
+// Added and removed sources are also returned. All sources are returned if object has not annotations.
+func (h *ConfigTracker) Changed(o metav1.Object) (changed []string, err error) {
+	hashes, err := h.Hashes()
+	if err != nil {
+		return nil, err
+	}
+
+	previousHashes := map[string]string{}
+	if o != nil {
+		if previous, ok := o.GetAnnotations()[AnnotationConfigHashes]; ok {
+			if err = json.Unmarshal([]byte(previous), &previousHashes); err != nil {
+				return nil, errors.Wrapf(err, "Error when unmarshal annotation %s", AnnotationConfigHashes)
+			}
+		}
+	}
+
+	changed = make([]string, 0)
+	for name, hash := range hashes {
+		if previousHashes[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+	for name := range previousHashes {
+		if _, ok := hashes[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	return changed, nil
+}
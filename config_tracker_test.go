@@ -0,0 +1,83 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConfigTracker(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config"},
+		Data:       map[string]string{"key": "value"},
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+
+	tracker := NewConfigTracker().AddConfigMap(cm).AddSecret(s).Add("spec", map[string]any{"replicas": 1})
+	annotations, err := tracker.Annotations()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, annotations[AnnotationConfigHash])
+	assert.NotContains(t, annotations[AnnotationConfigHashes], "c2VjcmV0")
+	pts := &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: annotations}}
+
+	// When nothing change
+	changed, err := tracker.Changed(pts)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	// When one source change, one is removed and one is added
+	cm.Data["key"] = "new value"
+	tracker = NewConfigTracker().AddConfigMap(cm).AddSecret(s).Add("other", "value")
+	changed, err = tracker.Changed(pts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"configmap/config", "other", "spec"}, changed)
+
+	newHash, err := tracker.Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, annotations[AnnotationConfigHash], newHash)
+
+	// When no annotations
+	changed, err = tracker.Changed(&corev1.PodTemplateSpec{})
+	assert.NoError(t, err)
+	assert.Len(t, changed, 3)
+}
+
+func TestConfigTrackerSecretStringData(t *testing.T) {
+	data := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials"},
+		Data:       map[string][]byte{"username": []byte("user"), "password": []byte("secret")},
+	}
+	stringData := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials"},
+		StringData: map[string]string{"username": "user", "password": "secret"},
+	}
+	mixed := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials"},
+		Data:       map[string][]byte{"username": []byte("user"), "password": []byte("old")},
+		StringData: map[string]string{"password": "secret"},
+	}
+
+	expected, err := NewConfigTracker().AddSecret(data).Hash()
+	assert.NoError(t, err)
+
+	// StringData give the same hash than Data
+	hash, err := NewConfigTracker().AddSecret(stringData).Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	// StringData take precedence over Data
+	hash, err = NewConfigTracker().AddSecret(mixed).Hash()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	// When data change
+	data.Data["password"] = []byte("other")
+	hash, err = NewConfigTracker().AddSecret(data).Hash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, expected, hash)
+}
@@ -0,0 +1,295 @@
+package k8sbuilder
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ConfigMapBuilder is the configmap builder interface
+type ConfigMapBuilder interface {
+	WithName(name string, opts ...WithOption) ConfigMapBuilder
+	WithNamespace(namespace string, opts ...WithOption) ConfigMapBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) ConfigMapBuilder
+	WithoutLabels(keys ...string) ConfigMapBuilder
+	WithFinalizer(name string) ConfigMapBuilder
+	WithoutFinalizer(name string) ConfigMapBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ConfigMapBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) ConfigMapBuilder
+	WithoutAnnotations(keys ...string) ConfigMapBuilder
+	WithData(data map[string]string, opts ...WithOption) ConfigMapBuilder
+	WithBinaryData(data map[string][]byte, opts ...WithOption) ConfigMapBuilder
+	WithDataFromFile(key string, filePath string) ConfigMapBuilder
+	WithDataFromDir(dirPath string, filter func(name string) bool) ConfigMapBuilder
+	WithDataFromFS(fsys fs.FS, dir string, filter func(name string) bool) ConfigMapBuilder
+	WithDefaults(defaults *Defaults) ConfigMapBuilder
+	ConfigMap() *corev1.ConfigMap
+	Build() (cm *corev1.ConfigMap, err error)
+}
+
+// ConfigMapBuilderDefault is the default implementation of configmap builder
+type ConfigMapBuilderDefault struct {
+	objectMeta
+	configMap *corev1.ConfigMap
+	defaults  *Defaults
+	err       error
+}
+
+// NewConfigMapBuilder permit to init configmap builder
+func NewConfigMapBuilder() ConfigMapBuilder {
+	configMap := &corev1.ConfigMap{}
+
+	return &ConfigMapBuilderDefault{
+		objectMeta: newObjectMeta(configMap),
+		configMap:  configMap,
+	}
+}
+
+// ConfigMap permit to get current configmap
+func (h *ConfigMapBuilderDefault) ConfigMap() *corev1.ConfigMap {
+	return h.configMap
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *ConfigMapBuilderDefault) WithDefaults(defaults *Defaults) ConfigMapBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the configmap
+// It fail if one file can't be read
+func (h *ConfigMapBuilderDefault) Build() (cm *corev1.ConfigMap, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.configMap)
+
+	if errs := ValidateMetadata(h.configMap); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.configMap, nil
+}
+
+// WithName permit to set name
+func (h *ConfigMapBuilderDefault) WithName(name string, opts ...WithOption) ConfigMapBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *ConfigMapBuilderDefault) WithNamespace(namespace string, opts ...WithOption) ConfigMapBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *ConfigMapBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) ConfigMapBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *ConfigMapBuilderDefault) WithoutLabels(keys ...string) ConfigMapBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *ConfigMapBuilderDefault) WithFinalizer(name string) ConfigMapBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *ConfigMapBuilderDefault) WithoutFinalizer(name string) ConfigMapBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *ConfigMapBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ConfigMapBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *ConfigMapBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) ConfigMapBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *ConfigMapBuilderDefault) WithoutAnnotations(keys ...string) ConfigMapBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithData permit to set data
+// On merge, data are merged by key and new values win
+func (h *ConfigMapBuilderDefault) WithData(data map[string]string, opts ...WithOption) ConfigMapBuilder {
+
+	var tmpData map[string]string
+
+	// Copy to avoid overwrite data
+	if data != nil {
+		tmpData = make(map[string]string, len(data))
+		for key, value := range data {
+			tmpData[key] = value
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.configMap.Data == nil {
+		h.configMap.Data = tmpData
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.configMap.Data) == 0 {
+		h.configMap.Data = tmpData
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for key, value := range tmpData {
+			h.configMap.Data[key] = value
+		}
+	}
+
+	return h
+}
+
+// WithBinaryData permit to set binary data
+// On merge, binary data are merged by key and new values win
+func (h *ConfigMapBuilderDefault) WithBinaryData(data map[string][]byte, opts ...WithOption) ConfigMapBuilder {
+
+	var tmpData map[string][]byte
+
+	// Copy to avoid overwrite data
+	if data != nil {
+		tmpData = make(map[string][]byte, len(data))
+		for key, value := range data {
+			tmpData[key] = append([]byte(nil), value...)
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.configMap.BinaryData == nil {
+		h.configMap.BinaryData = tmpData
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.configMap.BinaryData) == 0 {
+		h.configMap.BinaryData = tmpData
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for key, value := range tmpData {
+			h.configMap.BinaryData[key] = value
+		}
+	}
+
+	return h
+}
+
+// WithDataFromFile permit to merge the file content on key, like `kubectl create configmap --from-file=key=path`
+// If key is empty, the file name is used. Not UTF-8 content is set on binary data.
+// Errors are returned by Build.
+func (h *ConfigMapBuilderDefault) WithDataFromFile(key string, filePath string) ConfigMapBuilder {
+	if key == "" {
+		key = filepath.Base(filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		h.setErr(errors.Wrapf(err, "Error when read file %s", filePath))
+		return h
+	}
+
+	return h.withFileContent(key, content)
+}
+
+// WithDataFromDir permit to merge all regular files of directory, like `kubectl create configmap --from-file=dir`
+// The file names are used as keys. Sub directories are ignored. If filter is not nil, only files where it return true are added.
+// Errors are returned by Build.
+func (h *ConfigMapBuilderDefault) WithDataFromDir(dirPath string, filter func(name string) bool) ConfigMapBuilder {
+	return h.WithDataFromFS(os.DirFS(dirPath), ".", filter)
+}
+
+// WithDataFromFS is the same as WithDataFromDir, but read the directory from fs.FS, like embed.FS
+func (h *ConfigMapBuilderDefault) WithDataFromFS(fsys fs.FS, dir string, filter func(name string) bool) ConfigMapBuilder {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		h.setErr(errors.Wrapf(err, "Error when read directory %s", dir))
+		return h
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if filter != nil && !filter(entry.Name()) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			h.setErr(errors.Wrapf(err, "Error when read file %s", entry.Name()))
+			return h
+		}
+		h.withFileContent(entry.Name(), content)
+	}
+
+	return h
+}
+
+func (h *ConfigMapBuilderDefault) withFileContent(key string, content []byte) ConfigMapBuilder {
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		h.setErr(errors.Errorf("Invalid key %s: %s", key, strings.Join(errs, ", ")))
+		return h
+	}
+
+	if utf8.Valid(content) {
+		return h.WithData(map[string]string{key: string(content)}, Merge)
+	}
+
+	return h.WithBinaryData(map[string][]byte{key: content}, Merge)
+}
+
+// setErr keep the first error, to return it on Build
+func (h *ConfigMapBuilderDefault) setErr(err error) {
+	if h.err == nil {
+		h.err = err
+	}
+}
@@ -0,0 +1,83 @@
+package k8sbuilder
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigMapFromFiles(t *testing.T) {
+	cm, err := NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithData(map[string]string{"app.yaml": "old", "other": "value"}).
+		WithDataFromDir("testdata/configmap", nil).
+		WithDataFromFile("custom.yaml", "testdata/configmap/app.yaml").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app.yaml":       "key: value\n",
+		"custom.yaml":    "key: value\n",
+		"log.properties": "level=info\n",
+		"other":          "value",
+	}, cm.Data)
+	assert.Equal(t, map[string][]byte{"data.bin": {0xff, 0xfe, 0x00}}, cm.BinaryData)
+
+	// With filter
+	cm, err = NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithDataFromDir("testdata/configmap", func(name string) bool { return strings.HasSuffix(name, ".yaml") }).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app.yaml": "key: value\n"}, cm.Data)
+
+	// From fs.FS
+	cm, err = NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithDataFromFS(fstest.MapFS{"config/app.yaml": {Data: []byte("key: value")}}, "config", nil).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app.yaml": "key: value"}, cm.Data)
+
+	// When file not exist
+	_, err = NewConfigMapBuilder().WithDataFromFile("", "testdata/configmap/notfound").Build()
+	assert.Error(t, err)
+
+	// When invalid key
+	_, err = NewConfigMapBuilder().WithDataFromFile("invalid/key", "testdata/configmap/app.yaml").Build()
+	assert.Error(t, err)
+}
+
+func TestConfigMapWithoutMetadata(t *testing.T) {
+	cm, err := NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test", "debug": "true"}).
+		WithAnnotations(map[string]string{"foo": "bar"}).
+		WithoutLabels("debug").
+		WithoutAnnotations("foo").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test"}, cm.Labels)
+	assert.Empty(t, cm.Annotations)
+}
+
+func TestConfigMapWithFinalizer(t *testing.T) {
+	finalizers := []string{"finalizer1"}
+	cm, err := NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithFinalizer("finalizer2").
+		WithFinalizer("finalizer2").
+		WithoutFinalizer("finalizer3").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finalizer2"}, cm.Finalizers)
+
+	// Given finalizers are not modified
+	b := NewConfigMapBuilder().WithDefaults(&Defaults{})
+	b.ConfigMap().Finalizers = finalizers
+	cm, err = b.WithoutFinalizer("finalizer1").WithFinalizer("finalizer4").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finalizer4"}, cm.Finalizers)
+	assert.Equal(t, []string{"finalizer1"}, finalizers)
+}
@@ -2,6 +2,7 @@ package k8sbuilder
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/thoas/go-funk"
 	corev1 "k8s.io/api/core/v1"
@@ -12,19 +13,60 @@ type ContainerBuilder interface {
 	WithContainer(container *corev1.Container, opts ...WithOption) ContainerBuilder
 	WithEnvFrom(envFroms []corev1.EnvFromSource, opts ...WithOption) ContainerBuilder
 	WithEnv(envs []corev1.EnvVar, opts ...WithOption) ContainerBuilder
+	WithEnvPolicy(dedupe EnvDedupeMode, order EnvOrderMode) ContainerBuilder
+	ReplaceEnv(name string, value string) ContainerBuilder
 	WithImage(image string, opts ...WithOption) ContainerBuilder
 	WithImagePullPolicy(pullPolicy corev1.PullPolicy, opts ...WithOption) ContainerBuilder
 	WithPort(ports []corev1.ContainerPort, opts ...WithOption) ContainerBuilder
+	WithPortMergeKey(key PortMergeKey) ContainerBuilder
+	WithNamedPort(name string, port int32, protocol corev1.Protocol) ContainerBuilder
 	WithResource(ressources *corev1.ResourceRequirements, opts ...WithOption) ContainerBuilder
+	WithGPU(count int64, resourceName string) ContainerBuilder
 	WithSecurityContext(sc *corev1.SecurityContext, opts ...WithOption) ContainerBuilder
+	WithWindowsOptions(options *corev1.WindowsSecurityContextOptions) ContainerBuilder
+	WithSeccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) ContainerBuilder
 	WithVolumeMount(volumeMounts []corev1.VolumeMount, opts ...WithOption) ContainerBuilder
 	WithLivenessProbe(probe *corev1.Probe, opts ...WithOption) ContainerBuilder
 	WithReadinessProbe(probe *corev1.Probe, opts ...WithOption) ContainerBuilder
 	WithStartupProbe(probe *corev1.Probe, opts ...WithOption) ContainerBuilder
 }
 
+// EnvDedupeMode is the way to choose the env to keep when names collide
+type EnvDedupeMode string
+
+// EnvOrderMode is the way to order env
+type EnvOrderMode string
+
+// PortMergeKey is the way to match ports when merge them
+type PortMergeKey string
+
+const (
+	// EnvLastWins keep the last env set with the same name. It's the default
+	EnvLastWins EnvDedupeMode = "lastWins"
+
+	// EnvFirstWins keep the first env set with the same name
+	EnvFirstWins EnvDedupeMode = "firstWins"
+
+	// EnvOrderInsertion keep env in insertion order. It's the default
+	// Use it if env reference other env with $(VAR_NAME), because of they need to be defined before.
+	EnvOrderInsertion EnvOrderMode = "insertion"
+
+	// EnvOrderSorted sort env by name, so the order not drift between builds
+	EnvOrderSorted EnvOrderMode = "sorted"
+
+	// PortKeyNumber match ports by container port. It's the default
+	PortKeyNumber PortMergeKey = "number"
+
+	// PortKeyName match ports by name, then by container port and protocol
+	// So a renamed port replace the existing one, and a port with the same name but other number is updated
+	PortKeyName PortMergeKey = "name"
+)
+
 type ContainerBuilderDefault struct {
-	container *corev1.Container
+	container    *corev1.Container
+	envDedupe    EnvDedupeMode
+	envOrder     EnvOrderMode
+	portMergeKey PortMergeKey
 }
 
 // NewContainerBuilder permit to get new container builder
@@ -112,41 +154,93 @@ func (h *ContainerBuilderDefault) WithEnvFrom(envFroms []corev1.EnvFromSource, o
 	return h
 }
 
+// WithEnvPolicy permit to choose how env are deduplicated by name and ordered
+// The policy is applied on next env changes
+func (h *ContainerBuilderDefault) WithEnvPolicy(dedupe EnvDedupeMode, order EnvOrderMode) ContainerBuilder {
+	h.envDedupe = dedupe
+	h.envOrder = order
+
+	return h
+}
+
 // WithEnv permit to set env
+// Env are deduplicated by name and ordered according to env policy
 func (h *ContainerBuilderDefault) WithEnv(envs []corev1.EnvVar, opts ...WithOption) ContainerBuilder {
 
 	var tmpEnvs []corev1.EnvVar
 
 	// Copy to avoid overwrite envFroms
 	if envs != nil {
-		tmpEnvs = make([]corev1.EnvVar, len(envs))
-		copy(tmpEnvs, envs)
+		tmpEnvs = h.mergeEnv(make([]corev1.EnvVar, 0, len(envs)), envs)
 	}
 
 	// Overwrite
 	if IsOverwrite(opts) || h.container.Env == nil {
-		h.container.Env = tmpEnvs
+		h.container.Env = h.sortEnv(tmpEnvs)
 		return h
 	}
 
 	// Overwrite only if not default
-	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.container.Env).Elem().IsZero() {
-		h.container.Env = tmpEnvs
+	if IsOverwriteIfDefaultValue(opts) && len(h.container.Env) == 0 {
+		h.container.Env = h.sortEnv(tmpEnvs)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
-		for _, env := range tmpEnvs {
-			if !funk.Contains(h.container.Env, env) {
-				h.container.Env = append(h.container.Env, env)
-			}
-		}
+		h.container.Env = h.sortEnv(h.mergeEnv(h.container.Env, tmpEnvs))
 	}
 
 	return h
 }
 
+// ReplaceEnv permit to set the value of env, whatever the env policy
+// The env is added if not exist
+func (h *ContainerBuilderDefault) ReplaceEnv(name string, value string) ContainerBuilder {
+	env := corev1.EnvVar{
+		Name:  name,
+		Value: value,
+	}
+
+	index := funk.IndexOf(h.container.Env, func(o corev1.EnvVar) bool {
+		return o.Name == name
+	})
+	if index == -1 {
+		h.container.Env = h.sortEnv(append(h.container.Env, env))
+	} else {
+		h.container.Env[index] = env
+	}
+
+	return h
+}
+
+// mergeEnv permit to add envs on dst, deduplicated by name according to env policy
+func (h *ContainerBuilderDefault) mergeEnv(dst []corev1.EnvVar, envs []corev1.EnvVar) []corev1.EnvVar {
+	for _, env := range envs {
+		index := funk.IndexOf(dst, func(o corev1.EnvVar) bool {
+			return env.Name == o.Name
+		})
+		if index == -1 {
+			dst = append(dst, *env.DeepCopy())
+		} else if h.envDedupe != EnvFirstWins {
+			dst[index] = *env.DeepCopy()
+		}
+	}
+
+	return dst
+}
+
+// sortEnv permit to order envs according to env policy
+func (h *ContainerBuilderDefault) sortEnv(envs []corev1.EnvVar) []corev1.EnvVar {
+	if h.envOrder == EnvOrderSorted {
+		sort.SliceStable(envs, func(i, j int) bool {
+			return envs[i].Name < envs[j].Name
+		})
+	}
+
+	return envs
+}
+
 // WithImage permit to set image
 func (h *ContainerBuilderDefault) WithImage(image string, opts ...WithOption) ContainerBuilder {
 	// Overwrite
@@ -194,9 +288,7 @@ func (h *ContainerBuilderDefault) WithPort(ports []corev1.ContainerPort, opts ..
 	// Merge
 	if IsMerge(opts) {
 		for _, port := range tmpPorts {
-			index := funk.IndexOf(h.container.Ports, func(o corev1.ContainerPort) bool {
-				return port.ContainerPort == o.ContainerPort
-			})
+			index := h.indexOfPort(port)
 
 			if index == -1 {
 				h.container.Ports = append(h.container.Ports, port)
@@ -209,6 +301,79 @@ func (h *ContainerBuilderDefault) WithPort(ports []corev1.ContainerPort, opts ..
 	return h
 }
 
+// WithPortMergeKey permit to choose how ports are matched when merge them
+// The key is applied on next port changes
+func (h *ContainerBuilderDefault) WithPortMergeKey(key PortMergeKey) ContainerBuilder {
+	h.portMergeKey = key
+
+	return h
+}
+
+// WithNamedPort permit to add or update one named port
+// The port is matched by name, then by container port and protocol, so renaming a port not duplicate it.
+// Protocol default to TCP.
+func (h *ContainerBuilderDefault) WithNamedPort(name string, port int32, protocol corev1.Protocol) ContainerBuilder {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	containerPort := corev1.ContainerPort{
+		Name:          name,
+		ContainerPort: port,
+		Protocol:      protocol,
+	}
+
+	index := indexOfPortByName(h.container.Ports, containerPort)
+	if index == -1 {
+		h.container.Ports = append(h.container.Ports, containerPort)
+	} else {
+		// Keep other settings like hostPort
+		h.container.Ports[index].Name = name
+		h.container.Ports[index].ContainerPort = port
+		h.container.Ports[index].Protocol = protocol
+	}
+
+	return h
+}
+
+// indexOfPort permit to get the index of the port that match port according to merge key, or -1
+func (h *ContainerBuilderDefault) indexOfPort(port corev1.ContainerPort) int {
+	if h.portMergeKey == PortKeyName {
+		return indexOfPortByName(h.container.Ports, port)
+	}
+
+	return funk.IndexOf(h.container.Ports, func(o corev1.ContainerPort) bool {
+		return port.ContainerPort == o.ContainerPort
+	})
+}
+
+// indexOfPortByName permit to get the index of the port with the same name, or with the same container port and protocol, or -1
+func indexOfPortByName(ports []corev1.ContainerPort, port corev1.ContainerPort) int {
+	if port.Name != "" {
+		for i, o := range ports {
+			if o.Name == port.Name {
+				return i
+			}
+		}
+	}
+
+	for i, o := range ports {
+		if o.ContainerPort == port.ContainerPort && portProtocol(o) == portProtocol(port) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// portProtocol permit to get the protocol of port, TCP if not set
+func portProtocol(port corev1.ContainerPort) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+
+	return port.Protocol
+}
+
 // WithResource permit to set resources
 func (h *ContainerBuilderDefault) WithResource(resources *corev1.ResourceRequirements, opts ...WithOption) ContainerBuilder {
 	if resources == nil {
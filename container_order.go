@@ -0,0 +1,103 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WithInitContainerBefore permit to insert init container just before the init container name
+// If init container name not exist, it's inserted first. If an init container with the same name exist, it's replaced and moved.
+func (h *PodTemplateBuilderDefault) WithInitContainerBefore(name string, container corev1.Container) PodTemplateBuilder {
+	defer h.observe("WithInitContainerBefore")()
+
+	h.own(sharedInitContainers)
+	containers := removeContainer(h.podTemplate.Spec.InitContainers, container.Name)
+	index := indexOfContainer(containers, name)
+	if index == -1 {
+		index = 0
+	}
+	h.podTemplate.Spec.InitContainers = insertContainer(containers, index, container)
+
+	return h
+}
+
+// WithInitContainerAfter permit to insert init container just after the init container name
+// If init container name not exist, it's inserted last. If an init container with the same name exist, it's replaced and moved.
+func (h *PodTemplateBuilderDefault) WithInitContainerAfter(name string, container corev1.Container) PodTemplateBuilder {
+	defer h.observe("WithInitContainerAfter")()
+
+	h.own(sharedInitContainers)
+	containers := removeContainer(h.podTemplate.Spec.InitContainers, container.Name)
+	index := indexOfContainer(containers, name)
+	if index == -1 {
+		index = len(containers)
+	} else {
+		index++
+	}
+	h.podTemplate.Spec.InitContainers = insertContainer(containers, index, container)
+
+	return h
+}
+
+func indexOfContainer(containers []corev1.Container, name string) int {
+	for i := range containers {
+		if containers[i].Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeContainer permit to remove container by name. The slice is modified
+func removeContainer(containers []corev1.Container, name string) []corev1.Container {
+	index := indexOfContainer(containers, name)
+	if index == -1 {
+		return containers
+	}
+
+	return append(containers[:index], containers[index+1:]...)
+}
+
+// insertContainer permit to insert container at index. The slice is modified
+func insertContainer(containers []corev1.Container, index int, container corev1.Container) []corev1.Container {
+	containers = append(containers, corev1.Container{})
+	copy(containers[index+1:], containers[index:])
+	containers[index] = container
+
+	return containers
+}
+
+// MoveContainerFirst permit to move the container first, so it's the default container of kubectl logs and exec
+// Nothing is done if container not exist.
+func (h *PodTemplateBuilderDefault) MoveContainerFirst(name string) PodTemplateBuilder {
+	defer h.observe("MoveContainerFirst")()
+
+	return h.OrderContainers(name)
+}
+
+// OrderContainers permit to order containers: containers are sorted in the order of names,
+// and the containers not in names are keeped after them, in their current order.
+// Use it after merge layers to get the same order whatever the layers order.
+func (h *PodTemplateBuilderDefault) OrderContainers(names ...string) PodTemplateBuilder {
+	defer h.observe("OrderContainers")()
+
+	if len(names) == 0 || len(h.podTemplate.Spec.Containers) == 0 {
+		return h
+	}
+
+	h.own(sharedContainers)
+	ordered := make([]corev1.Container, 0, len(h.podTemplate.Spec.Containers))
+	for _, name := range names {
+		if index := indexOfContainer(h.podTemplate.Spec.Containers, name); index != -1 && indexOfContainer(ordered, name) == -1 {
+			ordered = append(ordered, h.podTemplate.Spec.Containers[index])
+		}
+	}
+	for _, container := range h.podTemplate.Spec.Containers {
+		if indexOfContainer(ordered, container.Name) == -1 {
+			ordered = append(ordered, container)
+		}
+	}
+	h.podTemplate.Spec.Containers = ordered
+
+	return h
+}
@@ -0,0 +1,63 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func containerNames(containers []corev1.Container) []string {
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+func TestPodTemplateInitContainerOrdering(t *testing.T) {
+	initContainers := []corev1.Container{{Name: "init-config"}, {Name: "init-data"}}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithInitContainers(initContainers).
+		WithInitContainerBefore("init-data", corev1.Container{Name: "sysctl"}).
+		WithInitContainerAfter("init-config", corev1.Container{Name: "wait-db"}).
+		WithInitContainerBefore("missing", corev1.Container{Name: "first"}).
+		WithInitContainerAfter("missing", corev1.Container{Name: "last"}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "init-config", "wait-db", "sysctl", "init-data", "last"}, containerNames(pts.Spec.InitContainers))
+
+	// Existing init container is replaced and moved
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithInitContainers(initContainers).
+		WithInitContainerBefore("init-config", corev1.Container{Name: "init-data", Image: "busybox"}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"init-data", "init-config"}, containerNames(pts.Spec.InitContainers))
+	assert.Equal(t, "busybox", pts.Spec.InitContainers[0].Image)
+
+	// Caller slice is not modified
+	assert.Equal(t, []string{"init-config", "init-data"}, containerNames(initContainers))
+}
+
+func TestPodTemplateContainerOrdering(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "sidecar"}, {Name: "exporter"}, {Name: "app"}, {Name: "logger"}})
+
+	pts, err := b.MoveContainerFirst("app").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app", "sidecar", "exporter", "logger"}, containerNames(pts.Spec.Containers))
+
+	pts, err = b.OrderContainers("logger", "missing", "sidecar", "logger").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "sidecar", "app", "exporter"}, containerNames(pts.Spec.Containers))
+
+	// Nothing change if container not exist
+	pts, err = b.MoveContainerFirst("missing").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logger", "sidecar", "app", "exporter"}, containerNames(pts.Spec.Containers))
+}
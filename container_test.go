@@ -0,0 +1,57 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestContainerWithEnv(t *testing.T) {
+	// Last wins and insertion order by default
+	b := NewContainerBuilder().
+		WithEnv([]corev1.EnvVar{{Name: "B", Value: "1"}, {Name: "A", Value: "1"}}).
+		WithEnv([]corev1.EnvVar{{Name: "B", Value: "2"}, {Name: "C", Value: "1"}}, Merge)
+	assert.Equal(t, []corev1.EnvVar{{Name: "B", Value: "2"}, {Name: "A", Value: "1"}, {Name: "C", Value: "1"}}, b.Container().Env)
+
+	// First wins and sorted
+	b = NewContainerBuilder().
+		WithEnvPolicy(EnvFirstWins, EnvOrderSorted).
+		WithEnv([]corev1.EnvVar{{Name: "B", Value: "1"}, {Name: "A", Value: "1"}, {Name: "A", Value: "2"}}).
+		WithEnv([]corev1.EnvVar{{Name: "B", Value: "2"}, {Name: "C", Value: "1"}}, Merge)
+	assert.Equal(t, []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}, {Name: "C", Value: "1"}}, b.Container().Env)
+
+	// Replace env
+	b.ReplaceEnv("B", "3").ReplaceEnv("0", "1")
+	assert.Equal(t, []corev1.EnvVar{{Name: "0", Value: "1"}, {Name: "A", Value: "1"}, {Name: "B", Value: "3"}, {Name: "C", Value: "1"}}, b.Container().Env)
+}
+
+func TestContainerWithPort(t *testing.T) {
+	// Match by number by default
+	b := NewContainerBuilder().
+		WithPort([]corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}).
+		WithPort([]corev1.ContainerPort{{Name: "web", ContainerPort: 8080}, {Name: "metrics", ContainerPort: 9090}}, Merge)
+	assert.Equal(t, []corev1.ContainerPort{{Name: "web", ContainerPort: 8080}, {Name: "metrics", ContainerPort: 9090}}, b.Container().Ports)
+
+	// Match by name, then by number and protocol
+	b = NewContainerBuilder().
+		WithPortMergeKey(PortKeyName).
+		WithPort([]corev1.ContainerPort{{Name: "http", ContainerPort: 8080}, {Name: "dns", ContainerPort: 53, Protocol: corev1.ProtocolUDP}}).
+		WithPort([]corev1.ContainerPort{{Name: "http", ContainerPort: 8081}, {Name: "dns-tcp", ContainerPort: 53, Protocol: corev1.ProtocolTCP}}, Merge)
+	assert.Equal(t, []corev1.ContainerPort{
+		{Name: "http", ContainerPort: 8081},
+		{Name: "dns", ContainerPort: 53, Protocol: corev1.ProtocolUDP},
+		{Name: "dns-tcp", ContainerPort: 53, Protocol: corev1.ProtocolTCP},
+	}, b.Container().Ports)
+
+	// Named port
+	b = NewContainerBuilder().
+		WithPort([]corev1.ContainerPort{{Name: "http", ContainerPort: 8080, HostPort: 80}}).
+		WithNamedPort("web", 8080, "").
+		WithNamedPort("metrics", 9090, corev1.ProtocolTCP).
+		WithNamedPort("metrics", 9091, corev1.ProtocolTCP)
+	assert.Equal(t, []corev1.ContainerPort{
+		{Name: "web", ContainerPort: 8080, HostPort: 80, Protocol: corev1.ProtocolTCP},
+		{Name: "metrics", ContainerPort: 9091, Protocol: corev1.ProtocolTCP},
+	}, b.Container().Ports)
+}
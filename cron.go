@@ -0,0 +1,46 @@
+package k8sbuilder
+
+import (
+	"strings"
+	"time"
+	// The tz database is embedded, so timezones are validated even on images without it, like distroless or scratch
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+)
+
+// ValidateCronSchedule permit to check the cron expression like the cronjob controller parse it
+// It use the same parser than the cronjob controller, that accept the standard 5 fields format and the descriptors, like @daily or @every 1h.
+// The timezone must be set with the timeZone field instead of TZ prefix.
+func ValidateCronSchedule(schedule string) (err error) {
+	schedule = strings.TrimSpace(schedule)
+	if schedule == "" {
+		return errors.New("Schedule can't be empty")
+	}
+	if strings.HasPrefix(schedule, "TZ=") || strings.HasPrefix(schedule, "CRON_TZ=") {
+		return errors.Errorf("Schedule %s must not contain timezone, use timeZone instead", schedule)
+	}
+
+	if _, err = cron.ParseStandard(schedule); err != nil {
+		return errors.Wrapf(err, "Invalid schedule %s", schedule)
+	}
+
+	return nil
+}
+
+// ValidateTimeZone permit to check the timezone exist on tz database
+// Local is rejected like the API server do, because of it depend on the timezone of the controller manager.
+func ValidateTimeZone(timeZone string) (err error) {
+	if timeZone == "" {
+		return errors.New("Time zone can't be empty")
+	}
+	if strings.EqualFold(timeZone, "Local") {
+		return errors.Errorf("Time zone %s is not supported, use the tz database name", timeZone)
+	}
+	if _, err = time.LoadLocation(timeZone); err != nil {
+		return errors.Wrapf(err, "Unknown time zone %s", timeZone)
+	}
+
+	return nil
+}
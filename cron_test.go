@@ -0,0 +1,73 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCronSchedule(t *testing.T) {
+	for _, schedule := range []string{
+		"*/5 * * * *",
+		"0 0 1,15 * MON-FRI",
+		"30 2 * jan-mar sun",
+		"0 */2 ? * *",
+		"@daily",
+		"@every 1h30m",
+		"@hourly",
+	} {
+		assert.NoError(t, ValidateCronSchedule(schedule), schedule)
+	}
+
+	for _, schedule := range []string{
+		"",
+		"* * * *",
+		"0 * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"* * * 5-2 *",
+		"* * * foo *",
+		"@often",
+		"@every foo",
+		"TZ=Europe/Paris 0 0 * * *",
+	} {
+		assert.Error(t, ValidateCronSchedule(schedule), schedule)
+	}
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	for _, timeZone := range []string{"Europe/Paris", "America/New_York", "UTC", "Etc/GMT+2"} {
+		assert.NoError(t, ValidateTimeZone(timeZone), timeZone)
+	}
+
+	for _, timeZone := range []string{"", "Local", "Europe/Nowhere", "+02:00"} {
+		assert.Error(t, ValidateTimeZone(timeZone), timeZone)
+	}
+}
+
+func TestCronJobTimeZone(t *testing.T) {
+	// When valid
+	_, err := NewCronJobBuilder().
+		WithDefaults(&Defaults{}).
+		WithSchedule("0 0 * * *").
+		WithTimeZone("Europe/Paris").
+		Build()
+	assert.NoError(t, err)
+
+	// When unknown timezone
+	_, err = NewCronJobBuilder().
+		WithDefaults(&Defaults{}).
+		WithSchedule("0 0 * * *").
+		WithTimeZone("Europe/Nowhere").
+		Build()
+	assert.Error(t, err)
+
+	// When invalid schedule
+	_, err = NewCronJobBuilder().
+		WithDefaults(&Defaults{}).
+		WithSchedule("0 0 * *").
+		Build()
+	assert.Error(t, err)
+}
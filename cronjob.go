@@ -0,0 +1,259 @@
+package k8sbuilder
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultCronJobSuccessfulJobsHistoryLimit is the successful jobs history limit set by batch defaults preset
+	DefaultCronJobSuccessfulJobsHistoryLimit int32 = 3
+
+	// DefaultCronJobFailedJobsHistoryLimit is the failed jobs history limit set by batch defaults preset
+	DefaultCronJobFailedJobsHistoryLimit int32 = 1
+)
+
+// CronJobBuilder is the cronjob builder interface
+type CronJobBuilder interface {
+	WithName(name string, opts ...WithOption) CronJobBuilder
+	WithNamespace(namespace string, opts ...WithOption) CronJobBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) CronJobBuilder
+	WithoutLabels(keys ...string) CronJobBuilder
+	WithFinalizer(name string) CronJobBuilder
+	WithoutFinalizer(name string) CronJobBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) CronJobBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) CronJobBuilder
+	WithoutAnnotations(keys ...string) CronJobBuilder
+	WithSchedule(schedule string, opts ...WithOption) CronJobBuilder
+	WithTimeZone(timeZone string, opts ...WithOption) CronJobBuilder
+	WithConcurrencyPolicy(policy batchv1.ConcurrencyPolicy, opts ...WithOption) CronJobBuilder
+	WithSuccessfulJobsHistoryLimit(nb int32, opts ...WithOption) CronJobBuilder
+	WithFailedJobsHistoryLimit(nb int32, opts ...WithOption) CronJobBuilder
+	WithBatchDefaults() CronJobBuilder
+	WithJobTemplate(fn func(jb JobBuilder)) CronJobBuilder
+	WithDefaults(defaults *Defaults) CronJobBuilder
+	JobTemplate() JobBuilder
+	CronJob() *batchv1.CronJob
+	Build() (cj *batchv1.CronJob, err error)
+}
+
+// CronJobBuilderDefault is the default implementation of cronjob builder
+type CronJobBuilderDefault struct {
+	objectMeta
+	cronJob     *batchv1.CronJob
+	jobTemplate JobBuilder
+	defaults    *Defaults
+	err         error
+}
+
+// NewCronJobBuilder permit to init cronjob builder
+func NewCronJobBuilder() CronJobBuilder {
+	cronJob := &batchv1.CronJob{}
+
+	return &CronJobBuilderDefault{
+		objectMeta:  newObjectMeta(cronJob),
+		cronJob:     cronJob,
+		jobTemplate: NewJobBuilder(),
+	}
+}
+
+// JobTemplate permit to get the job template sub-builder
+// Changes done on it are set on cronjob on Build. The name and namespace of job are ignored.
+func (h *CronJobBuilderDefault) JobTemplate() JobBuilder {
+	return h.jobTemplate
+}
+
+// WithJobTemplate permit to change the job template without break the fluent chain
+func (h *CronJobBuilderDefault) WithJobTemplate(fn func(jb JobBuilder)) CronJobBuilder {
+	if fn != nil {
+		fn(h.jobTemplate)
+	}
+
+	return h
+}
+
+// CronJob permit to get current cronjob
+func (h *CronJobBuilderDefault) CronJob() *batchv1.CronJob {
+	return h.cronJob
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+// They are also used by the job template sub-builder
+func (h *CronJobBuilderDefault) WithDefaults(defaults *Defaults) CronJobBuilder {
+	h.defaults = defaults
+	h.jobTemplate.WithDefaults(defaults)
+
+	return h
+}
+
+// WithBatchDefaults permit to apply sane defaults for cronjob and its job template
+// It set concurrencyPolicy to Forbid and jobs history limits with OverwriteIfDefaultValue, so you can still override them
+func (h *CronJobBuilderDefault) WithBatchDefaults() CronJobBuilder {
+	h.jobTemplate.WithBatchDefaults()
+
+	return h.WithConcurrencyPolicy(batchv1.ForbidConcurrent, OverwriteIfDefaultValue).
+		WithSuccessfulJobsHistoryLimit(DefaultCronJobSuccessfulJobsHistoryLimit, OverwriteIfDefaultValue).
+		WithFailedJobsHistoryLimit(DefaultCronJobFailedJobsHistoryLimit, OverwriteIfDefaultValue)
+}
+
+// Build permit to get the cronjob with the job template built by the sub-builder
+// It check that schedule is a valid cron expression and that timezone exist on tz database
+func (h *CronJobBuilderDefault) Build() (cj *batchv1.CronJob, err error) {
+	defer observeBuild("CronJob", time.Now())
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if err = ValidateCronSchedule(h.cronJob.Spec.Schedule); err != nil {
+		return nil, err
+	}
+	if h.cronJob.Spec.TimeZone != nil {
+		if err = ValidateTimeZone(*h.cronJob.Spec.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	job, err := h.jobTemplate.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when build job template")
+	}
+	h.cronJob.Spec.JobTemplate = batchv1.JobTemplateSpec{
+		Spec: *job.Spec.DeepCopy(),
+	}
+	h.cronJob.Spec.JobTemplate.Labels = job.Labels
+	h.cronJob.Spec.JobTemplate.Annotations = job.Annotations
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.cronJob)
+
+	if errs := ValidateMetadata(h.cronJob); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.cronJob, nil
+}
+
+// WithName permit to set name
+func (h *CronJobBuilderDefault) WithName(name string, opts ...WithOption) CronJobBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *CronJobBuilderDefault) WithNamespace(namespace string, opts ...WithOption) CronJobBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *CronJobBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) CronJobBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *CronJobBuilderDefault) WithoutLabels(keys ...string) CronJobBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *CronJobBuilderDefault) WithFinalizer(name string) CronJobBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *CronJobBuilderDefault) WithoutFinalizer(name string) CronJobBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *CronJobBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) CronJobBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *CronJobBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) CronJobBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *CronJobBuilderDefault) WithoutAnnotations(keys ...string) CronJobBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithSchedule permit to set schedule
+func (h *CronJobBuilderDefault) WithSchedule(schedule string, opts ...WithOption) CronJobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.cronJob.Spec.Schedule == "" {
+		h.cronJob.Spec.Schedule = schedule
+	}
+
+	return h
+}
+
+// WithTimeZone permit to set the timezone of schedule, like "Europe/Paris"
+func (h *CronJobBuilderDefault) WithTimeZone(timeZone string, opts ...WithOption) CronJobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.cronJob.Spec.TimeZone == nil {
+		h.cronJob.Spec.TimeZone = pointer.String(timeZone)
+	}
+
+	return h
+}
+
+// WithConcurrencyPolicy permit to set concurrency policy
+func (h *CronJobBuilderDefault) WithConcurrencyPolicy(policy batchv1.ConcurrencyPolicy, opts ...WithOption) CronJobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.cronJob.Spec.ConcurrencyPolicy == "" {
+		h.cronJob.Spec.ConcurrencyPolicy = policy
+	}
+
+	return h
+}
+
+// WithSuccessfulJobsHistoryLimit permit to set successful jobs history limit
+func (h *CronJobBuilderDefault) WithSuccessfulJobsHistoryLimit(nb int32, opts ...WithOption) CronJobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.cronJob.Spec.SuccessfulJobsHistoryLimit == nil {
+		h.cronJob.Spec.SuccessfulJobsHistoryLimit = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithFailedJobsHistoryLimit permit to set failed jobs history limit
+func (h *CronJobBuilderDefault) WithFailedJobsHistoryLimit(nb int32, opts ...WithOption) CronJobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.cronJob.Spec.FailedJobsHistoryLimit == nil {
+		h.cronJob.Spec.FailedJobsHistoryLimit = pointer.Int32(nb)
+	}
+
+	return h
+}
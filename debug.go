@@ -0,0 +1,73 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// debugContainerName is the base name of debug containers
+const debugContainerName = "debugger"
+
+// BuildDebugContainerPatch permit to get the patch that add an ephemeral debug container on existing pod
+// The debug container target the first container of pod, so it share its process namespace when the runtime support it.
+// Stdin and TTY are enabled, so you can attach to it. Its name is unique on pod, like debugger or debugger-1.
+// The patch must be sent on the ephemeralcontainers subresource of pod, like
+// `clientset.CoreV1().Pods(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{}, "ephemeralcontainers")`
+func BuildDebugContainerPatch(targetPod *corev1.Pod, image string, cmd []string) (patch []byte, patchType types.PatchType, err error) {
+	if targetPod == nil {
+		return nil, "", errors.New("Target pod can't be nil")
+	}
+	if len(targetPod.Spec.Containers) == 0 {
+		return nil, "", errors.Errorf("Pod %s/%s has no container to debug", targetPod.Namespace, targetPod.Name)
+	}
+
+	container := NewContainerBuilder().
+		WithImage(image).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
+		Container()
+	container.Name = debugContainerNameFor(targetPod)
+	container.Command = cmd
+	container.Stdin = true
+	container.TTY = true
+
+	ec := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon(*container),
+		TargetContainerName:      targetPod.Spec.Containers[0].Name,
+	}
+
+	patch, err = json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"ephemeralContainers": []corev1.EphemeralContainer{ec},
+		},
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when marshal debug container patch")
+	}
+
+	return patch, types.StrategicMergePatchType, nil
+}
+
+// debugContainerNameFor permit to get the name of debug container that not already exist on pod
+func debugContainerNameFor(pod *corev1.Pod) string {
+	names := map[string]bool{}
+	for _, c := range pod.Spec.Containers {
+		names[c.Name] = true
+	}
+	for _, c := range pod.Spec.InitContainers {
+		names[c.Name] = true
+	}
+	for _, c := range pod.Spec.EphemeralContainers {
+		names[c.Name] = true
+	}
+
+	name := debugContainerName
+	for i := 1; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d", debugContainerName, i)
+	}
+
+	return name
+}
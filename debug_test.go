@@ -0,0 +1,55 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+func TestBuildDebugContainerPatch(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app"}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}},
+			},
+		},
+	}
+
+	patch, patchType, err := BuildDebugContainerPatch(pod, "busybox", []string{"sh"})
+	assert.NoError(t, err)
+	assert.Equal(t, types.StrategicMergePatchType, patchType)
+
+	// Patch add the debug container and keep the existing one
+	original, err := json.Marshal(pod)
+	assert.NoError(t, err)
+	patched, err := strategicpatch.StrategicMergePatch(original, patch, &corev1.Pod{})
+	assert.NoError(t, err)
+	result := &corev1.Pod{}
+	assert.NoError(t, json.Unmarshal(patched, result))
+
+	assert.Len(t, result.Spec.EphemeralContainers, 2)
+	var ec corev1.EphemeralContainer
+	for _, c := range result.Spec.EphemeralContainers {
+		if c.Name == "debugger-1" {
+			ec = c
+		}
+	}
+	assert.Equal(t, "busybox", ec.Image)
+	assert.Equal(t, []string{"sh"}, ec.Command)
+	assert.Equal(t, "app", ec.TargetContainerName)
+	assert.True(t, ec.Stdin)
+	assert.True(t, ec.TTY)
+
+	// Errors
+	_, _, err = BuildDebugContainerPatch(nil, "busybox", nil)
+	assert.Error(t, err)
+	_, _, err = BuildDebugContainerPatch(&corev1.Pod{}, "busybox", nil)
+	assert.Error(t, err)
+}
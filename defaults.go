@@ -0,0 +1,348 @@
+package k8sbuilder
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// ApplyDefaults permit to set the same default values than API server on object
+// It mirror the upstream defaulting (k8s.io/kubernetes/pkg/apis/*/v1) of the most common fields for core, apps, batch
+// and networking types, so diffs against live objects not show spurious changes like protocol: TCP.
+// It accept objects and pod template spec. Unknown types are ignored.
+func ApplyDefaults(o any) {
+	switch t := o.(type) {
+	case *corev1.Pod:
+		setDefaultsPodSpec(&t.Spec)
+		// Requests default to limits only on pod
+		for i := range t.Spec.InitContainers {
+			setDefaultsRequests(&t.Spec.InitContainers[i])
+		}
+		for i := range t.Spec.Containers {
+			setDefaultsRequests(&t.Spec.Containers[i])
+		}
+	case *corev1.PodTemplateSpec:
+		setDefaultsPodSpec(&t.Spec)
+	case *corev1.PodTemplate:
+		setDefaultsPodSpec(&t.Template.Spec)
+	case *corev1.Service:
+		setDefaultsService(t)
+	case *appsv1.Deployment:
+		setDefaultsDeployment(t)
+	case *appsv1.StatefulSet:
+		setDefaultsStatefulSet(t)
+	case *appsv1.DaemonSet:
+		setDefaultsDaemonSet(t)
+	case *batchv1.Job:
+		setDefaultsJobSpec(&t.Spec)
+	case *batchv1.CronJob:
+		setDefaultsCronJob(t)
+	case *networkingv1.NetworkPolicy:
+		setDefaultsNetworkPolicy(t)
+	}
+}
+
+func setDefaultsPodSpec(spec *corev1.PodSpec) {
+	if spec.DNSPolicy == "" {
+		spec.DNSPolicy = corev1.DNSClusterFirst
+	}
+	if spec.RestartPolicy == "" {
+		spec.RestartPolicy = corev1.RestartPolicyAlways
+	}
+	if spec.SecurityContext == nil {
+		spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	if spec.TerminationGracePeriodSeconds == nil {
+		spec.TerminationGracePeriodSeconds = pointer.Int64(corev1.DefaultTerminationGracePeriodSeconds)
+	}
+	if spec.SchedulerName == "" {
+		spec.SchedulerName = corev1.DefaultSchedulerName
+	}
+	if spec.EnableServiceLinks == nil {
+		spec.EnableServiceLinks = pointer.Bool(corev1.DefaultEnableServiceLinks)
+	}
+
+	for i := range spec.Volumes {
+		setDefaultsVolume(&spec.Volumes[i])
+	}
+	for i := range spec.InitContainers {
+		setDefaultsContainer(&spec.InitContainers[i], spec.HostNetwork)
+	}
+	for i := range spec.Containers {
+		setDefaultsContainer(&spec.Containers[i], spec.HostNetwork)
+	}
+}
+
+func setDefaultsVolume(v *corev1.Volume) {
+	if v.VolumeSource == (corev1.VolumeSource{}) {
+		v.VolumeSource = corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}
+	}
+	if v.Secret != nil && v.Secret.DefaultMode == nil {
+		v.Secret.DefaultMode = pointer.Int32(corev1.SecretVolumeSourceDefaultMode)
+	}
+	if v.ConfigMap != nil && v.ConfigMap.DefaultMode == nil {
+		v.ConfigMap.DefaultMode = pointer.Int32(corev1.ConfigMapVolumeSourceDefaultMode)
+	}
+	if v.DownwardAPI != nil && v.DownwardAPI.DefaultMode == nil {
+		v.DownwardAPI.DefaultMode = pointer.Int32(corev1.DownwardAPIVolumeSourceDefaultMode)
+	}
+	if v.Projected != nil && v.Projected.DefaultMode == nil {
+		v.Projected.DefaultMode = pointer.Int32(corev1.ProjectedVolumeSourceDefaultMode)
+	}
+	if v.HostPath != nil && v.HostPath.Type == nil {
+		hostPathType := corev1.HostPathUnset
+		v.HostPath.Type = &hostPathType
+	}
+}
+
+func setDefaultsContainer(c *corev1.Container, hostNetwork bool) {
+	if c.ImagePullPolicy == "" {
+		c.ImagePullPolicy = defaultImagePullPolicy(c.Image)
+	}
+	if c.TerminationMessagePath == "" {
+		c.TerminationMessagePath = corev1.TerminationMessagePathDefault
+	}
+	if c.TerminationMessagePolicy == "" {
+		c.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+	}
+
+	for i := range c.Ports {
+		if c.Ports[i].Protocol == "" {
+			c.Ports[i].Protocol = corev1.ProtocolTCP
+		}
+		if hostNetwork && c.Ports[i].HostPort == 0 {
+			c.Ports[i].HostPort = c.Ports[i].ContainerPort
+		}
+	}
+	for i := range c.Env {
+		if c.Env[i].ValueFrom != nil && c.Env[i].ValueFrom.FieldRef != nil && c.Env[i].ValueFrom.FieldRef.APIVersion == "" {
+			c.Env[i].ValueFrom.FieldRef.APIVersion = "v1"
+		}
+	}
+
+	setDefaultsProbe(c.LivenessProbe)
+	setDefaultsProbe(c.ReadinessProbe)
+	setDefaultsProbe(c.StartupProbe)
+}
+
+func setDefaultsRequests(c *corev1.Container) {
+	if c.Resources.Limits == nil {
+		return
+	}
+	if c.Resources.Requests == nil {
+		c.Resources.Requests = corev1.ResourceList{}
+	}
+	for name, quantity := range c.Resources.Limits {
+		if _, ok := c.Resources.Requests[name]; !ok {
+			c.Resources.Requests[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+func setDefaultsProbe(p *corev1.Probe) {
+	if p == nil {
+		return
+	}
+	if p.TimeoutSeconds == 0 {
+		p.TimeoutSeconds = 1
+	}
+	if p.PeriodSeconds == 0 {
+		p.PeriodSeconds = 10
+	}
+	if p.SuccessThreshold == 0 {
+		p.SuccessThreshold = 1
+	}
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = 3
+	}
+	if p.HTTPGet != nil {
+		if p.HTTPGet.Path == "" {
+			p.HTTPGet.Path = "/"
+		}
+		if p.HTTPGet.Scheme == "" {
+			p.HTTPGet.Scheme = corev1.URISchemeHTTP
+		}
+	}
+}
+
+// defaultImagePullPolicy return Always when image use latest tag or no tag, else IfNotPresent
+func defaultImagePullPolicy(image string) corev1.PullPolicy {
+	if tag, hasDigest := imageTag(image); !hasDigest && (tag == "" || tag == "latest") {
+		return corev1.PullAlways
+	}
+
+	return corev1.PullIfNotPresent
+}
+
+func setDefaultsService(s *corev1.Service) {
+	if s.Spec.Type == "" {
+		s.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+	if s.Spec.SessionAffinity == "" {
+		s.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	}
+	if s.Spec.Type != corev1.ServiceTypeExternalName && s.Spec.InternalTrafficPolicy == nil {
+		policy := corev1.ServiceInternalTrafficPolicyCluster
+		s.Spec.InternalTrafficPolicy = &policy
+	}
+	if (s.Spec.Type == corev1.ServiceTypeNodePort || s.Spec.Type == corev1.ServiceTypeLoadBalancer) && s.Spec.ExternalTrafficPolicy == "" {
+		s.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	}
+	for i := range s.Spec.Ports {
+		if s.Spec.Ports[i].Protocol == "" {
+			s.Spec.Ports[i].Protocol = corev1.ProtocolTCP
+		}
+		if s.Spec.Ports[i].TargetPort == intstr.FromInt(0) || s.Spec.Ports[i].TargetPort == intstr.FromString("") {
+			s.Spec.Ports[i].TargetPort = intstr.FromInt(int(s.Spec.Ports[i].Port))
+		}
+	}
+}
+
+func setDefaultsDeployment(d *appsv1.Deployment) {
+	if d.Spec.Replicas == nil {
+		d.Spec.Replicas = pointer.Int32(1)
+	}
+	if d.Spec.Strategy.Type == "" {
+		d.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	}
+	if d.Spec.Strategy.Type == appsv1.RollingUpdateDeploymentStrategyType {
+		if d.Spec.Strategy.RollingUpdate == nil {
+			d.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{}
+		}
+		if d.Spec.Strategy.RollingUpdate.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromString("25%")
+			d.Spec.Strategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		if d.Spec.Strategy.RollingUpdate.MaxSurge == nil {
+			maxSurge := intstr.FromString("25%")
+			d.Spec.Strategy.RollingUpdate.MaxSurge = &maxSurge
+		}
+	}
+	if d.Spec.RevisionHistoryLimit == nil {
+		d.Spec.RevisionHistoryLimit = pointer.Int32(10)
+	}
+	if d.Spec.ProgressDeadlineSeconds == nil {
+		d.Spec.ProgressDeadlineSeconds = pointer.Int32(600)
+	}
+	setDefaultsPodSpec(&d.Spec.Template.Spec)
+}
+
+func setDefaultsStatefulSet(s *appsv1.StatefulSet) {
+	if s.Spec.Replicas == nil {
+		s.Spec.Replicas = pointer.Int32(1)
+	}
+	if s.Spec.PodManagementPolicy == "" {
+		s.Spec.PodManagementPolicy = appsv1.OrderedReadyPodManagement
+	}
+	if s.Spec.UpdateStrategy.Type == "" {
+		s.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+	}
+	if s.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
+		if s.Spec.UpdateStrategy.RollingUpdate == nil {
+			s.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{}
+		}
+		if s.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+			s.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32(0)
+		}
+	}
+	if s.Spec.RevisionHistoryLimit == nil {
+		s.Spec.RevisionHistoryLimit = pointer.Int32(10)
+	}
+	for i := range s.Spec.VolumeClaimTemplates {
+		if s.Spec.VolumeClaimTemplates[i].Spec.VolumeMode == nil {
+			volumeMode := corev1.PersistentVolumeFilesystem
+			s.Spec.VolumeClaimTemplates[i].Spec.VolumeMode = &volumeMode
+		}
+	}
+	setDefaultsPodSpec(&s.Spec.Template.Spec)
+}
+
+func setDefaultsDaemonSet(d *appsv1.DaemonSet) {
+	if d.Spec.UpdateStrategy.Type == "" {
+		d.Spec.UpdateStrategy.Type = appsv1.RollingUpdateDaemonSetStrategyType
+	}
+	if d.Spec.UpdateStrategy.Type == appsv1.RollingUpdateDaemonSetStrategyType {
+		if d.Spec.UpdateStrategy.RollingUpdate == nil {
+			d.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateDaemonSet{}
+		}
+		if d.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable == nil {
+			maxUnavailable := intstr.FromInt(1)
+			d.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		if d.Spec.UpdateStrategy.RollingUpdate.MaxSurge == nil {
+			maxSurge := intstr.FromInt(0)
+			d.Spec.UpdateStrategy.RollingUpdate.MaxSurge = &maxSurge
+		}
+	}
+	if d.Spec.RevisionHistoryLimit == nil {
+		d.Spec.RevisionHistoryLimit = pointer.Int32(10)
+	}
+	setDefaultsPodSpec(&d.Spec.Template.Spec)
+}
+
+func setDefaultsJobSpec(spec *batchv1.JobSpec) {
+	if spec.Completions == nil && spec.Parallelism == nil {
+		spec.Completions = pointer.Int32(1)
+		spec.Parallelism = pointer.Int32(1)
+	}
+	if spec.Parallelism == nil {
+		spec.Parallelism = pointer.Int32(1)
+	}
+	if spec.BackoffLimit == nil {
+		spec.BackoffLimit = pointer.Int32(6)
+	}
+	if spec.CompletionMode == nil {
+		completionMode := batchv1.NonIndexedCompletion
+		spec.CompletionMode = &completionMode
+	}
+	if spec.Suspend == nil {
+		spec.Suspend = pointer.Bool(false)
+	}
+	setDefaultsPodSpec(&spec.Template.Spec)
+}
+
+func setDefaultsCronJob(c *batchv1.CronJob) {
+	if c.Spec.ConcurrencyPolicy == "" {
+		c.Spec.ConcurrencyPolicy = batchv1.AllowConcurrent
+	}
+	if c.Spec.Suspend == nil {
+		c.Spec.Suspend = pointer.Bool(false)
+	}
+	if c.Spec.SuccessfulJobsHistoryLimit == nil {
+		c.Spec.SuccessfulJobsHistoryLimit = pointer.Int32(3)
+	}
+	if c.Spec.FailedJobsHistoryLimit == nil {
+		c.Spec.FailedJobsHistoryLimit = pointer.Int32(1)
+	}
+	setDefaultsJobSpec(&c.Spec.JobTemplate.Spec)
+}
+
+func setDefaultsNetworkPolicy(n *networkingv1.NetworkPolicy) {
+	for i := range n.Spec.Ingress {
+		for j := range n.Spec.Ingress[i].Ports {
+			if n.Spec.Ingress[i].Ports[j].Protocol == nil {
+				protocol := corev1.ProtocolTCP
+				n.Spec.Ingress[i].Ports[j].Protocol = &protocol
+			}
+		}
+	}
+	for i := range n.Spec.Egress {
+		for j := range n.Spec.Egress[i].Ports {
+			if n.Spec.Egress[i].Ports[j].Protocol == nil {
+				protocol := corev1.ProtocolTCP
+				n.Spec.Egress[i].Ports[j].Protocol = &protocol
+			}
+		}
+	}
+	if len(n.Spec.PolicyTypes) == 0 {
+		n.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if len(n.Spec.Egress) > 0 {
+			n.Spec.PolicyTypes = append(n.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+}
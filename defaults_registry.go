@@ -0,0 +1,80 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Defaults is the set of baseline values applied by builders on Build
+// Each value is only applied when not already set, so it can always be overridden.
+type Defaults struct {
+	// ImagePullPolicy is the default image pull policy of containers
+	ImagePullPolicy corev1.PullPolicy
+
+	// Resources is the default resources of containers
+	Resources *corev1.ResourceRequirements
+
+	// SecurityContext is the default security context of containers
+	SecurityContext *corev1.SecurityContext
+
+	// Labels are the default labels of objects and pod templates
+	Labels map[string]string
+
+	// Scheme is used to set apiVersion and kind of objects, like needed by server side apply or YAML export
+	// Objects with types not registered on scheme are keeped as is.
+	Scheme *runtime.Scheme
+}
+
+// GlobalDefaults is the package level defaults, used by builders that not have their own defaults
+// It must be set at startup, before use builders.
+var GlobalDefaults = &Defaults{}
+
+// ApplyToPodTemplate permit to apply defaults on pod template
+func (h *Defaults) ApplyToPodTemplate(pts *corev1.PodTemplateSpec) {
+	if h == nil || pts == nil {
+		return
+	}
+
+	pts.Labels = mergeMap(pts.Labels, h.Labels)
+
+	for i := range pts.Spec.InitContainers {
+		h.ApplyToContainer(&pts.Spec.InitContainers[i])
+	}
+	for i := range pts.Spec.Containers {
+		h.ApplyToContainer(&pts.Spec.Containers[i])
+	}
+}
+
+// ApplyToContainer permit to apply defaults on container
+func (h *Defaults) ApplyToContainer(container *corev1.Container) {
+	if h == nil || container == nil {
+		return
+	}
+
+	if container.ImagePullPolicy == "" {
+		container.ImagePullPolicy = h.ImagePullPolicy
+	}
+	if h.Resources != nil && container.Resources.Limits == nil && container.Resources.Requests == nil {
+		container.Resources = *h.Resources.DeepCopy()
+	}
+	if container.SecurityContext == nil && h.SecurityContext != nil {
+		container.SecurityContext = h.SecurityContext.DeepCopy()
+	}
+}
+
+// ApplyToObject permit to apply defaults on object metadata
+func (h *Defaults) ApplyToObject(o client.Object) {
+	if h == nil || o == nil {
+		return
+	}
+
+	o.SetLabels(mergeMap(o.GetLabels(), h.Labels))
+
+	if h.Scheme != nil && o.GetObjectKind().GroupVersionKind().Empty() {
+		if gvk, err := apiutil.GVKForObject(o, h.Scheme); err == nil {
+			o.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+	}
+}
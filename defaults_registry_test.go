@@ -0,0 +1,94 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func TestDefaults(t *testing.T) {
+	defaults := &Defaults{
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot: pointer.Bool(true),
+		},
+		Labels: map[string]string{
+			"team": "platform",
+			"app":  "default",
+		},
+	}
+
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(defaults).
+		WithLabels(map[string]string{"app": "test"}).
+		WithContainers([]corev1.Container{
+			{
+				Name: "default",
+			},
+			{
+				Name:            "override",
+				ImagePullPolicy: corev1.PullAlways,
+				SecurityContext: &corev1.SecurityContext{},
+			},
+		}).
+		Build()
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test", "team": "platform"}, pts.Labels)
+	assert.Equal(t, corev1.PullIfNotPresent, pts.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, *defaults.Resources, pts.Spec.Containers[0].Resources)
+	assert.Equal(t, defaults.SecurityContext, pts.Spec.Containers[0].SecurityContext)
+	assert.Equal(t, corev1.PullAlways, pts.Spec.Containers[1].ImagePullPolicy)
+	assert.Equal(t, &corev1.SecurityContext{}, pts.Spec.Containers[1].SecurityContext)
+
+	// When no defaults
+	pts, err = NewPodTemplateBuilder().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &corev1.PodTemplateSpec{}, pts)
+}
+
+func TestDefaultsScheme(t *testing.T) {
+	defaults := &Defaults{Scheme: scheme.Scheme}
+
+	cm, err := NewConfigMapBuilder().
+		WithDefaults(defaults).
+		WithName("test").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", cm.APIVersion)
+	assert.Equal(t, "ConfigMap", cm.Kind)
+
+	d, err := NewDeploymentBuilder().
+		WithDefaults(defaults).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/v1", d.APIVersion)
+	assert.Equal(t, "Deployment", d.Kind)
+
+	// Without scheme
+	cm, err = NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		Build()
+	assert.NoError(t, err)
+	assert.Empty(t, cm.Kind)
+
+	// Type not registered on scheme
+	cm, err = NewConfigMapBuilder().
+		WithDefaults(&Defaults{Scheme: runtime.NewScheme()}).
+		Build()
+	assert.NoError(t, err)
+	assert.Empty(t, cm.Kind)
+}
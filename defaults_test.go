@@ -0,0 +1,66 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	pts := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "test",
+					Image: "nginx",
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: 80,
+						},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{
+								Port: intstr.FromInt(80),
+							},
+						},
+					},
+				},
+				{
+					Name:            "test2",
+					Image:           "nginx:1.0",
+					ImagePullPolicy: corev1.PullNever,
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "empty",
+				},
+			},
+		},
+	}
+
+	ApplyDefaults(pts)
+
+	assert.Equal(t, corev1.DNSClusterFirst, pts.Spec.DNSPolicy)
+	assert.Equal(t, corev1.RestartPolicyAlways, pts.Spec.RestartPolicy)
+	assert.Equal(t, pointer.Int64(30), pts.Spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, corev1.PullAlways, pts.Spec.Containers[0].ImagePullPolicy)
+	assert.Equal(t, corev1.PullNever, pts.Spec.Containers[1].ImagePullPolicy)
+	assert.Equal(t, corev1.ProtocolTCP, pts.Spec.Containers[0].Ports[0].Protocol)
+	assert.Equal(t, "/", pts.Spec.Containers[0].ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, int32(3), pts.Spec.Containers[0].ReadinessProbe.FailureThreshold)
+	assert.NotNil(t, pts.Spec.Volumes[0].EmptyDir)
+
+	// Must be idempotent
+	expected := pts.DeepCopy()
+	ApplyDefaults(pts)
+	assert.Equal(t, expected, pts)
+
+	assert.Equal(t, corev1.PullIfNotPresent, defaultImagePullPolicy("registry:5000/nginx:1.0"))
+	assert.Equal(t, corev1.PullAlways, defaultImagePullPolicy("registry:5000/nginx"))
+	assert.Equal(t, corev1.PullIfNotPresent, defaultImagePullPolicy("nginx@sha256:abc"))
+}
@@ -0,0 +1,230 @@
+package k8sbuilder
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+// DeploymentBuilder is the deployment builder interface
+type DeploymentBuilder interface {
+	WithName(name string, opts ...WithOption) DeploymentBuilder
+	WithNamespace(namespace string, opts ...WithOption) DeploymentBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) DeploymentBuilder
+	WithoutLabels(keys ...string) DeploymentBuilder
+	WithFinalizer(name string) DeploymentBuilder
+	WithoutFinalizer(name string) DeploymentBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) DeploymentBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) DeploymentBuilder
+	WithoutAnnotations(keys ...string) DeploymentBuilder
+	WithReplicas(nb int32, opts ...WithOption) DeploymentBuilder
+	WithStrategy(strategy appsv1.DeploymentStrategy, opts ...WithOption) DeploymentBuilder
+	WithSelector(selector *metav1.LabelSelector, opts ...WithOption) DeploymentBuilder
+	WithPodTemplate(fn func(ptb PodTemplateBuilder)) DeploymentBuilder
+	WithDefaults(defaults *Defaults) DeploymentBuilder
+	PodTemplate() PodTemplateBuilder
+	Deployment() *appsv1.Deployment
+	Build() (d *appsv1.Deployment, err error)
+	Variants(variants ...Variant) (deployments []*appsv1.Deployment, err error)
+}
+
+// DeploymentBuilderDefault is the default implementation of deployment builder
+type DeploymentBuilderDefault struct {
+	objectMeta
+	deployment  *appsv1.Deployment
+	podTemplate PodTemplateBuilder
+	defaults    *Defaults
+	err         error
+}
+
+// NewDeploymentBuilder permit to init deployment builder
+func NewDeploymentBuilder() DeploymentBuilder {
+	deployment := &appsv1.Deployment{}
+
+	return &DeploymentBuilderDefault{
+		objectMeta:  newObjectMeta(deployment),
+		deployment:  deployment,
+		podTemplate: NewPodTemplateBuilder(),
+	}
+}
+
+// PodTemplate permit to get the pod template sub-builder
+// Changes done on it are set on deployment on Build
+func (h *DeploymentBuilderDefault) PodTemplate() PodTemplateBuilder {
+	return h.podTemplate
+}
+
+// WithPodTemplate permit to change the pod template without break the fluent chain
+func (h *DeploymentBuilderDefault) WithPodTemplate(fn func(ptb PodTemplateBuilder)) DeploymentBuilder {
+	if fn != nil {
+		fn(h.podTemplate)
+	}
+
+	return h
+}
+
+// Deployment permit to get current deployment
+func (h *DeploymentBuilderDefault) Deployment() *appsv1.Deployment {
+	return h.deployment
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+// They are also used by the pod template sub-builder
+func (h *DeploymentBuilderDefault) WithDefaults(defaults *Defaults) DeploymentBuilder {
+	h.defaults = defaults
+	h.podTemplate.WithDefaults(defaults)
+
+	return h
+}
+
+// Build permit to get the deployment with the pod template built by the sub-builder
+// If no selector is set, it's derived from the stable pod template labels (see PodTemplateBuilder.Selector), else it must match them
+func (h *DeploymentBuilderDefault) Build() (d *appsv1.Deployment, err error) {
+	defer observeBuild("Deployment", time.Now())
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	pts, err := h.podTemplate.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when build pod template")
+	}
+	h.deployment.Spec.Template = *pts.DeepCopy()
+
+	if h.deployment.Spec.Selector == nil {
+		if h.deployment.Spec.Selector, err = h.podTemplate.Selector(); err != nil {
+			return nil, errors.Wrap(err, "Error when derive selector")
+		}
+	} else if err = ValidateSelector(h.deployment.Spec.Selector, pts.Labels); err != nil {
+		return nil, err
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.deployment)
+
+	if errs := ValidateMetadata(h.deployment); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.deployment, nil
+}
+
+// WithName permit to set name
+func (h *DeploymentBuilderDefault) WithName(name string, opts ...WithOption) DeploymentBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *DeploymentBuilderDefault) WithNamespace(namespace string, opts ...WithOption) DeploymentBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *DeploymentBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) DeploymentBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *DeploymentBuilderDefault) WithoutLabels(keys ...string) DeploymentBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *DeploymentBuilderDefault) WithFinalizer(name string) DeploymentBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *DeploymentBuilderDefault) WithoutFinalizer(name string) DeploymentBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *DeploymentBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) DeploymentBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *DeploymentBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) DeploymentBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *DeploymentBuilderDefault) WithoutAnnotations(keys ...string) DeploymentBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithReplicas permit to set replicas
+func (h *DeploymentBuilderDefault) WithReplicas(nb int32, opts ...WithOption) DeploymentBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.deployment.Spec.Replicas == nil {
+		h.deployment.Spec.Replicas = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithStrategy permit to set deployment strategy
+func (h *DeploymentBuilderDefault) WithStrategy(strategy appsv1.DeploymentStrategy, opts ...WithOption) DeploymentBuilder {
+	// Overwrite
+	if IsOverwrite(opts) {
+		h.deployment.Spec.Strategy = strategy
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.deployment.Spec.Strategy).IsZero() {
+		h.deployment.Spec.Strategy = strategy
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		if err := MergeK8s(&h.deployment.Spec.Strategy, h.deployment.Spec.Strategy, strategy); err != nil {
+			panic(err)
+		}
+	}
+
+	return h
+}
+
+// WithSelector permit to set selector
+// If not set, it's derived from the stable pod template labels on Build
+func (h *DeploymentBuilderDefault) WithSelector(selector *metav1.LabelSelector, opts ...WithOption) DeploymentBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.deployment.Spec.Selector == nil {
+		h.deployment.Spec.Selector = selector
+	}
+
+	return h
+}
@@ -0,0 +1,80 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeploymentBuilder(t *testing.T) {
+	b := NewDeploymentBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithReplicas(2).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+
+	// Sub-builder changes flow back to deployment
+	b.PodTemplate().WithContainers([]corev1.Container{{Name: "test", Image: "test:1.0.0"}})
+
+	d, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", d.Name)
+	assert.Equal(t, int32(2), *d.Spec.Replicas)
+	assert.Equal(t, map[string]string{"app": "test"}, d.Spec.Template.Labels)
+	assert.Equal(t, "test", d.Spec.Template.Spec.Containers[0].Name)
+	assert.Equal(t, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}, d.Spec.Selector)
+
+	// When selector not match pod template
+	_, err = b.WithSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}}).Build()
+	assert.Error(t, err)
+
+	// When no pod labels to derive selector
+	_, err = NewDeploymentBuilder().WithDefaults(&Defaults{}).Build()
+	assert.Error(t, err)
+}
+
+func TestDeploymentBuilderStableSelector(t *testing.T) {
+	build := func(version string) *appsv1.Deployment {
+		d, err := NewDeploymentBuilder().
+			WithDefaults(&Defaults{Labels: map[string]string{"team": "search"}}).
+			WithName("test").
+			WithPodTemplate(func(ptb PodTemplateBuilder) {
+				ptb.WithRecommendedLabels("elasticsearch", "es", version, "database", "search", "operator")
+			}).
+			Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	// Only name and instance are used, so version bump not change the immutable selector
+	d := build("8.0.0")
+	assert.Equal(t, map[string]string{LabelName: "elasticsearch", LabelInstance: "es"}, d.Spec.Selector.MatchLabels)
+	assert.Equal(t, d.Spec.Selector, build("8.1.0").Spec.Selector)
+
+	// Without recommended labels, version and default labels are not used
+	d, err := NewDeploymentBuilder().
+		WithDefaults(&Defaults{Labels: map[string]string{"team": "search"}}).
+		WithName("test").
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test", LabelVersion: "1.0.0"})
+		}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test"}, d.Spec.Selector.MatchLabels)
+	assert.Equal(t, "search", d.Spec.Template.Labels["team"])
+
+	// When only default labels
+	_, err = NewDeploymentBuilder().
+		WithDefaults(&Defaults{Labels: map[string]string{"team": "search"}}).
+		WithName("test").
+		Build()
+	assert.Error(t, err)
+}
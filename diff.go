@@ -0,0 +1,135 @@
+package k8sbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Diff permit to get the semantic diff between the live object and the expected object
+// Only fields set on expected object are compared, so fields defaulted by API server, managed by other
+// controllers and managedFields are ignored. See Sanitize.
+// The status is not compared by default. Use IncludeStatus to compare it.
+// Secret values are redacted, so the diff can be logged.
+// It return empty string if there are no diff
+func Diff(expected, live client.Object, opts ...ExportOption) (diff string, err error) {
+	if expected == nil || live == nil {
+		return "", errors.New("Expected and live object can't be nil")
+	}
+
+	excludeStatus := withoutStatus(true, opts)
+	current := live.DeepCopyObject().(client.Object)
+	merged := current.DeepCopyObject().(client.Object)
+	if err = mergeOnLive(expected, merged, excludeStatus); err != nil {
+		return "", errors.Wrap(err, "Error when merge expected object on live object")
+	}
+
+	// Fields managed by API server are not compared
+	sanitize(current, excludeStatus)
+	sanitize(merged, excludeStatus)
+
+	return cmp.Diff(RedactSecret(current), RedactSecret(merged)), nil
+}
+
+// FieldDiff is the diff of one field
+type FieldDiff struct {
+	Path   string
+	Before string
+	After  string
+}
+
+// DiffPaths permit to get the paths of fields that differ between before and after, like Spec.Containers[0].Image
+// It's a short summary of the diff, usefull on logs
+func DiffPaths(before, after any) []string {
+	diffs := DiffFields(before, after)
+	paths := make([]string, 0, len(diffs))
+	for _, diff := range diffs {
+		paths = append(paths, diff.Path)
+	}
+
+	return paths
+}
+
+// DiffFields permit to get the fields that differ between before and after, with their values
+// Secret values are redacted.
+func DiffFields(before, after any) []FieldDiff {
+	r := &pathReporter{
+		diffs: make([]FieldDiff, 0),
+	}
+	cmp.Equal(redact(before), redact(after), cmp.Reporter(r))
+
+	return r.diffs
+}
+
+// pathReporter is a cmp reporter that collect the path and the values of each difference
+type pathReporter struct {
+	path  cmp.Path
+	diffs []FieldDiff
+}
+
+func (r *pathReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *pathReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+func (r *pathReporter) Report(rs cmp.Result) {
+	if rs.Equal() {
+		return
+	}
+
+	var path strings.Builder
+	for _, step := range r.path {
+		switch s := step.(type) {
+		case cmp.StructField:
+			if path.Len() > 0 {
+				path.WriteString(".")
+			}
+			path.WriteString(s.Name())
+		case cmp.SliceIndex:
+			// Key is -1 when the item exist only on one side
+			key := s.Key()
+			if key == -1 {
+				if before, after := s.SplitKeys(); before >= 0 {
+					key = before
+				} else {
+					key = after
+				}
+			}
+			fmt.Fprintf(&path, "[%d]", key)
+		case cmp.MapIndex:
+			fmt.Fprintf(&path, "[%v]", s.Key())
+		}
+	}
+
+	before, after := r.path.Last().Values()
+	r.diffs = append(r.diffs, FieldDiff{
+		Path:   path.String(),
+		Before: formatValue(before),
+		After:  formatValue(after),
+	})
+}
+
+// formatValue permit to get the string of value, or <none> if it not exist
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	if !v.CanInterface() {
+		return v.String()
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+		return "<nil>"
+	}
+	if v.Kind() == reflect.Ptr {
+		return fmt.Sprintf("%+v", v.Elem().Interface())
+	}
+
+	return fmt.Sprintf("%+v", v.Interface())
+}
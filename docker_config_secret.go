@@ -0,0 +1,147 @@
+package k8sbuilder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DockerConfigSecretBuilder is the builder interface of secret of type kubernetes.io/dockerconfigjson
+// Use the secret name on pod template image pull secrets.
+type DockerConfigSecretBuilder interface {
+	WithName(name string, opts ...WithOption) DockerConfigSecretBuilder
+	WithNamespace(namespace string, opts ...WithOption) DockerConfigSecretBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) DockerConfigSecretBuilder
+	WithoutLabels(keys ...string) DockerConfigSecretBuilder
+	WithFinalizer(name string) DockerConfigSecretBuilder
+	WithoutFinalizer(name string) DockerConfigSecretBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) DockerConfigSecretBuilder
+	WithoutAnnotations(keys ...string) DockerConfigSecretBuilder
+	WithRegistry(host string, username string, password string, email string) DockerConfigSecretBuilder
+	WithDefaults(defaults *Defaults) DockerConfigSecretBuilder
+	Build() (s *corev1.Secret, err error)
+}
+
+// DockerConfigSecretBuilderDefault is the default implementation of docker config secret builder
+type DockerConfigSecretBuilderDefault struct {
+	secret SecretBuilder
+	auths  map[string]dockerConfigEntry
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+type dockerConfigEntry struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Auth     string `json:"auth,omitempty"`
+}
+
+// NewDockerConfigSecretBuilder permit to init docker config secret builder
+func NewDockerConfigSecretBuilder() DockerConfigSecretBuilder {
+	return &DockerConfigSecretBuilderDefault{
+		secret: NewSecretBuilder(),
+		auths:  map[string]dockerConfigEntry{},
+	}
+}
+
+// WithName permit to set name
+func (h *DockerConfigSecretBuilderDefault) WithName(name string, opts ...WithOption) DockerConfigSecretBuilder {
+	h.secret.WithName(name, opts...)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *DockerConfigSecretBuilderDefault) WithNamespace(namespace string, opts ...WithOption) DockerConfigSecretBuilder {
+	h.secret.WithNamespace(namespace, opts...)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *DockerConfigSecretBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) DockerConfigSecretBuilder {
+	h.secret.WithLabels(labels, opts...)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *DockerConfigSecretBuilderDefault) WithoutLabels(keys ...string) DockerConfigSecretBuilder {
+	h.secret.WithoutLabels(keys...)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *DockerConfigSecretBuilderDefault) WithFinalizer(name string) DockerConfigSecretBuilder {
+	h.secret.WithFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *DockerConfigSecretBuilderDefault) WithoutFinalizer(name string) DockerConfigSecretBuilder {
+	h.secret.WithoutFinalizer(name)
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *DockerConfigSecretBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) DockerConfigSecretBuilder {
+	h.secret.WithAnnotations(annotations, opts...)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *DockerConfigSecretBuilderDefault) WithoutAnnotations(keys ...string) DockerConfigSecretBuilder {
+	h.secret.WithoutAnnotations(keys...)
+
+	return h
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *DockerConfigSecretBuilderDefault) WithDefaults(defaults *Defaults) DockerConfigSecretBuilder {
+	h.secret.WithDefaults(defaults)
+
+	return h
+}
+
+// WithRegistry permit to add credentials of registry
+// Registries are merged by host, so the last credentials of host win
+func (h *DockerConfigSecretBuilderDefault) WithRegistry(host string, username string, password string, email string) DockerConfigSecretBuilder {
+	h.auths[host] = dockerConfigEntry{
+		Username: username,
+		Password: password,
+		Email:    email,
+		Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+	}
+
+	return h
+}
+
+// Build permit to get the secret with the .dockerconfigjson key
+// It fail if there are no registry
+func (h *DockerConfigSecretBuilderDefault) Build() (s *corev1.Secret, err error) {
+	if len(h.auths) == 0 {
+		return nil, errors.New("At least one registry must be set")
+	}
+
+	// JSON encoding sort hosts, so the secret is stable between builds
+	dockerConfig, err := json.Marshal(dockerConfigJSON{Auths: h.auths})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal docker config")
+	}
+
+	return h.secret.
+		WithType(corev1.SecretTypeDockerConfigJson).
+		WithData(map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfig,
+		}, Merge).
+		Build()
+}
@@ -0,0 +1,31 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDockerConfigSecretBuilder(t *testing.T) {
+	s, err := NewDockerConfigSecretBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("registry").
+		WithRegistry("registry.example.com", "old", "old", "").
+		WithRegistry("docker.io", "user", "pass", "user@example.com").
+		WithRegistry("registry.example.com", "user", "pass", "").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "registry", s.Name)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, s.Type)
+	assert.JSONEq(t, `{
+		"auths": {
+			"docker.io": {"username": "user", "password": "pass", "email": "user@example.com", "auth": "dXNlcjpwYXNz"},
+			"registry.example.com": {"username": "user", "password": "pass", "auth": "dXNlcjpwYXNz"}
+		}
+	}`, string(s.Data[corev1.DockerConfigJsonKey]))
+
+	// When no registry
+	_, err = NewDockerConfigSecretBuilder().Build()
+	assert.Error(t, err)
+}
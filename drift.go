@@ -0,0 +1,38 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodTemplateDrift permit to get the paths of fields of expected pod template that drifted on live pod template, like Spec.Containers[0].Image
+// Only fields set on expected pod template are compared, so fields defaulted by API server or set by other controllers are ignored.
+// An error is returned if expected pod template can't be merged on live pod template.
+func PodTemplateDrift(expected, live *corev1.PodTemplateSpec) (paths []string, err error) {
+	if expected == nil {
+		return nil, nil
+	}
+	if live == nil {
+		live = &corev1.PodTemplateSpec{}
+	}
+
+	// Pod template spec is not an object, so it's wrapped on pod template to be merged
+	current := &corev1.PodTemplate{Template: *live}
+	merged := current.DeepCopy()
+	if err = mergeOnLive(&corev1.PodTemplate{Template: *expected}, merged, true); err != nil {
+		return nil, err
+	}
+
+	return DiffPaths(current.Template, merged.Template), nil
+}
+
+// IsDrifted permit to know if the live pod template drifted from the pod template of builder, and the paths of drifted fields
+// Fields defaulted by API server are ignored, see PodTemplateDrift. Call it after Build, so the defaults of builder are applied.
+// It return true without paths if live pod template can't be compared, so the caller update it.
+func (h *PodTemplateBuilderDefault) IsDrifted(live *corev1.PodTemplateSpec) (bool, []string) {
+	paths, err := PodTemplateDrift(h.podTemplate, live)
+	if err != nil {
+		return true, nil
+	}
+
+	return len(paths) > 0, paths
+}
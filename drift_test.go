@@ -0,0 +1,41 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsDrifted(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test"}).
+		WithContainers([]corev1.Container{{Name: "app", Image: "app:1"}})
+	expected, err := b.Build()
+	assert.NoError(t, err)
+
+	// Live with server defaults and fields set by other controllers
+	live := expected.DeepCopy()
+	live.Annotations = map[string]string{"kubectl.kubernetes.io/restartedAt": "now"}
+	live.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	live.Spec.DNSPolicy = corev1.DNSClusterFirst
+	live.Spec.Containers[0].ImagePullPolicy = corev1.PullIfNotPresent
+	live.Spec.Containers[0].TerminationMessagePath = corev1.TerminationMessagePathDefault
+	drifted, paths := b.IsDrifted(live)
+	assert.False(t, drifted)
+	assert.Empty(t, paths)
+
+	// Live with drift
+	live.Spec.Containers[0].Image = "app:2"
+	live.Labels["app"] = "other"
+	drifted, paths = b.IsDrifted(live)
+	assert.True(t, drifted)
+	assert.ElementsMatch(t, []string{"ObjectMeta.Labels[app]", "Spec.Containers[0].Image"}, paths)
+
+	// Empty live
+	paths, err = PodTemplateDrift(expected, &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paths)
+}
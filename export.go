@@ -0,0 +1,78 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ExcludeStatus remove the status from exported object
+	ExcludeStatus ExportOption = "excludeStatus"
+	// IncludeStatus keep the status on exported object
+	IncludeStatus ExportOption = "includeStatus"
+)
+
+// ExportOption permit to choose what is exported by ToYAML, SSAPatch and Diff
+type ExportOption string
+
+// withoutStatus permit to know if status must be removed, according to export options
+// The last option win. If there are no option, it return the default value.
+func withoutStatus(defaultValue bool, opts []ExportOption) bool {
+	for _, opt := range opts {
+		switch opt {
+		case ExcludeStatus:
+			defaultValue = true
+		case IncludeStatus:
+			defaultValue = false
+		}
+	}
+
+	return defaultValue
+}
+
+// ToYAML permit to render the object as YAML
+// The status is keeped by default. Use ExcludeStatus to remove it.
+func ToYAML(o client.Object, opts ...ExportOption) (data []byte, err error) {
+	if o == nil {
+		return nil, errors.New("Object can't be nil")
+	}
+
+	data, err = marshalObject(o, withoutStatus(false, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = yaml.JSONToYAML(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when convert object to YAML")
+	}
+
+	return data, nil
+}
+
+// marshalObject permit to marshal the object as JSON, with or without the status
+// Typed status are structs, so they are marshaled even if they are empty. The status is removed from JSON instead.
+func marshalObject(o client.Object, excludeStatus bool) (data []byte, err error) {
+	if !excludeStatus {
+		if data, err = json.Marshal(o); err != nil {
+			return nil, errors.Wrap(err, "Error when marshal object")
+		}
+		return data, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when convert object to unstructured")
+	}
+	delete(content, "status")
+
+	if data, err = json.Marshal(content); err != nil {
+		return nil, errors.Wrap(err, "Error when marshal object")
+	}
+
+	return data, nil
+}
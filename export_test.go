@@ -0,0 +1,83 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExportStatus(t *testing.T) {
+	d := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 2,
+		},
+	}
+
+	// ToYAML keep status by default
+	data, err := ToYAML(d)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "status:")
+	data, err = ToYAML(d, ExcludeStatus)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "status:")
+	assert.Contains(t, string(data), "name: test")
+	assert.Equal(t, int32(2), d.Status.Replicas)
+
+	// The last option win
+	data, err = ToYAML(d, ExcludeStatus, IncludeStatus)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "status:")
+
+	// SSAPatch
+	patch, _, err := SSAPatch(d, "operator", ExcludeStatus)
+	assert.NoError(t, err)
+	expected := map[string]any{}
+	if err = json.Unmarshal(patch, &expected); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, expected, "status")
+	assert.Equal(t, "Deployment", expected["kind"])
+
+	// Diff ignore status by default
+	live := d.DeepCopy()
+	live.Status.Replicas = 3
+	diff, err := Diff(d, live)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+	diff, err = Diff(d, live, IncludeStatus)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, diff)
+
+	// Per builder
+	i := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithExportOptions(ExcludeStatus)
+	data, err = i.ToYAML()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "status:")
+	assert.Contains(t, string(data), "kind: Ingress")
+	patch, _, err = i.SSAPatch("operator")
+	assert.NoError(t, err)
+	expected = map[string]any{}
+	if err = json.Unmarshal(patch, &expected); err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, expected, "status")
+
+	data, err = NewIngressBuilder().WithDefaults(&Defaults{}).WithName("test").ToYAML()
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "status:")
+}
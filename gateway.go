@@ -0,0 +1,180 @@
+package k8sbuilder
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GatewayAPIGroupVersion is the Gateway API group version used by IngressToGatewayAPI
+var GatewayAPIGroupVersion = schema.GroupVersion{Group: "gateway.networking.k8s.io", Version: "v1beta1"}
+
+// IngressToGatewayAPI permit to convert a built ingress to the equivalent Gateway and HTTPRoutes
+// Objects are returned as unstructured to not depend on Gateway API module.
+// The gateway class name is read from ingress class name if gatewayClassName is empty.
+// It create one HTTPRoute per ingress rule, and one listener per host and protocol on Gateway.
+func IngressToGatewayAPI(i *networkingv1.Ingress, gatewayClassName string) (gateway *unstructured.Unstructured, routes []*unstructured.Unstructured, err error) {
+	if i == nil {
+		return nil, nil, errors.New("Ingress can't be nil")
+	}
+	if gatewayClassName == "" && i.Spec.IngressClassName != nil {
+		gatewayClassName = *i.Spec.IngressClassName
+	}
+	if gatewayClassName == "" {
+		return nil, nil, errors.New("Gateway class name can't be empty")
+	}
+
+	gateway = newGatewayAPIObject("Gateway", i.ObjectMeta, i.Name)
+	listeners := make([]any, 0)
+	hosts := make([]string, 0)
+	for _, rule := range i.Spec.Rules {
+		if !funk.ContainsString(hosts, rule.Host) {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = append(hosts, "")
+	}
+	for index, host := range hosts {
+		listeners = append(listeners, newListener(fmt.Sprintf("http-%d", index), host, "HTTP", 80))
+	}
+	for index, tls := range i.Spec.TLS {
+		tlsHosts := tls.Hosts
+		if len(tlsHosts) == 0 {
+			tlsHosts = []string{""}
+		}
+		for indexHost, host := range tlsHosts {
+			listener := newListener(fmt.Sprintf("https-%d-%d", index, indexHost), host, "HTTPS", 443)
+			listener["tls"] = map[string]any{
+				"mode": "Terminate",
+				"certificateRefs": []any{
+					map[string]any{
+						"kind": "Secret",
+						"name": tls.SecretName,
+					},
+				},
+			}
+			listeners = append(listeners, listener)
+		}
+	}
+	gateway.Object["spec"] = map[string]any{
+		"gatewayClassName": gatewayClassName,
+		"listeners":        listeners,
+	}
+
+	parentRefs := []any{
+		map[string]any{
+			"name": i.Name,
+		},
+	}
+
+	routes = make([]*unstructured.Unstructured, 0, len(i.Spec.Rules)+1)
+	for index, rule := range i.Spec.Rules {
+		routeRules := make([]any, 0)
+		if rule.HTTP != nil {
+			for _, path := range rule.HTTP.Paths {
+				routeRule, err := newRouteRule(&path.Backend, &path)
+				if err != nil {
+					return nil, nil, errors.Wrapf(err, "Error when convert path %s of rule %d", path.Path, index)
+				}
+				routeRules = append(routeRules, routeRule)
+			}
+		}
+
+		spec := map[string]any{
+			"parentRefs": parentRefs,
+			"rules":      routeRules,
+		}
+		if rule.Host != "" {
+			spec["hostnames"] = []any{rule.Host}
+		}
+
+		route := newGatewayAPIObject("HTTPRoute", i.ObjectMeta, fmt.Sprintf("%s-%d", i.Name, index))
+		route.Object["spec"] = spec
+		routes = append(routes, route)
+	}
+
+	// Default backend is converted as route without match
+	if i.Spec.DefaultBackend != nil {
+		routeRule, err := newRouteRule(i.Spec.DefaultBackend, nil)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error when convert default backend")
+		}
+		route := newGatewayAPIObject("HTTPRoute", i.ObjectMeta, fmt.Sprintf("%s-default", i.Name))
+		route.Object["spec"] = map[string]any{
+			"parentRefs": parentRefs,
+			"rules":      []any{routeRule},
+		}
+		routes = append(routes, route)
+	}
+
+	return gateway, routes, nil
+}
+
+func newGatewayAPIObject(kind string, meta metav1.ObjectMeta, name string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{Object: map[string]any{}}
+	o.SetGroupVersionKind(GatewayAPIGroupVersion.WithKind(kind))
+	o.SetName(name)
+	o.SetNamespace(meta.Namespace)
+	o.SetLabels(meta.Labels)
+	o.SetOwnerReferences(meta.OwnerReferences)
+
+	return o
+}
+
+func newListener(name, host, protocol string, port int64) map[string]any {
+	listener := map[string]any{
+		"name":     name,
+		"protocol": protocol,
+		"port":     port,
+	}
+	if host != "" {
+		listener["hostname"] = host
+	}
+
+	return listener
+}
+
+func newRouteRule(backend *networkingv1.IngressBackend, path *networkingv1.HTTPIngressPath) (map[string]any, error) {
+	if backend.Service == nil {
+		return nil, errors.New("Only service backend can be converted")
+	}
+	if backend.Service.Port.Name != "" {
+		return nil, errors.Errorf("Service port name %s can't be converted, HTTPRoute need port number", backend.Service.Port.Name)
+	}
+
+	routeRule := map[string]any{
+		"backendRefs": []any{
+			map[string]any{
+				"name": backend.Service.Name,
+				"port": int64(backend.Service.Port.Number),
+			},
+		},
+	}
+
+	if path != nil {
+		matchType := "PathPrefix"
+		if path.PathType != nil && *path.PathType == networkingv1.PathTypeExact {
+			matchType = "Exact"
+		}
+		value := path.Path
+		if value == "" {
+			value = "/"
+		}
+		routeRule["matches"] = []any{
+			map[string]any{
+				"path": map[string]any{
+					"type":  matchType,
+					"value": value,
+				},
+			},
+		}
+	}
+
+	return routeRule, nil
+}
@@ -0,0 +1,98 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestIngressToGatewayAPI(t *testing.T) {
+	exact := networkingv1.PathTypeExact
+	i := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"api.example.com"},
+					SecretName: "api-tls",
+				},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "api.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/v1",
+									PathType: &exact,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "api",
+											Port: networkingv1.ServiceBackendPort{Number: 8080},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	gateway, routes, err := IngressToGatewayAPI(i, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Gateway", gateway.GetKind())
+	assert.Equal(t, "test", gateway.GetName())
+	assert.Equal(t, map[string]any{
+		"gatewayClassName": "nginx",
+		"listeners": []any{
+			map[string]any{"name": "http-0", "protocol": "HTTP", "port": int64(80), "hostname": "api.example.com"},
+			map[string]any{
+				"name":     "https-0-0",
+				"protocol": "HTTPS",
+				"port":     int64(443),
+				"hostname": "api.example.com",
+				"tls": map[string]any{
+					"mode":            "Terminate",
+					"certificateRefs": []any{map[string]any{"kind": "Secret", "name": "api-tls"}},
+				},
+			},
+		},
+	}, gateway.Object["spec"])
+
+	assert.Len(t, routes, 1)
+	assert.Equal(t, "HTTPRoute", routes[0].GetKind())
+	assert.Equal(t, "test-0", routes[0].GetName())
+	assert.Equal(t, map[string]any{
+		"parentRefs": []any{map[string]any{"name": "test"}},
+		"hostnames":  []any{"api.example.com"},
+		"rules": []any{
+			map[string]any{
+				"matches":     []any{map[string]any{"path": map[string]any{"type": "Exact", "value": "/v1"}}},
+				"backendRefs": []any{map[string]any{"name": "api", "port": int64(8080)}},
+			},
+		},
+	}, routes[0].Object["spec"])
+
+	// Objects must be deep copiable
+	assert.NotPanics(t, func() { gateway.DeepCopy(); routes[0].DeepCopy() })
+
+	// When port name
+	i.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port = networkingv1.ServiceBackendPort{Name: "http"}
+	_, _, err = IngressToGatewayAPI(i, "")
+	assert.Error(t, err)
+
+	// When no gateway class
+	i.Spec.IngressClassName = nil
+	_, _, err = IngressToGatewayAPI(i, "")
+	assert.Error(t, err)
+}
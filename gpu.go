@@ -0,0 +1,56 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ResourceNvidiaGPU is the extended resource name of NVIDIA GPU, exposed by the NVIDIA device plugin
+const ResourceNvidiaGPU = "nvidia.com/gpu"
+
+// GPUToleration permit to get the toleration of the taint set on GPU nodes, like nvidia.com/gpu:NoSchedule
+// Default resource name is nvidia.com/gpu
+func GPUToleration(resourceName string) corev1.Toleration {
+	if resourceName == "" {
+		resourceName = ResourceNvidiaGPU
+	}
+
+	return corev1.Toleration{
+		Key:      resourceName,
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+}
+
+// WithGPU permit to request GPU or any extended resource. Default resource name is nvidia.com/gpu
+// Extended resources can't be overcommitted, so the limit is set and the request, if any, is aligned on it.
+func (h *ContainerBuilderDefault) WithGPU(count int64, resourceName string) ContainerBuilder {
+	if resourceName == "" {
+		resourceName = ResourceNvidiaGPU
+	}
+	quantity := *resource.NewQuantity(count, resource.DecimalSI)
+
+	if h.container.Resources.Limits == nil {
+		h.container.Resources.Limits = corev1.ResourceList{}
+	}
+	h.container.Resources.Limits[corev1.ResourceName(resourceName)] = quantity
+	if _, ok := h.container.Resources.Requests[corev1.ResourceName(resourceName)]; ok {
+		h.container.Resources.Requests[corev1.ResourceName(resourceName)] = quantity
+	}
+
+	return h
+}
+
+// WithGPUScheduling permit to schedule the pod on GPU nodes. Default resource name is nvidia.com/gpu
+// It add the toleration of GPU nodes taint, and merge the node selector if not nil, like cloud.google.com/gke-accelerator.
+// Use it with ContainerBuilder.WithGPU to request the GPU.
+func (h *PodTemplateBuilderDefault) WithGPUScheduling(resourceName string, nodeSelector map[string]string) PodTemplateBuilder {
+	defer h.observe("WithGPUScheduling")()
+
+	h.WithTolerations([]corev1.Toleration{GPUToleration(resourceName)}, Merge)
+	if nodeSelector != nil {
+		h.WithNodeSelector(nodeSelector, Merge)
+	}
+
+	return h
+}
@@ -0,0 +1,46 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestContainerWithGPU(t *testing.T) {
+	c := NewContainerBuilder().
+		WithResource(&corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		}).
+		WithGPU(2, "").
+		Container()
+	assert.Equal(t, int64(2), c.Resources.Limits.Name(ResourceNvidiaGPU, resource.DecimalSI).Value())
+	assert.NotContains(t, c.Resources.Requests, corev1.ResourceName(ResourceNvidiaGPU))
+
+	// Request is aligned on limit
+	c = NewContainerBuilder().
+		WithResource(&corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{"amd.com/gpu": resource.MustParse("4")},
+		}).
+		WithGPU(1, "amd.com/gpu").
+		Container()
+	assert.Equal(t, int64(1), c.Resources.Limits.Name("amd.com/gpu", resource.DecimalSI).Value())
+	assert.Equal(t, int64(1), c.Resources.Requests.Name("amd.com/gpu", resource.DecimalSI).Value())
+}
+
+func TestPodTemplateWithGPUScheduling(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithTolerations([]corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}).
+		WithNodeSelector(map[string]string{"disk": "ssd"}).
+		WithGPUScheduling("", map[string]string{"cloud.google.com/gke-accelerator": "nvidia-tesla-t4"}).
+		WithGPUScheduling("", nil).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpExists},
+		{Key: ResourceNvidiaGPU, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	}, pts.Spec.Tolerations)
+	assert.Equal(t, map[string]string{"disk": "ssd", "cloud.google.com/gke-accelerator": "nvidia-tesla-t4"}, pts.Spec.NodeSelector)
+}
@@ -0,0 +1,56 @@
+package k8sbuilder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// hashSuffixLength is the length of hash suffix added on generated names
+	hashSuffixLength = 10
+
+	// maxNameLength is the max length of object name (DNS subdomain)
+	maxNameLength = 253
+)
+
+// Checksum permit to compute the sha256 checksum of inputs
+// Inputs are serialized together as JSON array, so map keys order not change the checksum
+// and the inputs boundaries are kept (Checksum(1, 23) is not Checksum(12, 3)).
+func Checksum(inputs ...any) (checksum string, err error) {
+	if inputs == nil {
+		inputs = []any{}
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", errors.Wrap(err, "Error when marshal input")
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// GenerateNameWithHash permit to generate deterministic name with hashed suffix, like configmap-abc123
+// The suffix change when inputs change, so immutable resources are recreated with new name.
+// The base is truncated to keep a valid name, and the trailing '-' and '.' are removed.
+// It fail if base is empty, to not generate name that start with '-'.
+func GenerateNameWithHash(base string, inputs ...any) (name string, err error) {
+	if strings.TrimRight(base, "-.") == "" {
+		return "", errors.Errorf("Base of name can't be empty, got '%s'", base)
+	}
+
+	checksum, err := Checksum(inputs...)
+	if err != nil {
+		return "", err
+	}
+
+	if len(base)+1+hashSuffixLength > maxNameLength {
+		base = base[:maxNameLength-1-hashSuffixLength]
+	}
+	base = strings.TrimRight(base, "-.")
+
+	return base + "-" + checksum[:hashSuffixLength], nil
+}
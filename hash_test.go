@@ -0,0 +1,108 @@
+package k8sbuilder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestChecksum(t *testing.T) {
+	// Deterministic
+	checksum, err := Checksum(map[string]string{"a": "1", "b": "2"}, "test")
+	assert.NoError(t, err)
+	expected, err := Checksum(map[string]string{"b": "2", "a": "1"}, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, checksum)
+	assert.Len(t, checksum, 64)
+
+	// Inputs boundaries are kept
+	checksum, err = Checksum(1, 23)
+	assert.NoError(t, err)
+	other, err := Checksum(12, 3)
+	assert.NoError(t, err)
+	assert.NotEqual(t, other, checksum)
+
+	checksum, err = Checksum("a", "bc")
+	assert.NoError(t, err)
+	other, err = Checksum("ab", "c")
+	assert.NoError(t, err)
+	assert.NotEqual(t, other, checksum)
+
+	// Inputs order change the checksum
+	other, err = Checksum("bc", "a")
+	assert.NoError(t, err)
+	assert.NotEqual(t, other, checksum)
+
+	// Without inputs
+	checksum, err = Checksum()
+	assert.NoError(t, err)
+	assert.Len(t, checksum, 64)
+
+	// Not serializable input
+	_, err = Checksum(make(chan int))
+	assert.ErrorContains(t, err, "Error when marshal input")
+}
+
+func TestGenerateNameWithHash(t *testing.T) {
+	cm := &corev1.ConfigMap{Data: map[string]string{"key": "value"}}
+
+	name, err := GenerateNameWithHash("config", cm)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(name, "config-"))
+	assert.Len(t, name, len("config-")+hashSuffixLength)
+
+	// Same inputs, same name
+	other, err := GenerateNameWithHash("config", cm.DeepCopy())
+	assert.NoError(t, err)
+	assert.Equal(t, name, other)
+
+	// Inputs change, name change
+	other, err = GenerateNameWithHash("config", &corev1.ConfigMap{Data: map[string]string{"key": "other"}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, name, other)
+
+	// Long base is truncated
+	name, err = GenerateNameWithHash(strings.Repeat("a", 300), cm)
+	assert.NoError(t, err)
+	assert.Len(t, name, maxNameLength)
+
+	// Truncated base not end with '-' or '.'
+	base := strings.Repeat("a", maxNameLength-1-hashSuffixLength-2) + ".-b"
+	name, err = GenerateNameWithHash(base, cm)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(name, strings.Repeat("a", maxNameLength-1-hashSuffixLength-2)+"-"))
+	assert.NotContains(t, name, ".")
+	assert.NotContains(t, name, "--")
+
+	// Not serializable input
+	_, err = GenerateNameWithHash("config", make(chan int))
+	assert.Error(t, err)
+
+	// When base is empty
+	_, err = GenerateNameWithHash("", cm)
+	assert.Error(t, err)
+	_, err = GenerateNameWithHash("-.", cm)
+	assert.Error(t, err)
+}
+
+func TestWithChecksumAnnotation(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithChecksumAnnotation("checksum/config", "data").
+		Build()
+	assert.NoError(t, err)
+	expected, err := Checksum("data")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pts.Annotations["checksum/config"])
+
+	// Not serializable input is returned by Build
+	assert.NotPanics(t, func() {
+		_, err = NewPodTemplateBuilder().
+			WithDefaults(&Defaults{}).
+			WithChecksumAnnotation("checksum/config", make(chan int)).
+			Build()
+	})
+	assert.ErrorContains(t, err, "Error when compute checksum annotation checksum/config")
+}
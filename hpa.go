@@ -0,0 +1,295 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HorizontalPodAutoscalerBuilder is the HPA builder interface
+type HorizontalPodAutoscalerBuilder interface {
+	WithName(name string, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithNamespace(namespace string, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithoutLabels(keys ...string) HorizontalPodAutoscalerBuilder
+	WithFinalizer(name string) HorizontalPodAutoscalerBuilder
+	WithoutFinalizer(name string) HorizontalPodAutoscalerBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) HorizontalPodAutoscalerBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithoutAnnotations(keys ...string) HorizontalPodAutoscalerBuilder
+	WithScaleTarget(target client.Object) HorizontalPodAutoscalerBuilder
+	WithMinReplicas(nb int32, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithMaxReplicas(nb int32, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithMetrics(metrics []autoscalingv2.MetricSpec, opts ...WithOption) HorizontalPodAutoscalerBuilder
+	WithCPUTarget(utilization int32) HorizontalPodAutoscalerBuilder
+	WithMemoryTarget(utilization int32) HorizontalPodAutoscalerBuilder
+	WithDefaults(defaults *Defaults) HorizontalPodAutoscalerBuilder
+	HorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler
+	Build() (hpa *autoscalingv2.HorizontalPodAutoscaler, err error)
+}
+
+// HorizontalPodAutoscalerBuilderDefault is the default implementation of HPA builder
+type HorizontalPodAutoscalerBuilderDefault struct {
+	objectMeta
+	hpa      *autoscalingv2.HorizontalPodAutoscaler
+	defaults *Defaults
+	err      error
+}
+
+// NewHorizontalPodAutoscalerBuilder permit to init HPA builder
+func NewHorizontalPodAutoscalerBuilder() HorizontalPodAutoscalerBuilder {
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+
+	return &HorizontalPodAutoscalerBuilderDefault{
+		objectMeta: newObjectMeta(hpa),
+		hpa:        hpa,
+	}
+}
+
+// HorizontalPodAutoscaler permit to get current HPA
+func (h *HorizontalPodAutoscalerBuilderDefault) HorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	return h.hpa
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *HorizontalPodAutoscalerBuilderDefault) WithDefaults(defaults *Defaults) HorizontalPodAutoscalerBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the HPA
+// It fail if the scale target can't be read or if max replicas is not set
+func (h *HorizontalPodAutoscalerBuilderDefault) Build() (hpa *autoscalingv2.HorizontalPodAutoscaler, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	if h.hpa.Spec.ScaleTargetRef.Name == "" {
+		return nil, errors.New("Scale target must be set")
+	}
+	if h.hpa.Spec.MaxReplicas <= 0 {
+		return nil, errors.New("Max replicas must be greater than 0")
+	}
+	if h.hpa.Spec.MinReplicas != nil && *h.hpa.Spec.MinReplicas > h.hpa.Spec.MaxReplicas {
+		return nil, errors.Errorf("Min replicas (%d) must be lower than max replicas (%d)", *h.hpa.Spec.MinReplicas, h.hpa.Spec.MaxReplicas)
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.hpa)
+
+	if errs := ValidateMetadata(h.hpa); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.hpa, nil
+}
+
+// WithScaleTarget permit to fill scaleTargetRef from the built workload, like Deployment or StatefulSet
+// The name and namespace of HPA are also set from target if they are empty.
+func (h *HorizontalPodAutoscalerBuilderDefault) WithScaleTarget(target client.Object) HorizontalPodAutoscalerBuilder {
+	gvk, err := scaleTargetGroupVersionKind(target)
+	if err != nil {
+		h.err = errors.Wrap(err, "Error when set scale target")
+		return h
+	}
+
+	h.hpa.Spec.ScaleTargetRef = autoscalingv2.CrossVersionObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       target.GetName(),
+	}
+
+	return h.WithName(target.GetName(), OverwriteIfDefaultValue).
+		WithNamespace(target.GetNamespace(), OverwriteIfDefaultValue)
+}
+
+func scaleTargetGroupVersionKind(target client.Object) (gvk schema.GroupVersionKind, err error) {
+	if target == nil {
+		return gvk, errors.New("Scale target can't be nil")
+	}
+
+	// Built objects not have type meta most of time
+	switch target.(type) {
+	case *appsv1.Deployment:
+		return appsv1.SchemeGroupVersion.WithKind("Deployment"), nil
+	case *appsv1.StatefulSet:
+		return appsv1.SchemeGroupVersion.WithKind("StatefulSet"), nil
+	case *appsv1.ReplicaSet:
+		return appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), nil
+	}
+
+	gvk = target.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		return gvk, errors.Errorf("ApiVersion and kind must be set on scale target of type %T", target)
+	}
+
+	return gvk, nil
+}
+
+// WithName permit to set name
+func (h *HorizontalPodAutoscalerBuilderDefault) WithName(name string, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *HorizontalPodAutoscalerBuilderDefault) WithNamespace(namespace string, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *HorizontalPodAutoscalerBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *HorizontalPodAutoscalerBuilderDefault) WithoutLabels(keys ...string) HorizontalPodAutoscalerBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *HorizontalPodAutoscalerBuilderDefault) WithFinalizer(name string) HorizontalPodAutoscalerBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *HorizontalPodAutoscalerBuilderDefault) WithoutFinalizer(name string) HorizontalPodAutoscalerBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *HorizontalPodAutoscalerBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) HorizontalPodAutoscalerBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *HorizontalPodAutoscalerBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *HorizontalPodAutoscalerBuilderDefault) WithoutAnnotations(keys ...string) HorizontalPodAutoscalerBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithMinReplicas permit to set min replicas
+func (h *HorizontalPodAutoscalerBuilderDefault) WithMinReplicas(nb int32, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.hpa.Spec.MinReplicas == nil {
+		h.hpa.Spec.MinReplicas = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithMaxReplicas permit to set max replicas
+func (h *HorizontalPodAutoscalerBuilderDefault) WithMaxReplicas(nb int32, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.hpa.Spec.MaxReplicas == 0 {
+		h.hpa.Spec.MaxReplicas = nb
+	}
+
+	return h
+}
+
+// WithMetrics permit to set metrics
+// On merge, resource metrics are merged by resource name and others metrics by type
+func (h *HorizontalPodAutoscalerBuilderDefault) WithMetrics(metrics []autoscalingv2.MetricSpec, opts ...WithOption) HorizontalPodAutoscalerBuilder {
+
+	var tmpMetrics []autoscalingv2.MetricSpec
+
+	// To avoid to overwrite metrics
+	if metrics != nil {
+		tmpMetrics = make([]autoscalingv2.MetricSpec, 0, len(metrics))
+		for _, metric := range metrics {
+			tmpMetrics = append(tmpMetrics, *metric.DeepCopy())
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.hpa.Spec.Metrics == nil {
+		h.hpa.Spec.Metrics = tmpMetrics
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.hpa.Spec.Metrics) == 0 {
+		h.hpa.Spec.Metrics = tmpMetrics
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for _, metric := range tmpMetrics {
+			index := funk.IndexOf(h.hpa.Spec.Metrics, func(o autoscalingv2.MetricSpec) bool {
+				if metric.Type != o.Type {
+					return false
+				}
+				if metric.Resource != nil && o.Resource != nil {
+					return metric.Resource.Name == o.Resource.Name
+				}
+				return true
+			})
+			if index == -1 {
+				h.hpa.Spec.Metrics = append(h.hpa.Spec.Metrics, metric)
+			} else {
+				h.hpa.Spec.Metrics[index] = metric
+			}
+		}
+	}
+
+	return h
+}
+
+// WithCPUTarget permit to merge the CPU average utilization target, in percent of requests
+func (h *HorizontalPodAutoscalerBuilderDefault) WithCPUTarget(utilization int32) HorizontalPodAutoscalerBuilder {
+	return h.WithMetrics([]autoscalingv2.MetricSpec{resourceUtilizationMetric(corev1.ResourceCPU, utilization)}, Merge)
+}
+
+// WithMemoryTarget permit to merge the memory average utilization target, in percent of requests
+func (h *HorizontalPodAutoscalerBuilderDefault) WithMemoryTarget(utilization int32) HorizontalPodAutoscalerBuilder {
+	return h.WithMetrics([]autoscalingv2.MetricSpec{resourceUtilizationMetric(corev1.ResourceMemory, utilization)}, Merge)
+}
+
+func resourceUtilizationMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: pointer.Int32(utilization),
+			},
+		},
+	}
+}
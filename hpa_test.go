@@ -0,0 +1,50 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHorizontalPodAutoscalerBuilder(t *testing.T) {
+	d, err := NewDeploymentBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		}).
+		Build()
+	assert.NoError(t, err)
+
+	hpa, err := NewHorizontalPodAutoscalerBuilder().
+		WithDefaults(&Defaults{}).
+		WithScaleTarget(d).
+		WithMaxReplicas(5).
+		WithCPUTarget(50).
+		WithMemoryTarget(80).
+		WithCPUTarget(75).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", hpa.Name)
+	assert.Equal(t, "default", hpa.Namespace)
+	assert.Equal(t, autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "test"}, hpa.Spec.ScaleTargetRef)
+	assert.Len(t, hpa.Spec.Metrics, 2)
+	assert.Equal(t, corev1.ResourceCPU, hpa.Spec.Metrics[0].Resource.Name)
+	assert.Equal(t, int32(75), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	assert.Equal(t, corev1.ResourceMemory, hpa.Spec.Metrics[1].Resource.Name)
+
+	// When target without type meta
+	_, err = NewHorizontalPodAutoscalerBuilder().
+		WithScaleTarget(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test"}}).
+		WithMaxReplicas(5).
+		Build()
+	assert.Error(t, err)
+
+	// When no max replicas
+	_, err = NewHorizontalPodAutoscalerBuilder().WithScaleTarget(d).Build()
+	assert.Error(t, err)
+}
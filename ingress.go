@@ -1,34 +1,83 @@
 package k8sbuilder
 
 import (
+	"context"
 	"reflect"
+	"time"
 
-	"github.com/imdario/mergo"
 	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // IngressBuilder is the ingress builder interface
 type IngressBuilder interface{
 	WithIngressSpec(is *networkingv1.IngressSpec, opts ...WithOption) IngressBuilder
+	WithRules(rules []networkingv1.IngressRule, opts ...WithOption) IngressBuilder
+	WithTLS(tls []networkingv1.IngressTLS, opts ...WithOption) IngressBuilder
+	WithTLSHost(host string, secretName string) IngressBuilder
+	WithIngressClassName(className string, opts ...WithOption) IngressBuilder
+	Host(host string) IngressRuleBuilder
 	WithLabels(labels map[string]string, opts ...WithOption) IngressBuilder
+	WithoutLabels(keys ...string) IngressBuilder
 	WithAnnotations(annotations map[string]string, opts ...WithOption) IngressBuilder
+	WithoutAnnotations(keys ...string) IngressBuilder
+	WithRecommendedLabels(name, instance, version, component, partOf, managedBy string) IngressBuilder
 	WithName(name string, opts ...WithOption) IngressBuilder
 	WithNamespace(namespace string, opts ...WithOption) IngressBuilder
+	WithGeneratedNameSuffix(base string, inputs ...any) IngressBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) IngressBuilder
+	WithOwnerReferences(ownerReferences []metav1.OwnerReference, opts ...WithOption) IngressBuilder
+	WithFinalizers(finalizers []string, opts ...WithOption) IngressBuilder
+	WithFinalizer(name string) IngressBuilder
+	WithoutFinalizer(name string) IngressBuilder
 	Build() (i *networkingv1.Ingress, err error)
+	SSAPatch(fieldManager string) (patch []byte, opts []client.PatchOption, err error)
+	ToYAML() (data []byte, err error)
+	PatchAgainst(live client.Object) (patch []byte, patchType types.PatchType, err error)
+	JSONPatchAgainst(live client.Object) (patch []byte, patchType types.PatchType, err error)
+	ToKustomizePatch(base client.Object) (patch []byte, err error)
+	WithValidator(validator Validator) IngressBuilder
+	WithDefaults(defaults *Defaults) IngressBuilder
+	WithExportOptions(opts ...ExportOption) IngressBuilder
+	Reconcile(ctx context.Context, c client.Client) (res controllerutil.OperationResult, err error)
+	DryRunAgainstCluster(ctx context.Context, c client.Client) (i *networkingv1.Ingress, err error)
+	DiffAgainstCluster(ctx context.Context, c client.Client, key client.ObjectKey) (diff string, err error)
+	ToGatewayAPI(gatewayClassName string) (gateway *unstructured.Unstructured, routes []*unstructured.Unstructured, err error)
+	RecordOperations() (data []byte, err error)
+	ReplayOperations(data []byte) IngressBuilder
+	Undo(n int) IngressBuilder
 }
 
 // IngressBuilderDefault is the default implementation for ingress builder
 type IngressBuilderDefault struct {
 	i *networkingv1.Ingress
-	operations []Operation
+	operations []ingressOperation
+	history []Operation
+	validator Validator
+	defaults *Defaults
+	exportOptions []ExportOption
+}
+
+// ingressOperation is a pending operation of ingress builder
+// The operation name and arguments are only used to give context on Build errors
+type ingressOperation struct {
+	Operation
+	apply func(h *IngressBuilderDefault) error
 }
 
 // NewIngressBuilder permit to get the default ingress builder
 func NewIngressBuilder() IngressBuilder {
 	return &IngressBuilderDefault{
 		i: &networkingv1.Ingress{},
-		operations: make([]Operation, 0),
+		operations: make([]ingressOperation, 0),
 	}
 }
 
@@ -36,92 +85,453 @@ func NewIngressBuilder() IngressBuilder {
 // It will execute all pending operation in the same order
 // At the end, it will clean all pending operations
 func (h *IngressBuilderDefault) Build() (i *networkingv1.Ingress, err error) {
+	defer observeBuild("Ingress", time.Now())
+
+	// Operations are keeped as pending on error, so they are recorded only once
+	historyLength := len(h.history)
+	for index, o := range h.operations {
+		h.history = append(h.history, o.Operation)
+		if err = o.apply(h); err != nil {
+			h.history = h.history[:historyLength]
+			return nil, errors.Wrapf(err, "Error on operation %d %s", index, o.String())
+		}
+	}
 
-	rv := reflect.ValueOf(h)
+	h.operations = make([]ingressOperation, 0)
 
-	for _, o :=  range h.operations {
-		if o.Name != "" {
-			m := rv.MethodByName(o.Name)
-			if m.IsZero() {
-				return nil, errors.Errorf("Method %s not found", o.Name)
-			}
-			args := make([]reflect.Value, 0, len(o.Args))
-			for _, argv := range o.Args {
-				args = append(args, reflect.ValueOf(argv))
-			} 
-			res := m.Call(args)
-
-			for _, r := range res {
-				if _, ok := r.Interface().(*error); ok {
-					if !r.IsNil() {
-						return nil, r.Interface().(error)
-					}
-				}
-			}
-		}
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
 	}
+	defaults.ApplyToObject(h.i)
 
-	h.operations = make([]Operation, 0)
+	if errs := ValidateMetadata(h.i); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	if errs := ValidateIngress(h.i); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Ingress is invalid")
+	}
+
+	if h.validator != nil {
+		i = h.i.DeepCopy()
+		i.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+		if err = h.validator.Validate(i); err != nil {
+			return nil, errors.Wrap(err, "Error when validate ingress")
+		}
+	}
 
 	return h.i, nil
 }
 
+// Undo permit to remove the n last pending operations, like an overlay that should finally not be applied
+// Operations already applied by Build can't be undone. If n is greater than the number of pending operations, all of them are removed.
+func (h *IngressBuilderDefault) Undo(n int) IngressBuilder {
+	if n <= 0 {
+		return h
+	}
+	if n > len(h.operations) {
+		n = len(h.operations)
+	}
+
+	h.operations = h.operations[:len(h.operations)-n]
+
+	return h
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *IngressBuilderDefault) WithDefaults(defaults *Defaults) IngressBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// WithValidator permit to validate the ingress at the end of Build
+// For exemple, you can use OpenAPIValidator to validate it against cluster schema
+func (h *IngressBuilderDefault) WithValidator(validator Validator) IngressBuilder {
+	h.validator = validator
+
+	return h
+}
+
+// WithExportOptions permit to choose what is exported by SSAPatch, ToYAML and DiffAgainstCluster
+// For exemple, use ExcludeStatus to never export the load balancer status.
+func (h *IngressBuilderDefault) WithExportOptions(opts ...ExportOption) IngressBuilder {
+	h.exportOptions = opts
+
+	return h
+}
+
+// SSAPatch permit to build the ingress and get the server side apply patch with the patch options
+func (h *IngressBuilderDefault) SSAPatch(fieldManager string) (patch []byte, opts []client.PatchOption, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i = i.DeepCopy()
+	i.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	return SSAPatch(i, fieldManager, h.exportOptions...)
+}
+
+// ToYAML permit to build the ingress and render it as YAML
+func (h *IngressBuilderDefault) ToYAML() (data []byte, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	i = i.DeepCopy()
+	i.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	return ToYAML(i, h.exportOptions...)
+}
+
+// PatchAgainst permit to build the ingress and get the strategic merge patch to apply on the live ingress
+func (h *IngressBuilderDefault) PatchAgainst(live client.Object) (patch []byte, patchType types.PatchType, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return PatchAgainst(i, live)
+}
+
+// JSONPatchAgainst permit to build the ingress and get the JSON patch to apply on the live ingress
+func (h *IngressBuilderDefault) JSONPatchAgainst(live client.Object) (patch []byte, patchType types.PatchType, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return JSONPatchAgainst(i, live)
+}
+
+// ToKustomizePatch permit to build the ingress and get the strategic merge patch YAML relative to base ingress
+// See ToKustomizePatch.
+func (h *IngressBuilderDefault) ToKustomizePatch(base client.Object) (patch []byte, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return ToKustomizePatch(i, base)
+}
+
+// Reconcile permit to build the ingress and create or update it on cluster
+func (h *IngressBuilderDefault) Reconcile(ctx context.Context, c client.Client) (res controllerutil.OperationResult, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	return Reconcile(ctx, c, i)
+}
+
+// DryRunAgainstCluster permit to build the ingress and submit it to API server with server side dry-run
+// See DryRunAgainstCluster.
+func (h *IngressBuilderDefault) DryRunAgainstCluster(ctx context.Context, c client.Client) (i *networkingv1.Ingress, err error) {
+	i, err = h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := DryRunAgainstCluster(ctx, c, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*networkingv1.Ingress), nil
+}
+
+// DiffAgainstCluster permit to build the ingress and get the semantic diff with the live ingress
+// It return empty string if there are no diff
+func (h *IngressBuilderDefault) DiffAgainstCluster(ctx context.Context, c client.Client, key client.ObjectKey) (diff string, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return "", err
+	}
+
+	live := &networkingv1.Ingress{}
+	if err = c.Get(ctx, key, live); err != nil {
+		return "", errors.Wrapf(err, "Error when get ingress %s", key.String())
+	}
+
+	return Diff(i, live, h.exportOptions...)
+}
+
+// ToGatewayAPI permit to build the ingress and convert it to the equivalent Gateway and HTTPRoutes
+func (h *IngressBuilderDefault) ToGatewayAPI(gatewayClassName string) (gateway *unstructured.Unstructured, routes []*unstructured.Unstructured, err error) {
+	i, err := h.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return IngressToGatewayAPI(i, gatewayClassName)
+}
+
 // WithIngressSpec permit to initialize ingress from ingress Spec
 func (h *IngressBuilderDefault) WithIngressSpec(is *networkingv1.IngressSpec, opts ...WithOption) IngressBuilder {
-	
-	o := Operation{
-		Name: "withIngressSpec",
-		Args: append([]any{is}, opts),
-	}
-	h.operations = append(h.operations, o)
-	
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withIngressSpec",
+			Args: append([]any{is}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withIngressSpec(is, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithRules permit to set rules
+// On merge, rules are merged by host and paths are merged by path and pathType
+func (h *IngressBuilderDefault) WithRules(rules []networkingv1.IngressRule, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withRules",
+			Args: append([]any{rules}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withRules(rules, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithTLS permit to set TLS
+// On merge, TLS are merged by secret name and hosts are unioned
+func (h *IngressBuilderDefault) WithTLS(tls []networkingv1.IngressTLS, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withTLS",
+			Args: append([]any{tls}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withTLS(tls, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithTLSHost permit to merge TLS for one host
+func (h *IngressBuilderDefault) WithTLSHost(host string, secretName string) IngressBuilder {
+	return h.WithTLS([]networkingv1.IngressTLS{
+		{
+			Hosts:      []string{host},
+			SecretName: secretName,
+		},
+	}, Merge)
+}
+
+// WithIngressClassName permit to set ingress class name
+func (h *IngressBuilderDefault) WithIngressClassName(className string, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withIngressClassName",
+			Args: append([]any{className}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withIngressClassName(className, opts...)
+		},
+	})
+
 	return h
 }
 
 // WithLabels permit to set labels
 func (h *IngressBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) IngressBuilder {
-	
-	o := Operation{
-		Name: "withLabels",
-		Args: append([]any{labels}, opts),
-	}
-	h.operations = append(h.operations, o)
-	
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withLabels",
+			Args: append([]any{labels}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withLabels(labels, opts...)
+		},
+	})
+
 	return h
 }
 
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *IngressBuilderDefault) WithoutLabels(keys ...string) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withoutLabels",
+			Args: []any{keys},
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			h.i.Labels = withoutKeys(h.i.Labels, keys)
+			return nil
+		},
+	})
+
+	return h
+}
+
+// WithRecommendedLabels permit to merge the recommended app.kubernetes.io labels
+// Use the same values on pod template builder to keep labels consistent
+func (h *IngressBuilderDefault) WithRecommendedLabels(name, instance, version, component, partOf, managedBy string) IngressBuilder {
+	return h.WithLabels(RecommendedLabels(name, instance, version, component, partOf, managedBy), Merge)
+}
+
 // WithAnnotations permit to set annotation
 func (h *IngressBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) IngressBuilder {
-	
-	o := Operation{
-		Name: "withAnnotations",
-		Args: append([]any{annotations}, opts),
-	}
-	h.operations = append(h.operations, o)
-	
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withAnnotations",
+			Args: append([]any{annotations}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withAnnotations(annotations, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *IngressBuilderDefault) WithoutAnnotations(keys ...string) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withoutAnnotations",
+			Args: []any{keys},
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			h.i.Annotations = withoutKeys(h.i.Annotations, keys)
+			return nil
+		},
+	})
+
 	return h
 }
 
 // WithName permit to set name
 func (h *IngressBuilderDefault) WithName(name string, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withName",
+			Args: append([]any{name}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withName(name, opts...)
+		},
+	})
 
-	o := Operation{
-		Name: "withName",
-		Args: append([]any{name}, opts),
-	}
-	h.operations = append(h.operations, o)
+	return h
+}
+
+// WithGeneratedNameSuffix permit to set name with deterministic hashed suffix computed from inputs
+func (h *IngressBuilderDefault) WithGeneratedNameSuffix(base string, inputs ...any) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withGeneratedNameSuffix",
+			Args: append([]any{base}, inputs...),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withGeneratedNameSuffix(base, inputs...)
+		},
+	})
 
 	return h
 }
 
 // WithNamespace permit to set namespace
 func (h *IngressBuilderDefault) WithNamespace(namespace string, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withNamespace",
+			Args: append([]any{namespace}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withNamespace(namespace, opts...)
+		},
+	})
 
-	o := Operation{
-		Name: "withNamespace",
-		Args: append([]any{namespace}, opts),
-	}
-	h.operations = append(h.operations, o)
+	return h
+}
+
+// WithOwner permit to set owner reference
+// If controller is true, it set the controller reference
+func (h *IngressBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withOwner",
+			Args: []any{owner, scheme, controller},
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withOwner(owner, scheme, controller)
+		},
+	})
+
+	return h
+}
+
+// WithOwnerReferences permit to set owner references
+// On merge, owner references are merged by UID, see MergeOwnerReferences
+func (h *IngressBuilderDefault) WithOwnerReferences(ownerReferences []metav1.OwnerReference, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withOwnerReferences",
+			Args: append([]any{ownerReferences}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withOwnerReferences(ownerReferences, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithFinalizers permit to set finalizers
+// On merge, finalizers are merged by name
+func (h *IngressBuilderDefault) WithFinalizers(finalizers []string, opts ...WithOption) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withFinalizers",
+			Args: append([]any{finalizers}, opts),
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return h.withFinalizers(finalizers, opts...)
+		},
+	})
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *IngressBuilderDefault) WithFinalizer(name string) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withFinalizer",
+			Args: []any{name},
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			h.i.Finalizers = withFinalizer(h.i.Finalizers, name)
+			return nil
+		},
+	})
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *IngressBuilderDefault) WithoutFinalizer(name string) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "withoutFinalizer",
+			Args: []any{name},
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			h.i.Finalizers = withoutFinalizer(h.i.Finalizers, name)
+			return nil
+		},
+	})
 
 	return h
 }
@@ -136,6 +546,27 @@ func (h *IngressBuilderDefault) withName(name string, opts ...WithOption) (err e
 	return nil
 }
 
+func (h *IngressBuilderDefault) withGeneratedNameSuffix(base string, inputs ...any) (err error) {
+
+	name, err := GenerateNameWithHash(base, inputs...)
+	if err != nil {
+		return errors.Wrap(err, "Error when generate name")
+	}
+	h.i.Name = name
+
+	return nil
+}
+
+func (h *IngressBuilderDefault) withIngressClassName(className string, opts ...WithOption) (err error) {
+
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.i.Spec.IngressClassName == nil || *h.i.Spec.IngressClassName == "" {
+		h.i.Spec.IngressClassName = pointer.String(className)
+	}
+
+	return nil
+}
+
 func (h *IngressBuilderDefault) withNamespace(namespace string, opts ...WithOption) (err error) {
 	
 	// Overwrite
@@ -146,51 +577,92 @@ func (h *IngressBuilderDefault) withNamespace(namespace string, opts ...WithOpti
 	return nil
 }
 
-func (h *IngressBuilderDefault) withLabels(labels map[string]string, opts ...WithOption) (err error) {
-	
+func (h *IngressBuilderDefault) withOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) (err error) {
+
+	if err = SetOwner(h.i, owner, scheme, controller); err != nil {
+		return errors.Wrap(err, "Error when set owner")
+	}
+
+	return nil
+}
+
+func (h *IngressBuilderDefault) withOwnerReferences(ownerReferences []metav1.OwnerReference, opts ...WithOption) (err error) {
+
+	var tmpOwnerReferences []metav1.OwnerReference
+
+	// Copy to avoid overwrite owner references
+	if ownerReferences != nil {
+		tmpOwnerReferences = make([]metav1.OwnerReference, 0, len(ownerReferences))
+		for _, ownerReference := range ownerReferences {
+			tmpOwnerReferences = append(tmpOwnerReferences, *ownerReference.DeepCopy())
+		}
+	}
+
 	// Overwrite
-	if IsOverwrite(opts) || h.i.Labels == nil {
-		h.i.Labels = labels
+	if IsOverwrite(opts) || h.i.OwnerReferences == nil {
+		h.i.OwnerReferences = tmpOwnerReferences
 		return nil
 	}
 
 	// Overwrite only if not default
-	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.i.Labels).Elem().IsZero() {
-		h.i.Labels = labels
+	if IsOverwriteIfDefaultValue(opts) && len(h.i.OwnerReferences) == 0 {
+		h.i.OwnerReferences = tmpOwnerReferences
 		return nil
 	}
 
 	// Merge
-	if IsMerge(opts) && labels != nil {
-		if err := mergo.Merge(h.i.Labels, labels); err != nil {
-			return errors.Wrap(err, "Error when merge labels")
+	if IsMerge(opts) {
+		if h.i.OwnerReferences, err = MergeOwnerReferences(h.i.OwnerReferences, tmpOwnerReferences...); err != nil {
+			return err
 		}
 	}
-	
+
 	return nil
 }
 
-func (h *IngressBuilderDefault) withAnnotations(annotations map[string]string, opts ...WithOption) (err error) {
-	
+func (h *IngressBuilderDefault) withFinalizers(finalizers []string, opts ...WithOption) (err error) {
+
+	var tmpFinalizers []string
+
+	// Copy to avoid overwrite finalizers
+	if finalizers != nil {
+		tmpFinalizers = make([]string, len(finalizers))
+		copy(tmpFinalizers, finalizers)
+	}
+
 	// Overwrite
-	if IsOverwrite(opts) || h.i.Annotations == nil {
-		h.i.Annotations = annotations
+	if IsOverwrite(opts) || h.i.Finalizers == nil {
+		h.i.Finalizers = tmpFinalizers
 		return nil
 	}
 
 	// Overwrite only if not default
-	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.i.Labels).Elem().IsZero() {
-		h.i.Annotations = annotations
+	if IsOverwriteIfDefaultValue(opts) && len(h.i.Finalizers) == 0 {
+		h.i.Finalizers = tmpFinalizers
 		return nil
 	}
 
 	// Merge
-	if IsMerge(opts) && annotations != nil {
-		if err := mergo.Merge(h.i.Annotations, annotations); err != nil {
-			return errors.Wrap(err, "Error when merge annotations")
+	if IsMerge(opts) {
+		for _, finalizer := range tmpFinalizers {
+			if !funk.ContainsString(h.i.Finalizers, finalizer) {
+				h.i.Finalizers = append(h.i.Finalizers, finalizer)
+			}
 		}
 	}
-	
+
+	return nil
+}
+
+func (h *IngressBuilderDefault) withLabels(labels map[string]string, opts ...WithOption) (err error) {
+	h.i.Labels = withMap(h.i.Labels, labels, opts)
+
+	return nil
+}
+
+func (h *IngressBuilderDefault) withAnnotations(annotations map[string]string, opts ...WithOption) (err error) {
+	h.i.Annotations = withMap(h.i.Annotations, annotations, opts)
+
 	return nil
 }
 
@@ -207,7 +679,7 @@ func (h *IngressBuilderDefault) withIngressSpec(is *networkingv1.IngressSpec, op
 	}
 
 	// Overwrite only if not default
-	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.i.Spec).Elem().IsZero() {
+	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.i.Spec).IsZero() {
 		 h.i.Spec = *is
 			return nil
 		}
@@ -223,4 +695,108 @@ func (h *IngressBuilderDefault) withIngressSpec(is *networkingv1.IngressSpec, op
 	
 	
 	return nil
-}
\ No newline at end of file
+}
+func (h *IngressBuilderDefault) withRules(rules []networkingv1.IngressRule, opts ...WithOption) (err error) {
+
+	var tmpRules []networkingv1.IngressRule
+
+	// Copy to avoid overwrite rules
+	if rules != nil {
+		tmpRules = make([]networkingv1.IngressRule, 0, len(rules))
+		for _, rule := range rules {
+			tmpRules = append(tmpRules, *rule.DeepCopy())
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.i.Spec.Rules == nil {
+		h.i.Spec.Rules = tmpRules
+		return nil
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.i.Spec.Rules) == 0 {
+		h.i.Spec.Rules = tmpRules
+		return nil
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for _, rule := range tmpRules {
+			index := funk.IndexOf(h.i.Spec.Rules, func(o networkingv1.IngressRule) bool {
+				return rule.Host == o.Host
+			})
+			if index == -1 {
+				h.i.Spec.Rules = append(h.i.Spec.Rules, rule)
+				continue
+			}
+
+			currentRule := &h.i.Spec.Rules[index]
+			if rule.HTTP == nil {
+				continue
+			}
+			if currentRule.HTTP == nil {
+				currentRule.HTTP = rule.HTTP
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				indexPath := funk.IndexOf(currentRule.HTTP.Paths, func(o networkingv1.HTTPIngressPath) bool {
+					return path.Path == o.Path && reflect.DeepEqual(path.PathType, o.PathType)
+				})
+				if indexPath == -1 {
+					currentRule.HTTP.Paths = append(currentRule.HTTP.Paths, path)
+				} else {
+					currentRule.HTTP.Paths[indexPath] = path
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (h *IngressBuilderDefault) withTLS(tls []networkingv1.IngressTLS, opts ...WithOption) (err error) {
+
+	var tmpTLS []networkingv1.IngressTLS
+
+	// Copy to avoid overwrite tls
+	if tls != nil {
+		tmpTLS = make([]networkingv1.IngressTLS, 0, len(tls))
+		for _, t := range tls {
+			tmpTLS = append(tmpTLS, *t.DeepCopy())
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.i.Spec.TLS == nil {
+		h.i.Spec.TLS = tmpTLS
+		return nil
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.i.Spec.TLS) == 0 {
+		h.i.Spec.TLS = tmpTLS
+		return nil
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for _, t := range tmpTLS {
+			index := funk.IndexOf(h.i.Spec.TLS, func(o networkingv1.IngressTLS) bool {
+				return t.SecretName == o.SecretName
+			})
+			if index == -1 {
+				h.i.Spec.TLS = append(h.i.Spec.TLS, t)
+				continue
+			}
+
+			for _, host := range t.Hosts {
+				if !funk.ContainsString(h.i.Spec.TLS[index].Hosts, host) {
+					h.i.Spec.TLS[index].Hosts = append(h.i.Spec.TLS[index].Hosts, host)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// recordedOperation is an operation read from JSON, with arguments not yet decoded
+type recordedOperation struct {
+	Name string            `json:"name"`
+	Args []json.RawMessage `json:"args"`
+}
+
+// ingressReplayers permit to replay the recorded operations, by operation name
+// withOwner is not replayable, because of the scheme can't be serialized.
+var ingressReplayers = map[string]func(h *IngressBuilderDefault, args []json.RawMessage) error{
+	"withIngressSpec": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var is *networkingv1.IngressSpec
+		var opts []WithOption
+		if err := decodeArgs(args, &is, &opts); err != nil {
+			return err
+		}
+		h.WithIngressSpec(is, opts...)
+		return nil
+	},
+	"withRules": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var rules []networkingv1.IngressRule
+		var opts []WithOption
+		if err := decodeArgs(args, &rules, &opts); err != nil {
+			return err
+		}
+		h.WithRules(rules, opts...)
+		return nil
+	},
+	"withTLS": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var tls []networkingv1.IngressTLS
+		var opts []WithOption
+		if err := decodeArgs(args, &tls, &opts); err != nil {
+			return err
+		}
+		h.WithTLS(tls, opts...)
+		return nil
+	},
+	"withIngressClassName": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var className string
+		var opts []WithOption
+		if err := decodeArgs(args, &className, &opts); err != nil {
+			return err
+		}
+		h.WithIngressClassName(className, opts...)
+		return nil
+	},
+	"withLabels": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var labels map[string]string
+		var opts []WithOption
+		if err := decodeArgs(args, &labels, &opts); err != nil {
+			return err
+		}
+		h.WithLabels(labels, opts...)
+		return nil
+	},
+	"withoutLabels": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var keys []string
+		if err := decodeArgs(args, &keys); err != nil {
+			return err
+		}
+		h.WithoutLabels(keys...)
+		return nil
+	},
+	"withAnnotations": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var annotations map[string]string
+		var opts []WithOption
+		if err := decodeArgs(args, &annotations, &opts); err != nil {
+			return err
+		}
+		h.WithAnnotations(annotations, opts...)
+		return nil
+	},
+	"withoutAnnotations": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var keys []string
+		if err := decodeArgs(args, &keys); err != nil {
+			return err
+		}
+		h.WithoutAnnotations(keys...)
+		return nil
+	},
+	"withName": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var name string
+		var opts []WithOption
+		if err := decodeArgs(args, &name, &opts); err != nil {
+			return err
+		}
+		h.WithName(name, opts...)
+		return nil
+	},
+	"withNamespace": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var namespace string
+		var opts []WithOption
+		if err := decodeArgs(args, &namespace, &opts); err != nil {
+			return err
+		}
+		h.WithNamespace(namespace, opts...)
+		return nil
+	},
+	"withGeneratedNameSuffix": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		if len(args) == 0 {
+			return errors.New("Base name is missing")
+		}
+		var base string
+		if err := json.Unmarshal(args[0], &base); err != nil {
+			return errors.Wrap(err, "Error when decode argument 0")
+		}
+		// Inputs are hashed as JSON, so the recorded JSON is replayed as is to get the same suffix
+		// Decode them would change the keys order of structs and the numbers type.
+		inputs := make([]any, len(args)-1)
+		for index := range inputs {
+			inputs[index] = args[index+1]
+		}
+		h.WithGeneratedNameSuffix(base, inputs...)
+		return nil
+	},
+	"withOwnerReferences": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var ownerReferences []metav1.OwnerReference
+		var opts []WithOption
+		if err := decodeArgs(args, &ownerReferences, &opts); err != nil {
+			return err
+		}
+		h.WithOwnerReferences(ownerReferences, opts...)
+		return nil
+	},
+	"withFinalizers": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var finalizers []string
+		var opts []WithOption
+		if err := decodeArgs(args, &finalizers, &opts); err != nil {
+			return err
+		}
+		h.WithFinalizers(finalizers, opts...)
+		return nil
+	},
+	"withFinalizer": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var name string
+		if err := decodeArgs(args, &name); err != nil {
+			return err
+		}
+		h.WithFinalizer(name)
+		return nil
+	},
+	"withoutFinalizer": func(h *IngressBuilderDefault, args []json.RawMessage) error {
+		var name string
+		if err := decodeArgs(args, &name); err != nil {
+			return err
+		}
+		h.WithoutFinalizer(name)
+		return nil
+	},
+}
+
+// RecordOperations permit to serialize as JSON all operations done on builder, applied or pending, in the same order
+// Use ReplayOperations to replay them, for exemple to reproduce a bug report or on regression tests.
+// It return error if an operation can't be recorded, like WithOwner.
+func (h *IngressBuilderDefault) RecordOperations() (data []byte, err error) {
+	operations := make([]Operation, 0, len(h.history)+len(h.operations))
+	operations = append(operations, h.history...)
+	for _, o := range h.operations {
+		operations = append(operations, o.Operation)
+	}
+
+	for index, o := range operations {
+		if _, ok := ingressReplayers[o.Name]; !ok {
+			return nil, errors.Errorf("Operation %d %s can't be recorded", index, o.Name)
+		}
+	}
+
+	data, err = json.Marshal(operations)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal operations")
+	}
+
+	return data, nil
+}
+
+// ReplayOperations permit to add the operations recorded by RecordOperations on builder
+// Operations are applied on Build like the others. If the record is invalid, the error is returned by Build.
+func (h *IngressBuilderDefault) ReplayOperations(data []byte) IngressBuilder {
+	operations := make([]recordedOperation, 0)
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return h.replayError(errors.Wrap(err, "Error when unmarshal operations"))
+	}
+
+	for index, o := range operations {
+		replayer, ok := ingressReplayers[o.Name]
+		if !ok {
+			return h.replayError(errors.Errorf("Operation %d %s is not supported", index, o.Name))
+		}
+		if err := replayer(h, o.Args); err != nil {
+			return h.replayError(errors.Wrapf(err, "Error when replay operation %d %s", index, o.Name))
+		}
+	}
+
+	return h
+}
+
+// replayError permit to add an operation that return the replay error on Build
+func (h *IngressBuilderDefault) replayError(err error) IngressBuilder {
+	h.operations = append(h.operations, ingressOperation{
+		Operation: Operation{
+			Name: "replayOperations",
+		},
+		apply: func(h *IngressBuilderDefault) error {
+			return err
+		},
+	})
+
+	return h
+}
+
+// decodeArgs permit to decode the recorded arguments on targets, in the same order
+func decodeArgs(args []json.RawMessage, targets ...any) (err error) {
+	if len(args) != len(targets) {
+		return errors.Errorf("Expected %d arguments, got %d", len(targets), len(args))
+	}
+
+	for index, target := range targets {
+		if err = json.Unmarshal(args[index], target); err != nil {
+			return errors.Wrapf(err, "Error when decode argument %d", index)
+		}
+	}
+
+	return nil
+}
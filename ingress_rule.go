@@ -0,0 +1,105 @@
+package k8sbuilder
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// IngressRuleBuilder is the fluent builder of ingress rule for one host
+type IngressRuleBuilder interface {
+	Path(path string, pathType networkingv1.PathType) IngressPathBuilder
+	Host(host string) IngressRuleBuilder
+	Ingress() IngressBuilder
+}
+
+// IngressPathBuilder is the fluent builder of ingress path
+type IngressPathBuilder interface {
+	Service(name string, port int32) IngressRuleBuilder
+	ServicePortName(name string, portName string) IngressRuleBuilder
+}
+
+// IngressRuleBuilderDefault is the default implementation of ingress rule builder
+type IngressRuleBuilderDefault struct {
+	ingress IngressBuilder
+	host    string
+}
+
+// IngressPathBuilderDefault is the default implementation of ingress path builder
+type IngressPathBuilderDefault struct {
+	rule     *IngressRuleBuilderDefault
+	path     string
+	pathType networkingv1.PathType
+}
+
+// Host permit to get fluent rule builder for host
+// Each path is merged on ingress rules, for exemple:
+// ingress.Host("api.example.com").Path("/v1", networkingv1.PathTypePrefix).Service("api", 8080)
+func (h *IngressBuilderDefault) Host(host string) IngressRuleBuilder {
+	return &IngressRuleBuilderDefault{
+		ingress: h,
+		host:    host,
+	}
+}
+
+// Path permit to add path on host
+func (h *IngressRuleBuilderDefault) Path(path string, pathType networkingv1.PathType) IngressPathBuilder {
+	return &IngressPathBuilderDefault{
+		rule:     h,
+		path:     path,
+		pathType: pathType,
+	}
+}
+
+// Host permit to get fluent rule builder for another host
+func (h *IngressRuleBuilderDefault) Host(host string) IngressRuleBuilder {
+	return h.ingress.Host(host)
+}
+
+// Ingress permit to go back on ingress builder
+func (h *IngressRuleBuilderDefault) Ingress() IngressBuilder {
+	return h.ingress
+}
+
+// Service permit to route the path on service port number
+func (h *IngressPathBuilderDefault) Service(name string, port int32) IngressRuleBuilder {
+	return h.backend(&networkingv1.IngressServiceBackend{
+		Name: name,
+		Port: networkingv1.ServiceBackendPort{
+			Number: port,
+		},
+	})
+}
+
+// ServicePortName permit to route the path on service port name
+func (h *IngressPathBuilderDefault) ServicePortName(name string, portName string) IngressRuleBuilder {
+	return h.backend(&networkingv1.IngressServiceBackend{
+		Name: name,
+		Port: networkingv1.ServiceBackendPort{
+			Name: portName,
+		},
+	})
+}
+
+func (h *IngressPathBuilderDefault) backend(service *networkingv1.IngressServiceBackend) IngressRuleBuilder {
+	pathType := h.pathType
+
+	h.rule.ingress.WithRules([]networkingv1.IngressRule{
+		{
+			Host: h.rule.host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     h.path,
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: service,
+							},
+						},
+					},
+				},
+			},
+		},
+	}, Merge)
+
+	return h.rule
+}
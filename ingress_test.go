@@ -0,0 +1,480 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func TestIngressWithRules(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	exact := networkingv1.PathTypeExact
+	backend := func(name string) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: name,
+			},
+		}
+	}
+
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+	defaultRules := []networkingv1.IngressRule{
+		{
+			Host: "api.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &prefix, Backend: backend("api")},
+					},
+				},
+			},
+		},
+	}
+	userRules := []networkingv1.IngressRule{
+		{
+			Host: "api.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &prefix, Backend: backend("api-v2")},
+						{Path: "/", PathType: &exact, Backend: backend("home")},
+					},
+				},
+			},
+		},
+		{
+			Host: "extra.example.com",
+		},
+	}
+
+	assert.NoError(t, b.withRules(defaultRules))
+	assert.NoError(t, b.withRules(userRules, Merge))
+
+	assert.Len(t, b.i.Spec.Rules, 2)
+	assert.Equal(t, []networkingv1.HTTPIngressPath{
+		{Path: "/", PathType: &prefix, Backend: backend("api-v2")},
+		{Path: "/", PathType: &exact, Backend: backend("home")},
+	}, b.i.Spec.Rules[0].HTTP.Paths)
+	assert.Equal(t, "extra.example.com", b.i.Spec.Rules[1].Host)
+
+	// Default rules must not be modified
+	assert.Len(t, defaultRules[0].HTTP.Paths, 1)
+	assert.Equal(t, "api", defaultRules[0].HTTP.Paths[0].Backend.Service.Name)
+}
+
+func TestIngressWithTLS(t *testing.T) {
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+
+	assert.NoError(t, b.withTLS([]networkingv1.IngressTLS{
+		{
+			Hosts:      []string{"api.example.com"},
+			SecretName: "tls",
+		},
+	}))
+	assert.NoError(t, b.withTLS([]networkingv1.IngressTLS{
+		{
+			Hosts:      []string{"api.example.com", "www.example.com"},
+			SecretName: "tls",
+		},
+		{
+			Hosts:      []string{"extra.example.com"},
+			SecretName: "extra-tls",
+		},
+	}, Merge))
+
+	assert.Equal(t, []networkingv1.IngressTLS{
+		{
+			Hosts:      []string{"api.example.com", "www.example.com"},
+			SecretName: "tls",
+		},
+		{
+			Hosts:      []string{"extra.example.com"},
+			SecretName: "extra-tls",
+		},
+	}, b.i.Spec.TLS)
+}
+
+func TestIngressRuleBuilder(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+
+	b.Host("api.example.com").
+		Path("/v1", networkingv1.PathTypePrefix).Service("api", 8080).
+		Path("/v2", networkingv1.PathTypePrefix).ServicePortName("api-v2", "http").
+		Host("www.example.com").
+		Path("/", networkingv1.PathTypePrefix).Service("www", 80)
+
+	for _, o := range b.operations {
+		assert.NoError(t, o.apply(b))
+	}
+
+	assert.Equal(t, []networkingv1.IngressRule{
+		{
+			Host: "api.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/v1",
+							PathType: &prefix,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "api",
+									Port: networkingv1.ServiceBackendPort{Number: 8080},
+								},
+							},
+						},
+						{
+							Path:     "/v2",
+							PathType: &prefix,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "api-v2",
+									Port: networkingv1.ServiceBackendPort{Name: "http"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Host: "www.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     "/",
+							PathType: &prefix,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: "www",
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, b.i.Spec.Rules)
+}
+
+func TestIngressWithOwnerReferences(t *testing.T) {
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+
+	// When empty
+	assert.NoError(t, b.withOwnerReferences([]metav1.OwnerReference{{UID: "1", Name: "owner1"}}, Merge))
+	assert.Equal(t, []metav1.OwnerReference{{UID: "1", Name: "owner1"}}, b.i.OwnerReferences)
+
+	// When merge
+	assert.NoError(t, b.withOwnerReferences([]metav1.OwnerReference{{UID: "1", Name: "owner1-renamed"}, {UID: "2", Name: "owner2"}}, Merge))
+	assert.Equal(t, []metav1.OwnerReference{{UID: "1", Name: "owner1-renamed"}, {UID: "2", Name: "owner2"}}, b.i.OwnerReferences)
+
+	// When overwrite if default value
+	assert.NoError(t, b.withOwnerReferences([]metav1.OwnerReference{{UID: "3", Name: "owner3"}}, OverwriteIfDefaultValue))
+	assert.Equal(t, []metav1.OwnerReference{{UID: "1", Name: "owner1-renamed"}, {UID: "2", Name: "owner2"}}, b.i.OwnerReferences)
+
+	// When overwrite
+	assert.NoError(t, b.withOwnerReferences([]metav1.OwnerReference{{UID: "3", Name: "owner3"}}))
+	assert.Equal(t, []metav1.OwnerReference{{UID: "3", Name: "owner3"}}, b.i.OwnerReferences)
+}
+
+func TestIngressWithFinalizers(t *testing.T) {
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+
+	// When empty
+	assert.NoError(t, b.withFinalizers([]string{"finalizer1"}, Merge))
+	assert.Equal(t, []string{"finalizer1"}, b.i.Finalizers)
+
+	// When merge
+	assert.NoError(t, b.withFinalizers([]string{"finalizer1", "finalizer2"}, Merge))
+	assert.Equal(t, []string{"finalizer1", "finalizer2"}, b.i.Finalizers)
+
+	// When overwrite
+	assert.NoError(t, b.withFinalizers([]string{"finalizer3"}))
+	assert.Equal(t, []string{"finalizer3"}, b.i.Finalizers)
+}
+
+func TestIngressWithIngressClassName(t *testing.T) {
+	b := NewIngressBuilder().(*IngressBuilderDefault)
+
+	// When empty
+	assert.NoError(t, b.withIngressClassName("nginx", OverwriteIfDefaultValue))
+	assert.Equal(t, "nginx", *b.i.Spec.IngressClassName)
+
+	// When already set, it's keeped with overwrite only if default value
+	assert.NoError(t, b.withIngressClassName("traefik", OverwriteIfDefaultValue))
+	assert.Equal(t, "nginx", *b.i.Spec.IngressClassName)
+
+	// When overwrite
+	assert.NoError(t, b.withIngressClassName("traefik", Overwrite))
+	assert.Equal(t, "traefik", *b.i.Spec.IngressClassName)
+	assert.NoError(t, b.withIngressClassName("nginx"))
+	assert.Equal(t, "nginx", *b.i.Spec.IngressClassName)
+
+	// When merge, the scalar value is replaced
+	assert.NoError(t, b.withIngressClassName("traefik", Merge))
+	assert.Equal(t, "traefik", *b.i.Spec.IngressClassName)
+
+	// When empty string, like the default value
+	b.i.Spec.IngressClassName = pointer.String("")
+	assert.NoError(t, b.withIngressClassName("nginx", OverwriteIfDefaultValue))
+	assert.Equal(t, "nginx", *b.i.Spec.IngressClassName)
+}
+
+func TestIngressWithFinalizer(t *testing.T) {
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithFinalizers([]string{"finalizer1"}).
+		WithFinalizer("finalizer2").
+		WithFinalizer("finalizer2").
+		WithoutFinalizer("finalizer1").
+		WithoutFinalizer("finalizer3").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"finalizer2"}, i.Finalizers)
+}
+
+func TestIngressBuild(t *testing.T) {
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithLabels(map[string]string{"app": "test"}).
+		WithLabels(map[string]string{"env": "dev"}, Merge).
+		WithAnnotations(map[string]string{"foo": "bar"}, OverwriteIfDefaultValue).
+		WithIngressClassName("nginx").
+		WithFinalizers([]string{"test"}).
+		Host("api.example.com").Path("/", networkingv1.PathTypePrefix).Service("api", 8080).
+		Ingress().
+		WithTLSHost("api.example.com", "api-tls").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", i.Name)
+	assert.Equal(t, "default", i.Namespace)
+	assert.Equal(t, map[string]string{"app": "test", "env": "dev"}, i.Labels)
+	assert.Equal(t, map[string]string{"foo": "bar"}, i.Annotations)
+	assert.Equal(t, "nginx", *i.Spec.IngressClassName)
+	assert.Equal(t, []string{"test"}, i.Finalizers)
+	assert.Len(t, i.Spec.Rules, 1)
+	assert.Equal(t, []networkingv1.IngressTLS{{Hosts: []string{"api.example.com"}, SecretName: "api-tls"}}, i.Spec.TLS)
+}
+
+func TestIngressWithRecommendedLabels(t *testing.T) {
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithLabels(map[string]string{"app": "test"}).
+		WithRecommendedLabels("elasticsearch", "logs", "8.5.0", "", "elk", "").
+		Build()
+	assert.NoError(t, err)
+
+	// Labels are merged and empty values are skipped
+	assert.Equal(t, map[string]string{
+		"app":                        "test",
+		"app.kubernetes.io/name":     "elasticsearch",
+		"app.kubernetes.io/instance": "logs",
+		"app.kubernetes.io/version":  "8.5.0",
+		"app.kubernetes.io/part-of":  "elk",
+	}, i.Labels)
+}
+
+func TestIngressWithoutMetadata(t *testing.T) {
+	labels := map[string]string{"app": "test", "debug": "true"}
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithLabels(labels).
+		WithAnnotations(map[string]string{"foo": "bar", "debug": "true"}).
+		WithoutLabels("debug").
+		WithoutAnnotations("debug", "missing").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test"}, i.Labels)
+	assert.Equal(t, map[string]string{"foo": "bar"}, i.Annotations)
+
+	// The map given by the caller is not modified
+	assert.Equal(t, map[string]string{"app": "test", "debug": "true"}, labels)
+}
+
+func TestIngressBuildError(t *testing.T) {
+	_, err := NewIngressBuilder().
+		WithGeneratedNameSuffix("test", make(chan int)).
+		Build()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error on operation 0 withGeneratedNameSuffix(test, ")
+	assert.Contains(t, err.Error(), "Error when generate name: Error when marshal input: json: unsupported type: chan int")
+	assert.NotContains(t, err.Error(), "method not found")
+}
+
+func TestValidateIngress(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	unknown := networkingv1.PathType("unknown")
+	i := &networkingv1.Ingress{
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts: []string{"*.example.com"},
+				},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "api.example.com",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/v1",
+									PathType: &prefix,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// When valid
+	assert.Empty(t, ValidateIngress(i))
+
+	// When invalid
+	i.Spec.TLS[0].Hosts = []string{"10.0.0.1"}
+	i.Spec.Rules[0].Host = "API_example.com"
+	i.Spec.Rules[0].HTTP.Paths = []networkingv1.HTTPIngressPath{
+		{
+			Path: "/v1",
+		},
+		{
+			Path:     "v1//test",
+			PathType: &prefix,
+		},
+		{
+			Path:     "/v1",
+			PathType: &unknown,
+		},
+	}
+	errs := ValidateIngress(i)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{
+		"spec.tls[0].hosts[0]",
+		"spec.rules[0].host",
+		"spec.rules[0].http.paths[0].pathType",
+		"spec.rules[0].http.paths[1].path",
+		"spec.rules[0].http.paths[1].path",
+		"spec.rules[0].http.paths[2].pathType",
+	}, fields)
+}
+
+func TestIngressRecordOperations(t *testing.T) {
+	b := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithGeneratedNameSuffix("test", map[string]any{"replicas": 3}).
+		WithNamespace("default").
+		WithLabels(map[string]string{"app": "test"}).
+		Host("api.example.com").Path("/v1", networkingv1.PathTypePrefix).Service("api", 8080).
+		Ingress()
+	if _, err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+	b.WithAnnotations(map[string]string{"team": "search"}, Merge).
+		WithoutLabels("app").
+		WithFinalizer("example.com/cleanup")
+	expected, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Applied and pending operations are recorded
+	data, err := b.RecordOperations()
+	assert.NoError(t, err)
+
+	// Replay give the same ingress
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		ReplayOperations(data).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, i)
+
+	// Struct inputs give the same generated name
+	b = NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithGeneratedNameSuffix("test", corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+			Data:       map[string]string{"replicas": "3"},
+		}, 1.5, 12345678901)
+	expected, err = b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = b.RecordOperations()
+	assert.NoError(t, err)
+	i, err = NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		ReplayOperations(data).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Name, i.Name)
+
+	// When record is invalid, Build return error
+	_, err = NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		ReplayOperations([]byte(`[{"name":"withName","args":[1]}]`)).
+		Build()
+	assert.Error(t, err)
+	_, err = NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		ReplayOperations([]byte(`[{"name":"unknown","args":[]}]`)).
+		Build()
+	assert.ErrorContains(t, err, "unknown")
+
+	// When operation can't be recorded
+	owner := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: "uid"}}
+	_, err = NewIngressBuilder().
+		WithOwner(owner, scheme.Scheme, true).
+		RecordOperations()
+	assert.ErrorContains(t, err, "withOwner")
+}
+
+func TestIngressUndo(t *testing.T) {
+	b := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithLabels(map[string]string{"app": "test"})
+	if _, err := b.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only pending operations are removed
+	i, err := b.
+		WithAnnotations(map[string]string{"team": "search"}).
+		WithLabels(map[string]string{"canary": "true"}, Merge).
+		Undo(1).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test"}, i.Labels)
+	assert.Equal(t, map[string]string{"team": "search"}, i.Annotations)
+
+	// When n is greater than pending operations
+	i, err = b.
+		WithName("other").
+		Undo(10).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", i.Name)
+
+	// Undone operations are not recorded
+	data, err := b.WithNamespace("default").Undo(1).RecordOperations()
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "withNamespace")
+}
@@ -0,0 +1,107 @@
+package k8sbuilder
+
+import (
+	"net"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	invalidPathSequences = []string{"//", "/./", "/../", "%2f", "%2F"}
+	invalidPathSuffixes  = []string{"/..", "/."}
+	supportedPathTypes   = []string{
+		string(networkingv1.PathTypeExact),
+		string(networkingv1.PathTypePrefix),
+		string(networkingv1.PathTypeImplementationSpecific),
+	}
+)
+
+// ValidateIngress permit to check hosts and paths of ingress before send it to API server
+// Hosts must be DNS names (wildcard allowed), pathType must be set and paths must be well formed.
+func ValidateIngress(i *networkingv1.Ingress) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+	if i == nil {
+		return errs
+	}
+
+	specPath := field.NewPath("spec")
+
+	for index, tls := range i.Spec.TLS {
+		for indexHost, host := range tls.Hosts {
+			errs = append(errs, validateIngressHost(host, specPath.Child("tls").Index(index).Child("hosts").Index(indexHost))...)
+		}
+	}
+
+	for index, rule := range i.Spec.Rules {
+		rulePath := specPath.Child("rules").Index(index)
+		if rule.Host != "" {
+			errs = append(errs, validateIngressHost(rule.Host, rulePath.Child("host"))...)
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for indexPath, path := range rule.HTTP.Paths {
+			errs = append(errs, validateIngressPath(&path, rulePath.Child("http", "paths").Index(indexPath))...)
+		}
+	}
+
+	return errs
+}
+
+func validateIngressHost(host string, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	if net.ParseIP(host) != nil {
+		return append(errs, field.Invalid(fldPath, host, "must be a DNS name, not an IP address"))
+	}
+
+	var msgs []string
+	if strings.Contains(host, "*") {
+		msgs = validation.IsWildcardDNS1123Subdomain(host)
+	} else {
+		msgs = validation.IsDNS1123Subdomain(host)
+	}
+	for _, msg := range msgs {
+		errs = append(errs, field.Invalid(fldPath, host, msg))
+	}
+
+	return errs
+}
+
+func validateIngressPath(path *networkingv1.HTTPIngressPath, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	if path.PathType == nil {
+		return append(errs, field.Required(fldPath.Child("pathType"), "pathType must be specified"))
+	}
+
+	switch *path.PathType {
+	case networkingv1.PathTypeExact, networkingv1.PathTypePrefix:
+		if !strings.HasPrefix(path.Path, "/") {
+			errs = append(errs, field.Invalid(fldPath.Child("path"), path.Path, "must be an absolute path"))
+		}
+		if len(path.Path) > 0 {
+			for _, invalidSeq := range invalidPathSequences {
+				if strings.Contains(path.Path, invalidSeq) {
+					errs = append(errs, field.Invalid(fldPath.Child("path"), path.Path, "must not contain '"+invalidSeq+"'"))
+				}
+			}
+			for _, invalidSuff := range invalidPathSuffixes {
+				if strings.HasSuffix(path.Path, invalidSuff) {
+					errs = append(errs, field.Invalid(fldPath.Child("path"), path.Path, "cannot end with '"+invalidSuff+"'"))
+				}
+			}
+		}
+	case networkingv1.PathTypeImplementationSpecific:
+		if len(path.Path) > 0 && !strings.HasPrefix(path.Path, "/") {
+			errs = append(errs, field.Invalid(fldPath.Child("path"), path.Path, "must be an absolute path"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(fldPath.Child("pathType"), *path.PathType, supportedPathTypes))
+	}
+
+	return errs
+}
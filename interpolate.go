@@ -0,0 +1,85 @@
+package k8sbuilder
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Interpolate permit to resolve Go template on all string fields of object, like env values, args or annotations
+// Only strings that contain "{{" are rendered. It fail if template use a key not found on data.
+// The object must be a pointer.
+func Interpolate(o any, data any) (err error) {
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New("Object must be a not nil pointer")
+	}
+
+	return interpolateValue(v, data)
+}
+
+func interpolateValue(v reflect.Value, data any) (err error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Kind() == reflect.Interface {
+			// Values inside interface are not settable, so work on copy
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			if err = interpolateValue(elem, data); err != nil {
+				return err
+			}
+			if v.CanSet() {
+				v.Set(elem)
+			}
+			return nil
+		}
+		return interpolateValue(v.Elem(), data)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			if err = interpolateValue(v.Field(i), data); err != nil {
+				return errors.Wrapf(err, "Error on field %s", v.Type().Field(i).Name)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err = interpolateValue(v.Index(i), data); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values are not addressable, so work on copy
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err = interpolateValue(elem, data); err != nil {
+				return errors.Wrapf(err, "Error on key %v", iter.Key().Interface())
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.String:
+		if !v.CanSet() || !strings.Contains(v.String(), "{{") {
+			return nil
+		}
+		t, err := template.New("").Option("missingkey=error").Parse(v.String())
+		if err != nil {
+			return errors.Wrapf(err, "Error when parse template %s", v.String())
+		}
+		buf := &bytes.Buffer{}
+		if err = t.Execute(buf, data); err != nil {
+			return errors.Wrapf(err, "Error when render template %s", v.String())
+		}
+		v.SetString(buf.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInterpolate(t *testing.T) {
+	data := map[string]string{
+		"ClusterName": "prod",
+	}
+
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithTemplateData(data).
+		WithPodTemplateSpec(&corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"cluster": "{{ .ClusterName }}",
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "test",
+						Args: []string{"--cluster={{ .ClusterName }}", "--static"},
+						Env: []corev1.EnvVar{
+							{
+								Name:  "CLUSTER",
+								Value: "cluster-{{ .ClusterName }}",
+							},
+						},
+					},
+				},
+			},
+		}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", pts.Annotations["cluster"])
+	assert.Equal(t, []string{"--cluster=prod", "--static"}, pts.Spec.Containers[0].Args)
+	assert.Equal(t, "cluster-prod", pts.Spec.Containers[0].Env[0].Value)
+
+	// When key not found
+	c := &corev1.Container{Args: []string{"{{ .Unknown }}"}}
+	assert.Error(t, Interpolate(c, data))
+
+	// When not pointer
+	assert.Error(t, Interpolate(*c, data))
+}
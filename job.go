@@ -0,0 +1,224 @@
+package k8sbuilder
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultJobBackoffLimit is the backoff limit set by batch defaults preset
+	DefaultJobBackoffLimit int32 = 3
+
+	// DefaultJobTTLSecondsAfterFinished is the TTL set by batch defaults preset, to clean finished jobs after one day
+	DefaultJobTTLSecondsAfterFinished int32 = 86400
+)
+
+// JobBuilder is the job builder interface
+type JobBuilder interface {
+	WithName(name string, opts ...WithOption) JobBuilder
+	WithNamespace(namespace string, opts ...WithOption) JobBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) JobBuilder
+	WithoutLabels(keys ...string) JobBuilder
+	WithFinalizer(name string) JobBuilder
+	WithoutFinalizer(name string) JobBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) JobBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) JobBuilder
+	WithoutAnnotations(keys ...string) JobBuilder
+	WithBackoffLimit(nb int32, opts ...WithOption) JobBuilder
+	WithTTLSecondsAfterFinished(nb int32, opts ...WithOption) JobBuilder
+	WithActiveDeadlineSeconds(nb int64, opts ...WithOption) JobBuilder
+	WithBatchDefaults() JobBuilder
+	WithPodTemplate(fn func(ptb PodTemplateBuilder)) JobBuilder
+	WithDefaults(defaults *Defaults) JobBuilder
+	PodTemplate() PodTemplateBuilder
+	Job() *batchv1.Job
+	Build() (job *batchv1.Job, err error)
+}
+
+// JobBuilderDefault is the default implementation of job builder
+type JobBuilderDefault struct {
+	objectMeta
+	job         *batchv1.Job
+	podTemplate PodTemplateBuilder
+	defaults    *Defaults
+	err         error
+}
+
+// NewJobBuilder permit to init job builder
+func NewJobBuilder() JobBuilder {
+	job := &batchv1.Job{}
+
+	return &JobBuilderDefault{
+		objectMeta:  newObjectMeta(job),
+		job:         job,
+		podTemplate: NewPodTemplateBuilder(),
+	}
+}
+
+// PodTemplate permit to get the pod template sub-builder
+// Changes done on it are set on job on Build
+func (h *JobBuilderDefault) PodTemplate() PodTemplateBuilder {
+	return h.podTemplate
+}
+
+// WithPodTemplate permit to change the pod template without break the fluent chain
+func (h *JobBuilderDefault) WithPodTemplate(fn func(ptb PodTemplateBuilder)) JobBuilder {
+	if fn != nil {
+		fn(h.podTemplate)
+	}
+
+	return h
+}
+
+// Job permit to get current job
+func (h *JobBuilderDefault) Job() *batchv1.Job {
+	return h.job
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+// They are also used by the pod template sub-builder
+func (h *JobBuilderDefault) WithDefaults(defaults *Defaults) JobBuilder {
+	h.defaults = defaults
+	h.podTemplate.WithDefaults(defaults)
+
+	return h
+}
+
+// WithBatchDefaults permit to apply sane defaults for batch job
+// It set restartPolicy to Never, backoffLimit and TTL after finished with OverwriteIfDefaultValue, so you can still override them
+func (h *JobBuilderDefault) WithBatchDefaults() JobBuilder {
+	h.podTemplate.WithRestartPolicy(corev1.RestartPolicyNever, OverwriteIfDefaultValue)
+
+	return h.WithBackoffLimit(DefaultJobBackoffLimit, OverwriteIfDefaultValue).
+		WithTTLSecondsAfterFinished(DefaultJobTTLSecondsAfterFinished, OverwriteIfDefaultValue)
+}
+
+// Build permit to get the job with the pod template built by the sub-builder
+// The selector is not set, because of it's generated by API server
+func (h *JobBuilderDefault) Build() (job *batchv1.Job, err error) {
+	defer observeBuild("Job", time.Now())
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	pts, err := h.podTemplate.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when build pod template")
+	}
+	h.job.Spec.Template = *pts.DeepCopy()
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.job)
+
+	if errs := ValidateMetadata(h.job); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.job, nil
+}
+
+// WithName permit to set name
+func (h *JobBuilderDefault) WithName(name string, opts ...WithOption) JobBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *JobBuilderDefault) WithNamespace(namespace string, opts ...WithOption) JobBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *JobBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) JobBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *JobBuilderDefault) WithoutLabels(keys ...string) JobBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *JobBuilderDefault) WithFinalizer(name string) JobBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *JobBuilderDefault) WithoutFinalizer(name string) JobBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *JobBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) JobBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *JobBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) JobBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *JobBuilderDefault) WithoutAnnotations(keys ...string) JobBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithBackoffLimit permit to set backoff limit
+func (h *JobBuilderDefault) WithBackoffLimit(nb int32, opts ...WithOption) JobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.job.Spec.BackoffLimit == nil {
+		h.job.Spec.BackoffLimit = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithTTLSecondsAfterFinished permit to set TTL after job finished
+func (h *JobBuilderDefault) WithTTLSecondsAfterFinished(nb int32, opts ...WithOption) JobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.job.Spec.TTLSecondsAfterFinished == nil {
+		h.job.Spec.TTLSecondsAfterFinished = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithActiveDeadlineSeconds permit to set active deadline
+func (h *JobBuilderDefault) WithActiveDeadlineSeconds(nb int64, opts ...WithOption) JobBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.job.Spec.ActiveDeadlineSeconds == nil {
+		h.job.Spec.ActiveDeadlineSeconds = pointer.Int64(nb)
+	}
+
+	return h
+}
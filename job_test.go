@@ -0,0 +1,47 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestJobBatchDefaults(t *testing.T) {
+	job, err := NewJobBuilder().
+		WithDefaults(&Defaults{}).
+		WithBackoffLimit(10).
+		WithBatchDefaults().
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.RestartPolicyNever, job.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, int32(10), *job.Spec.BackoffLimit)
+	assert.Equal(t, DefaultJobTTLSecondsAfterFinished, *job.Spec.TTLSecondsAfterFinished)
+
+	// Preset can be overridden after
+	b := NewJobBuilder().WithDefaults(&Defaults{}).WithBatchDefaults()
+	b.PodTemplate().WithRestartPolicy(corev1.RestartPolicyOnFailure)
+	job, err = b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.RestartPolicyOnFailure, job.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, DefaultJobBackoffLimit, *job.Spec.BackoffLimit)
+}
+
+func TestCronJobBatchDefaults(t *testing.T) {
+	cj, err := NewCronJobBuilder().
+		WithDefaults(&Defaults{}).
+		WithSchedule("*/5 * * * *").
+		WithConcurrencyPolicy(batchv1.ReplaceConcurrent).
+		WithBatchDefaults().
+		WithJobTemplate(func(jb JobBuilder) {
+			jb.WithLabels(map[string]string{"app": "test"})
+		}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, batchv1.ReplaceConcurrent, cj.Spec.ConcurrencyPolicy)
+	assert.Equal(t, DefaultCronJobSuccessfulJobsHistoryLimit, *cj.Spec.SuccessfulJobsHistoryLimit)
+	assert.Equal(t, DefaultJobBackoffLimit, *cj.Spec.JobTemplate.Spec.BackoffLimit)
+	assert.Equal(t, corev1.RestartPolicyNever, cj.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, map[string]string{"app": "test"}, cj.Spec.JobTemplate.Labels)
+}
@@ -0,0 +1,51 @@
+package k8sbuildest
+
+import (
+	"testing"
+
+	"github.com/disaster37/k8sbuilder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AssertEquivalent permit to check that got object is equivalent to want object
+// Fields only set on got object (server defaults, status, fields managed by other controllers) are ignored,
+// like server noise (creationTimestamp, managedFields, resourceVersion, uid, generation and type meta).
+func AssertEquivalent(t testing.TB, want, got client.Object) bool {
+	t.Helper()
+
+	if want == nil || got == nil {
+		if want != got {
+			t.Errorf("Objects are not equivalent, want %v, got %v", want, got)
+			return false
+		}
+		return true
+	}
+
+	diff, err := k8sbuilder.Diff(scrub(want), scrub(got))
+	if err != nil {
+		t.Errorf("Error when compare objects: %s", err.Error())
+		return false
+	}
+	if diff != "" {
+		t.Errorf("Objects are not equivalent (-got +want):\n%s", diff)
+		return false
+	}
+
+	return true
+}
+
+// scrub permit to get copy of object without server noise
+func scrub(o client.Object) client.Object {
+	o = o.DeepCopyObject().(client.Object)
+	o.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	o.SetManagedFields(nil)
+	o.SetResourceVersion("")
+	o.SetUID("")
+	o.SetGeneration(0)
+	o.SetSelfLink("")
+	o.SetCreationTimestamp(metav1.Time{})
+
+	return o
+}
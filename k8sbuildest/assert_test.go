@@ -0,0 +1,32 @@
+package k8sbuildest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAssertEquivalent(t *testing.T) {
+	want := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Data: map[string]string{
+			"a": "1",
+		},
+	}
+	got := want.DeepCopy()
+	got.ResourceVersion = "12"
+	got.UID = "uid"
+	got.CreationTimestamp = metav1.Now()
+	got.Labels = map[string]string{
+		"defaulted": "true",
+	}
+
+	assert.True(t, AssertEquivalent(t, want, got))
+
+	got.Data["a"] = "2"
+	assert.False(t, AssertEquivalent(&testing.T{}, want, got))
+}
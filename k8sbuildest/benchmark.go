@@ -0,0 +1,92 @@
+package k8sbuildest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateBaselineEnv is the environment variable to set to update benchmark baseline files instead of compare them
+const UpdateBaselineEnv = "UPDATE_BASELINE"
+
+// BenchmarkBaseline is the reference cost of one benchmark
+// NsPerOp is only informative, because it depend on the machine. Allocations are compared.
+type BenchmarkBaseline struct {
+	NsPerOp     int64 `json:"nsPerOp"`
+	AllocsPerOp int64 `json:"allocsPerOp"`
+	BytesPerOp  int64 `json:"bytesPerOp"`
+}
+
+// AssertBenchmarkBaseline permit to run the benchmark and compare its allocations with the baseline file
+// The baseline file is a JSON map of benchmark name to BenchmarkBaseline, so many benchmarks can share it.
+// Tolerance is the allowed ratio of regression, like 0.1 for 10%.
+// When UPDATE_BASELINE environment variable is set, it update the baseline file instead.
+func AssertBenchmarkBaseline(t testing.TB, name string, benchmark func(b *testing.B), baselineFile string, tolerance float64) bool {
+	t.Helper()
+
+	result := testing.Benchmark(benchmark)
+	if result.N == 0 {
+		t.Errorf("Benchmark %s failed", name)
+		return false
+	}
+	actual := BenchmarkBaseline{
+		NsPerOp:     result.NsPerOp(),
+		AllocsPerOp: result.AllocsPerOp(),
+		BytesPerOp:  result.AllocedBytesPerOp(),
+	}
+
+	baselines := map[string]BenchmarkBaseline{}
+	data, err := os.ReadFile(baselineFile)
+	if err == nil {
+		err = json.Unmarshal(data, &baselines)
+	}
+
+	if os.Getenv(UpdateBaselineEnv) != "" {
+		if err != nil && !os.IsNotExist(err) {
+			t.Errorf("Error when read baseline file: %s", err.Error())
+			return false
+		}
+		baselines[name] = actual
+		data, err = json.MarshalIndent(baselines, "", "  ")
+		if err != nil {
+			t.Errorf("Error when render baseline file: %s", err.Error())
+			return false
+		}
+		if err = os.MkdirAll(filepath.Dir(baselineFile), 0755); err != nil {
+			t.Errorf("Error when create baseline file directory: %s", err.Error())
+			return false
+		}
+		if err = os.WriteFile(baselineFile, append(data, '\n'), 0644); err != nil {
+			t.Errorf("Error when update baseline file: %s", err.Error())
+			return false
+		}
+		return true
+	}
+
+	if err != nil {
+		t.Errorf("Error when read baseline file (set %s=true to create it): %s", UpdateBaselineEnv, err.Error())
+		return false
+	}
+	expected, ok := baselines[name]
+	if !ok {
+		t.Errorf("Benchmark %s not found on baseline file (set %s=true to add it)", name, UpdateBaselineEnv)
+		return false
+	}
+
+	success := true
+	if exceedBaseline(actual.AllocsPerOp, expected.AllocsPerOp, tolerance) {
+		t.Errorf("Benchmark %s regress: %d allocs/op, baseline is %d allocs/op", name, actual.AllocsPerOp, expected.AllocsPerOp)
+		success = false
+	}
+	if exceedBaseline(actual.BytesPerOp, expected.BytesPerOp, tolerance) {
+		t.Errorf("Benchmark %s regress: %d B/op, baseline is %d B/op", name, actual.BytesPerOp, expected.BytesPerOp)
+		success = false
+	}
+
+	return success
+}
+
+func exceedBaseline(actual, expected int64, tolerance float64) bool {
+	return float64(actual) > float64(expected)*(1+tolerance)
+}
@@ -0,0 +1,30 @@
+package k8sbuildest
+
+import (
+	"testing"
+
+	"github.com/disaster37/k8sbuilder"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAssertBenchmarkBaseline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Benchmarks are skipped on short mode")
+	}
+
+	expected := &corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "app", Image: "nginx:2.0", Args: []string{"run"}}},
+	}
+
+	AssertBenchmarkBaseline(t, "MergeK8s", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dst := &corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: "nginx:1.0"}},
+			}
+			if err := k8sbuilder.MergeK8s(dst, dst, expected); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}, "testdata/benchmark_baseline.json", 0.2)
+}
@@ -0,0 +1,103 @@
+// Package k8sbuildest provide helpers to test builders and the objects they produce
+package k8sbuildest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/disaster37/k8sbuilder"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// StartEnvironment permit to start envtest API server and get client on it
+// The API server is stopped when test finish.
+// It need the envtest binaries, see KUBEBUILDER_ASSETS.
+func StartEnvironment(t testing.TB, scheme *runtime.Scheme, crdPaths ...string) client.Client {
+	t.Helper()
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     crdPaths,
+		ErrorIfCRDPathMissing: len(crdPaths) > 0,
+	}
+
+	cfg, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("Error when start envtest: %s", err.Error())
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("Error when stop envtest: %s", err.Error())
+		}
+	})
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("Error when create client: %s", err.Error())
+	}
+
+	return c
+}
+
+// ApplyAndGet permit to create or update object on API server, and read it back
+func ApplyAndGet(ctx context.Context, c client.Client, o client.Object) (live client.Object, err error) {
+	if _, err = k8sbuilder.Reconcile(ctx, c, o); err != nil {
+		return nil, err
+	}
+
+	live = o.DeepCopyObject().(client.Object)
+	if err = c.Get(ctx, client.ObjectKeyFromObject(o), live); err != nil {
+		return nil, errors.Wrapf(err, "Error when read back %s/%s", o.GetNamespace(), o.GetName())
+	}
+
+	return live, nil
+}
+
+// AssertIdempotent permit to check that re-building the object produce no diff with the object applied on API server
+// The build function is called twice, so it must return a new object each time, like a reconciler do.
+func AssertIdempotent(t testing.TB, c client.Client, build func() (client.Object, error)) bool {
+	t.Helper()
+	ctx := context.Background()
+
+	o, err := build()
+	if err != nil {
+		t.Errorf("Error when build object: %s", err.Error())
+		return false
+	}
+	live, err := ApplyAndGet(ctx, c, o)
+	if err != nil {
+		t.Errorf("Error when apply object: %s", err.Error())
+		return false
+	}
+
+	o, err = build()
+	if err != nil {
+		t.Errorf("Error when re-build object: %s", err.Error())
+		return false
+	}
+
+	diff, err := k8sbuilder.Diff(o, live)
+	if err != nil {
+		t.Errorf("Error when diff object: %s", err.Error())
+		return false
+	}
+	if diff != "" {
+		t.Errorf("Re-building object produce diff with live object (-live +expected):\n%s", diff)
+		return false
+	}
+
+	res, err := k8sbuilder.Reconcile(ctx, c, o)
+	if err != nil {
+		t.Errorf("Error when re-apply object: %s", err.Error())
+		return false
+	}
+	if res != controllerutil.OperationResultNone {
+		t.Errorf("Re-applying object must do nothing, but it was %s", res)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,71 @@
+package k8sbuildest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/disaster37/k8sbuilder"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// UpdateGoldenEnv is the environment variable to set to update golden files instead of compare them
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Builder is the builder interface expected by golden helpers
+type Builder[T any] interface {
+	Build() (T, error)
+}
+
+// AssertMatchesGolden permit to build and render the object as YAML, and compare it with golden file
+// Map keys are sorted, so the output is stable between builds.
+// When UPDATE_GOLDEN environment variable is set, it update the golden file instead.
+func AssertMatchesGolden[T any](t testing.TB, b Builder[T], goldenFile string) bool {
+	t.Helper()
+
+	o, err := b.Build()
+	if err != nil {
+		t.Errorf("Error when build object: %s", err.Error())
+		return false
+	}
+
+	return AssertObjectMatchesGolden(t, o, goldenFile)
+}
+
+// AssertObjectMatchesGolden permit to render the object as YAML and compare it with golden file
+// Secret values are redacted, so golden files can be committed safely.
+func AssertObjectMatchesGolden(t testing.TB, o any, goldenFile string) bool {
+	t.Helper()
+
+	if object, ok := o.(runtime.Object); ok {
+		o = k8sbuilder.RedactSecret(object)
+	}
+
+	actual, err := yaml.Marshal(o)
+	if err != nil {
+		t.Errorf("Error when render object: %s", err.Error())
+		return false
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err = os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+			t.Errorf("Error when create golden file directory: %s", err.Error())
+			return false
+		}
+		if err = os.WriteFile(goldenFile, actual, 0644); err != nil {
+			t.Errorf("Error when update golden file: %s", err.Error())
+			return false
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Errorf("Error when read golden file (set %s=true to create it): %s", UpdateGoldenEnv, err.Error())
+		return false
+	}
+
+	return assert.Equal(t, string(expected), string(actual), "Object not match golden file %s", goldenFile)
+}
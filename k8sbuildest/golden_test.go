@@ -0,0 +1,26 @@
+package k8sbuildest
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type configMapBuilder struct{}
+
+func (h configMapBuilder) Build() (*corev1.ConfigMap, error) {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Data: map[string]string{
+			"b": "2",
+			"a": "1",
+		},
+	}, nil
+}
+
+func TestAssertMatchesGolden(t *testing.T) {
+	AssertMatchesGolden[*corev1.ConfigMap](t, configMapBuilder{}, "testdata/configmap.yaml")
+}
@@ -0,0 +1,458 @@
+package k8sbuildest
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/disaster37/k8sbuilder"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ k8sbuilder.PodTemplateBuilder = &RecordingPodTemplateBuilder{}
+
+// RecordingPodTemplateBuilder is a pod template builder that record all calls before delegate them to the default builder
+// It permit to test code that compose builders, without assert on the rendered pod template.
+type RecordingPodTemplateBuilder struct {
+	builder    k8sbuilder.PodTemplateBuilder
+	operations []k8sbuilder.Operation
+}
+
+// NewRecordingPodTemplateBuilder permit to get recording pod template builder
+func NewRecordingPodTemplateBuilder() *RecordingPodTemplateBuilder {
+	return &RecordingPodTemplateBuilder{
+		builder:    k8sbuilder.NewPodTemplateBuilder(),
+		operations: make([]k8sbuilder.Operation, 0),
+	}
+}
+
+// Operations permit to get all recorded calls, in the same order
+// The last argument is always the options
+func (h *RecordingPodTemplateBuilder) Operations() []k8sbuilder.Operation {
+	return h.operations
+}
+
+// Calls permit to get all recorded calls of method
+func (h *RecordingPodTemplateBuilder) Calls(name string) []k8sbuilder.Operation {
+	calls := make([]k8sbuilder.Operation, 0)
+	for _, o := range h.operations {
+		if o.Name == name {
+			calls = append(calls, o)
+		}
+	}
+
+	return calls
+}
+
+// CalledWith permit to know if method was called at least one time with the expected options
+func (h *RecordingPodTemplateBuilder) CalledWith(name string, opts ...k8sbuilder.WithOption) bool {
+	for _, o := range h.Calls(name) {
+		if len(o.Args) == 0 {
+			continue
+		}
+		callOpts, ok := o.Args[len(o.Args)-1].([]k8sbuilder.WithOption)
+		if !ok {
+			continue
+		}
+		if len(callOpts) == 0 && len(opts) == 0 || reflect.DeepEqual(callOpts, opts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *RecordingPodTemplateBuilder) record(name string, args ...any) {
+	h.operations = append(h.operations, k8sbuilder.Operation{
+		Name: name,
+		Args: args,
+	})
+}
+
+// PodTemplate permit to get current pod template
+func (h *RecordingPodTemplateBuilder) PodTemplate() *corev1.PodTemplateSpec {
+	return h.builder.PodTemplate()
+}
+
+// Selector delegate the call
+func (h *RecordingPodTemplateBuilder) Selector(keys ...string) (*metav1.LabelSelector, error) {
+	return h.builder.Selector(keys...)
+}
+
+// Build record the call and delegate it
+func (h *RecordingPodTemplateBuilder) Build() (*corev1.PodTemplateSpec, error) {
+	h.record("Build")
+	return h.builder.Build()
+}
+
+// WithDefaults record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithDefaults(defaults *k8sbuilder.Defaults) k8sbuilder.PodTemplateBuilder {
+	h.record("WithDefaults", defaults)
+	h.builder.WithDefaults(defaults)
+	return h
+}
+
+// WithPolicies record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithPolicies(mode k8sbuilder.PolicyMode, policies ...k8sbuilder.Policy) k8sbuilder.PodTemplateBuilder {
+	h.record("WithPolicies", mode, policies)
+	h.builder.WithPolicies(mode, policies...)
+	return h
+}
+
+// WithContainerResources record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithContainerResources(name string, requests, limits map[string]string, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithContainerResources", name, requests, limits, opts)
+	h.builder.WithContainerResources(name, requests, limits, opts...)
+	return h
+}
+
+// WithResourceNormalization record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithResourceNormalization(n *k8sbuilder.ResourceNormalization) k8sbuilder.PodTemplateBuilder {
+	h.record("WithResourceNormalization", n)
+	h.builder.WithResourceNormalization(n)
+	return h
+}
+
+// WithNormalization record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithNormalization() k8sbuilder.PodTemplateBuilder {
+	h.record("WithNormalization")
+	h.builder.WithNormalization()
+	return h
+}
+
+// WithDefaultProbes record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithDefaultProbes() k8sbuilder.PodTemplateBuilder {
+	h.record("WithDefaultProbes")
+	h.builder.WithDefaultProbes()
+	return h
+}
+
+// WithTemplateData record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithTemplateData(data any) k8sbuilder.PodTemplateBuilder {
+	h.record("WithTemplateData", data)
+	h.builder.WithTemplateData(data)
+	return h
+}
+
+// WithPodTemplateSpec record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithPodTemplateSpec(pts *corev1.PodTemplateSpec, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithPodTemplateSpec", pts, opts)
+	h.builder.WithPodTemplateSpec(pts, opts...)
+	return h
+}
+
+// WithLabels record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithLabels(labels map[string]string, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithLabels", labels, opts)
+	h.builder.WithLabels(labels, opts...)
+	return h
+}
+
+// WithoutLabels record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithoutLabels(keys ...string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithoutLabels", keys)
+	h.builder.WithoutLabels(keys...)
+	return h
+}
+
+// WithRecommendedLabels record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithRecommendedLabels(name, instance, version, component, partOf, managedBy string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithRecommendedLabels", name, instance, version, component, partOf, managedBy)
+	h.builder.WithRecommendedLabels(name, instance, version, component, partOf, managedBy)
+	return h
+}
+
+// WithChecksumAnnotation record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithChecksumAnnotation(key string, inputs ...any) k8sbuilder.PodTemplateBuilder {
+	h.record("WithChecksumAnnotation", key, inputs)
+	h.builder.WithChecksumAnnotation(key, inputs...)
+	return h
+}
+
+// WithParentMetadata record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithParentMetadata(parent metav1.Object, labelKeys []string, annotationKeys []string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithParentMetadata", parent, labelKeys, annotationKeys)
+	h.builder.WithParentMetadata(parent, labelKeys, annotationKeys)
+	return h
+}
+
+// WithAnnotations record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithAnnotations(annotations map[string]string, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithAnnotations", annotations, opts)
+	h.builder.WithAnnotations(annotations, opts...)
+	return h
+}
+
+// WithoutAnnotations record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithoutAnnotations(keys ...string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithoutAnnotations", keys)
+	h.builder.WithoutAnnotations(keys...)
+	return h
+}
+
+// WithImagePullSecrets record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithImagePullSecrets(ips []corev1.LocalObjectReference, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithImagePullSecrets", ips, opts)
+	h.builder.WithImagePullSecrets(ips, opts...)
+	return h
+}
+
+// WithImagePullSecretNames record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithImagePullSecretNames(names []string, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithImagePullSecretNames", names, opts)
+	h.builder.WithImagePullSecretNames(names, opts...)
+	return h
+}
+
+// WithTerminationGracePeriodSeconds record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithTerminationGracePeriodSeconds(nb int64, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithTerminationGracePeriodSeconds", nb, opts)
+	h.builder.WithTerminationGracePeriodSeconds(nb, opts...)
+	return h
+}
+
+// WithGracefulShutdown record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithGracefulShutdown(periodSeconds int64, preStopSleepSeconds int64, containers ...string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithGracefulShutdown", periodSeconds, preStopSleepSeconds, containers)
+	h.builder.WithGracefulShutdown(periodSeconds, preStopSleepSeconds, containers...)
+	return h
+}
+
+// WithRestartPolicy record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithRestartPolicy(restartPolicy corev1.RestartPolicy, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithRestartPolicy", restartPolicy, opts)
+	h.builder.WithRestartPolicy(restartPolicy, opts...)
+	return h
+}
+
+// WithTolerations record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithTolerations(tolerations []corev1.Toleration, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithTolerations", tolerations, opts)
+	h.builder.WithTolerations(tolerations, opts...)
+	return h
+}
+
+// WithTolerationsForNodePool record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithTolerationsForNodePool(ctx context.Context, c client.Reader, selector labels.Selector) k8sbuilder.PodTemplateBuilder {
+	h.record("WithTolerationsForNodePool", selector)
+	h.builder.WithTolerationsForNodePool(ctx, c, selector)
+	return h
+}
+
+// WithNodeSelector record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithNodeSelector(nodeSelector map[string]string, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithNodeSelector", nodeSelector, opts)
+	h.builder.WithNodeSelector(nodeSelector, opts...)
+	return h
+}
+
+// WithGPUScheduling record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithGPUScheduling(resourceName string, nodeSelector map[string]string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithGPUScheduling", resourceName, nodeSelector)
+	h.builder.WithGPUScheduling(resourceName, nodeSelector)
+	return h
+}
+
+// WithInitContainers record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithInitContainers(containers []corev1.Container, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithInitContainers", containers, opts)
+	h.builder.WithInitContainers(containers, opts...)
+	return h
+}
+
+// WithInitContainerBefore record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithInitContainerBefore(name string, container corev1.Container) k8sbuilder.PodTemplateBuilder {
+	h.record("WithInitContainerBefore", name, container)
+	h.builder.WithInitContainerBefore(name, container)
+	return h
+}
+
+// WithInitContainerAfter record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithInitContainerAfter(name string, container corev1.Container) k8sbuilder.PodTemplateBuilder {
+	h.record("WithInitContainerAfter", name, container)
+	h.builder.WithInitContainerAfter(name, container)
+	return h
+}
+
+// WithContainers record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithContainers(containers []corev1.Container, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithContainers", containers, opts)
+	h.builder.WithContainers(containers, opts...)
+	return h
+}
+
+// MoveContainerFirst record the call and delegate it
+func (h *RecordingPodTemplateBuilder) MoveContainerFirst(name string) k8sbuilder.PodTemplateBuilder {
+	h.record("MoveContainerFirst", name)
+	h.builder.MoveContainerFirst(name)
+	return h
+}
+
+// OrderContainers record the call and delegate it
+func (h *RecordingPodTemplateBuilder) OrderContainers(names ...string) k8sbuilder.PodTemplateBuilder {
+	h.record("OrderContainers", names)
+	h.builder.OrderContainers(names...)
+	return h
+}
+
+// WithVolumes record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithVolumes(volumes []corev1.Volume, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithVolumes", volumes, opts)
+	h.builder.WithVolumes(volumes, opts...)
+	return h
+}
+
+// WithAffinity record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithAffinity(affinity corev1.Affinity, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithAffinity", affinity, opts)
+	h.builder.WithAffinity(affinity, opts...)
+	return h
+}
+
+// WithMultiArchSupport record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithMultiArchSupport(archs ...string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithMultiArchSupport", archs)
+	h.builder.WithMultiArchSupport(archs...)
+	return h
+}
+
+// WithImageDigests record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithImageDigests(container string, digests map[string]string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithImageDigests", container, digests)
+	h.builder.WithImageDigests(container, digests)
+	return h
+}
+
+// WithSpotToleration record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithSpotToleration(provider string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithSpotToleration", provider)
+	h.builder.WithSpotToleration(provider)
+	return h
+}
+
+// WithSecurityContext record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithSecurityContext(sc *corev1.PodSecurityContext, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithSecurityContext", sc, opts)
+	h.builder.WithSecurityContext(sc, opts...)
+	return h
+}
+
+// WithFSGroup record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithFSGroup(id int64, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithFSGroup", id, opts)
+	h.builder.WithFSGroup(id, opts...)
+	return h
+}
+
+// WithFSGroupChangePolicy record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithFSGroupChangePolicy(policy corev1.PodFSGroupChangePolicy, opts ...k8sbuilder.WithOption) k8sbuilder.PodTemplateBuilder {
+	h.record("WithFSGroupChangePolicy", policy, opts)
+	h.builder.WithFSGroupChangePolicy(policy, opts...)
+	return h
+}
+
+// WithSupplementalGroups record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithSupplementalGroups(ids ...int64) k8sbuilder.PodTemplateBuilder {
+	h.record("WithSupplementalGroups", ids)
+	h.builder.WithSupplementalGroups(ids...)
+	return h
+}
+
+// WithWindowsOptions record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithWindowsOptions(options *corev1.WindowsSecurityContextOptions) k8sbuilder.PodTemplateBuilder {
+	h.record("WithWindowsOptions", options)
+	h.builder.WithWindowsOptions(options)
+	return h
+}
+
+// ForWindows record the call and delegate it
+func (h *RecordingPodTemplateBuilder) ForWindows() k8sbuilder.PodTemplateBuilder {
+	h.record("ForWindows")
+	h.builder.ForWindows()
+	return h
+}
+
+// WithSeccompProfile record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithSeccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithSeccompProfile", profileType, localhostProfile)
+	h.builder.WithSeccompProfile(profileType, localhostProfile)
+	return h
+}
+
+// WithContainerSeccompProfile record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithContainerSeccompProfile(container string, profileType corev1.SeccompProfileType, localhostProfile string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithContainerSeccompProfile", container, profileType, localhostProfile)
+	h.builder.WithContainerSeccompProfile(container, profileType, localhostProfile)
+	return h
+}
+
+// WithAppArmorProfile record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithAppArmorProfile(container string, profile string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithAppArmorProfile", container, profile)
+	h.builder.WithAppArmorProfile(container, profile)
+	return h
+}
+
+// WithIstioInjection record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithIstioInjection(enabled bool, overrides map[string]string) k8sbuilder.PodTemplateBuilder {
+	h.record("WithIstioInjection", enabled, overrides)
+	h.builder.WithIstioInjection(enabled, overrides)
+	return h
+}
+
+// WithLinkerdInjection record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithLinkerdInjection(enabled bool) k8sbuilder.PodTemplateBuilder {
+	h.record("WithLinkerdInjection", enabled)
+	h.builder.WithLinkerdInjection(enabled)
+	return h
+}
+
+// WithLogger record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithLogger(logger logr.Logger) k8sbuilder.PodTemplateBuilder {
+	h.record("WithLogger", logger)
+	h.builder.WithLogger(logger)
+	return h
+}
+
+// WithDebug record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithDebug() k8sbuilder.PodTemplateBuilder {
+	h.record("WithDebug")
+	h.builder.WithDebug()
+	return h
+}
+
+// WithValidationMode record the call and delegate it
+func (h *RecordingPodTemplateBuilder) WithValidationMode(mode k8sbuilder.ValidationMode) k8sbuilder.PodTemplateBuilder {
+	h.record("WithValidationMode", mode)
+	h.builder.WithValidationMode(mode)
+	return h
+}
+
+// Warnings delegate the call
+func (h *RecordingPodTemplateBuilder) Warnings() field.ErrorList {
+	return h.builder.Warnings()
+}
+
+// DebugCalls delegate the call
+func (h *RecordingPodTemplateBuilder) DebugCalls() []k8sbuilder.DebugCall {
+	return h.builder.DebugCalls()
+}
+
+// Summary delegate the call
+func (h *RecordingPodTemplateBuilder) Summary() string {
+	return h.builder.Summary()
+}
+
+// AuditPSS delegate the call
+func (h *RecordingPodTemplateBuilder) AuditPSS(level k8sbuilder.PSSLevel) (violations field.ErrorList, err error) {
+	return h.builder.AuditPSS(level)
+}
+
+// IsDrifted delegate the call
+func (h *RecordingPodTemplateBuilder) IsDrifted(live *corev1.PodTemplateSpec) (bool, []string) {
+	return h.builder.IsDrifted(live)
+}
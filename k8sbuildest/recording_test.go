@@ -0,0 +1,23 @@
+package k8sbuildest
+
+import (
+	"testing"
+
+	"github.com/disaster37/k8sbuilder"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRecordingPodTemplateBuilder(t *testing.T) {
+	b := NewRecordingPodTemplateBuilder()
+
+	b.WithLabels(map[string]string{"app": "test"}).
+		WithPodTemplateSpec(&corev1.PodTemplateSpec{}, k8sbuilder.Merge)
+
+	assert.Len(t, b.Operations(), 2)
+	assert.True(t, b.CalledWith("WithLabels"))
+	assert.False(t, b.CalledWith("WithLabels", k8sbuilder.Merge))
+	assert.True(t, b.CalledWith("WithPodTemplateSpec", k8sbuilder.Merge))
+	assert.False(t, b.CalledWith("WithVolumes"))
+	assert.Equal(t, map[string]string{"app": "test"}, b.PodTemplate().Labels)
+}
@@ -0,0 +1,82 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ToKustomizePatch permit to get the strategic merge patch YAML with only the changes of expected object relative to base object
+// The patch keep apiVersion, kind, name and namespace, so Kustomize can match it with the base resource on overlays.
+// ApiVersion and kind are read from objects, or from the Kubernetes scheme if not set.
+// It return nil if there are no changes.
+func ToKustomizePatch(expected, base client.Object) (patch []byte, err error) {
+	if expected == nil || base == nil {
+		return nil, errors.New("Expected and base object can't be nil")
+	}
+	if reflect.TypeOf(expected) != reflect.TypeOf(base) {
+		return nil, errors.Errorf("Expected object (%T) and base object (%T) must have the same type", expected, base)
+	}
+
+	gvk := expected.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		gvk = base.GetObjectKind().GroupVersionKind()
+	}
+	if gvk.Empty() {
+		if gvk, err = apiutil.GVKForObject(expected, scheme.Scheme); err != nil {
+			return nil, errors.Wrap(err, "Error when get apiVersion and kind of object")
+		}
+	}
+
+	expectedByte, err := json.Marshal(expected)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal expected object")
+	}
+	baseByte, err := json.Marshal(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal base object")
+	}
+
+	patchMeta, err := newCachedPatchMeta(expected)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when get patch metadata")
+	}
+
+	patchByte, err := strategicpatch.CreateTwoWayMergePatchUsingLookupPatchMeta(baseByte, expectedByte, patchMeta)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when create strategic merge patch")
+	}
+
+	patchMap := map[string]any{}
+	if err = json.Unmarshal(patchByte, &patchMap); err != nil {
+		return nil, errors.Wrap(err, "Error when unmarshal strategic merge patch")
+	}
+	if len(patchMap) == 0 {
+		return nil, nil
+	}
+
+	// Kustomize need the identity of the targeted resource
+	metadata, _ := patchMap["metadata"].(map[string]any)
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["name"] = base.GetName()
+	if base.GetNamespace() != "" {
+		metadata["namespace"] = base.GetNamespace()
+	}
+	patchMap["metadata"] = metadata
+	patchMap["apiVersion"], patchMap["kind"] = gvk.ToAPIVersionAndKind()
+
+	patch, err = yaml.Marshal(patchMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when marshal Kustomize patch")
+	}
+
+	return patch, nil
+}
@@ -0,0 +1,58 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestToKustomizePatch(t *testing.T) {
+	base := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Labels: map[string]string{"app": "test"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "app:1"}, {Name: "sidecar", Image: "sidecar:1"}},
+				},
+			},
+		},
+	}
+	expected := base.DeepCopy()
+	expected.Spec.Replicas = pointer.Int32(3)
+	expected.Spec.Template.Spec.Containers[0].Image = "app:2"
+
+	patch, err := ToKustomizePatch(expected, base)
+	assert.NoError(t, err)
+	assert.Equal(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+  namespace: default
+spec:
+  replicas: 3
+  template:
+    spec:
+      $setElementOrder/containers:
+      - name: app
+      - name: sidecar
+      containers:
+      - image: app:2
+        name: app
+`, string(patch))
+
+	// No changes
+	patch, err = ToKustomizePatch(base.DeepCopy(), base)
+	assert.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// Errors
+	_, err = ToKustomizePatch(expected, &corev1.ConfigMap{})
+	assert.Error(t, err)
+	_, err = ToKustomizePatch(nil, base)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package k8sbuilder
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelInstance  = "app.kubernetes.io/instance"
+	LabelVersion   = "app.kubernetes.io/version"
+	LabelComponent = "app.kubernetes.io/component"
+	LabelPartOf    = "app.kubernetes.io/part-of"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// SelectorLabelKeys are the pod template labels used to derive selector when no keys are given
+// They identify the application and not change between versions, because of selectors are immutable.
+var SelectorLabelKeys = []string{LabelName, LabelInstance}
+
+// volatileLabelKeys are the labels that change between versions, so they are never used on derived selector
+var volatileLabelKeys = []string{LabelVersion}
+
+// RecommendedLabels permit to get the recommended app.kubernetes.io labels
+// Empty values are not set.
+func RecommendedLabels(name, instance, version, component, partOf, managedBy string) map[string]string {
+	labels := map[string]string{}
+
+	for key, value := range map[string]string{
+		LabelName:      name,
+		LabelInstance:  instance,
+		LabelVersion:   version,
+		LabelComponent: component,
+		LabelPartOf:    partOf,
+		LabelManagedBy: managedBy,
+	} {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+
+	return labels
+}
+
+// SelectorFromLabels permit to derive label selector from a subset of pod template labels
+// If no keys are provided, all labels are used. It return error if key not exist on labels.
+func SelectorFromLabels(podLabels map[string]string, keys ...string) (selector *metav1.LabelSelector, err error) {
+	if len(keys) == 0 {
+		for key := range podLabels {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("Can't derive selector from empty labels")
+	}
+
+	selector = &metav1.LabelSelector{
+		MatchLabels: make(map[string]string, len(keys)),
+	}
+	for _, key := range keys {
+		value, ok := podLabels[key]
+		if !ok {
+			return nil, errors.Errorf("Label %s not found on pod template", key)
+		}
+		selector.MatchLabels[key] = value
+	}
+
+	return selector, nil
+}
+
+// StableSelectorKeys permit to get the keys of pod template labels that can be used on immutable selector
+// It return the SelectorLabelKeys set on labels. If none are set, it return all keys except app.kubernetes.io/version
+// and the excluded keys, like the keys of default labels that can change later.
+// Keys are sorted.
+func StableSelectorKeys(podLabels map[string]string, excludedKeys ...string) (keys []string) {
+	keys = make([]string, 0, len(podLabels))
+	for _, key := range SelectorLabelKeys {
+		if _, ok := podLabels[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		return keys
+	}
+
+	for key := range podLabels {
+		if !funk.ContainsString(volatileLabelKeys, key) && !funk.ContainsString(excludedKeys, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// ValidateSelector permit to check that selector match pod template labels
+func ValidateSelector(selector *metav1.LabelSelector, podLabels map[string]string) (err error) {
+	if selector == nil {
+		return errors.New("Selector can't be nil")
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return errors.Wrap(err, "Invalid selector")
+	}
+	if s.Empty() {
+		return errors.New("Selector can't be empty")
+	}
+	if !s.Matches(labels.Set(podLabels)) {
+		return errors.Errorf("Selector %s does not match pod template labels", s.String())
+	}
+
+	return nil
+}
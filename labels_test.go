@@ -0,0 +1,116 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecommendedLabels(t *testing.T) {
+	// All values
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/name":       "elasticsearch",
+		"app.kubernetes.io/instance":   "logs",
+		"app.kubernetes.io/version":    "8.5.0",
+		"app.kubernetes.io/component":  "database",
+		"app.kubernetes.io/part-of":    "elk",
+		"app.kubernetes.io/managed-by": "operator",
+	}, RecommendedLabels("elasticsearch", "logs", "8.5.0", "database", "elk", "operator"))
+
+	// Empty values are skipped
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/name":       "elasticsearch",
+		"app.kubernetes.io/managed-by": "operator",
+	}, RecommendedLabels("elasticsearch", "", "", "", "", "operator"))
+	assert.Empty(t, RecommendedLabels("", "", "", "", "", ""))
+}
+
+func TestSelectorFromLabels(t *testing.T) {
+	podLabels := map[string]string{
+		LabelName:     "app",
+		LabelInstance: "test",
+		LabelVersion:  "1.0.0",
+	}
+
+	// From chosen keys
+	selector, err := SelectorFromLabels(podLabels, LabelName, LabelInstance)
+	assert.NoError(t, err)
+	assert.Equal(t, &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			LabelName:     "app",
+			LabelInstance: "test",
+		},
+	}, selector)
+
+	// From all labels
+	selector, err = SelectorFromLabels(podLabels)
+	assert.NoError(t, err)
+	assert.Equal(t, podLabels, selector.MatchLabels)
+
+	// Missing key
+	_, err = SelectorFromLabels(podLabels, LabelName, LabelComponent)
+	assert.ErrorContains(t, err, "Label app.kubernetes.io/component not found on pod template")
+
+	// Empty labels
+	_, err = SelectorFromLabels(nil)
+	assert.ErrorContains(t, err, "Can't derive selector from empty labels")
+	_, err = SelectorFromLabels(map[string]string{})
+	assert.ErrorContains(t, err, "Can't derive selector from empty labels")
+}
+
+func TestStableSelectorKeys(t *testing.T) {
+	// Stable keys are preferred
+	keys := StableSelectorKeys(map[string]string{
+		LabelName:    "app",
+		LabelVersion: "1.0.0",
+		"env":        "prod",
+	})
+	assert.Equal(t, []string{LabelName}, keys)
+
+	// Without stable keys, all keys except volatile and excluded ones
+	keys = StableSelectorKeys(map[string]string{
+		"app":        "test",
+		"env":        "prod",
+		"team":       "ops",
+		LabelVersion: "1.0.0",
+	}, "team")
+	assert.Equal(t, []string{"app", "env"}, keys)
+}
+
+func TestValidateSelector(t *testing.T) {
+	podLabels := map[string]string{
+		LabelName:     "app",
+		LabelInstance: "test",
+	}
+
+	// Match
+	assert.NoError(t, ValidateSelector(&metav1.LabelSelector{MatchLabels: map[string]string{LabelName: "app"}}, podLabels))
+	assert.NoError(t, ValidateSelector(&metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: LabelInstance, Operator: metav1.LabelSelectorOpIn, Values: []string{"test", "prod"}},
+		},
+	}, podLabels))
+
+	// Mismatch value
+	err := ValidateSelector(&metav1.LabelSelector{MatchLabels: map[string]string{LabelName: "other"}}, podLabels)
+	assert.ErrorContains(t, err, "does not match pod template labels")
+
+	// Mismatch key
+	err = ValidateSelector(&metav1.LabelSelector{MatchLabels: map[string]string{LabelComponent: "server"}}, podLabels)
+	assert.ErrorContains(t, err, "does not match pod template labels")
+
+	// Nil selector
+	assert.ErrorContains(t, ValidateSelector(nil, podLabels), "Selector can't be nil")
+
+	// Empty selector match all pods
+	assert.ErrorContains(t, ValidateSelector(&metav1.LabelSelector{}, podLabels), "Selector can't be empty")
+
+	// Invalid selector
+	err = ValidateSelector(&metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: LabelName, Operator: "Unknown"},
+		},
+	}, podLabels)
+	assert.ErrorContains(t, err, "Invalid selector")
+}
@@ -1,9 +1,11 @@
 package k8sbuilder
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"reflect"
+	"sync"
 
 	"github.com/thoas/go-funk"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
@@ -11,6 +13,8 @@ import (
 
 // MergeK8s permit to merge kubernetes resources
 func MergeK8s(dst any, src, new any) (err error) {
+	defer func() { observeMerge("MergeK8s", err) }()
+
   if dst != nil && reflect.ValueOf(dst).Kind() != reflect.Ptr {
 		return errors.New("dst must be a pointer of instanciated object")
 	}
@@ -29,26 +33,40 @@ func MergeK8s(dst any, src, new any) (err error) {
 		reflect.ValueOf(dst).Elem().Set(value)
 	}
 
-	dstByte, err := json.Marshal(dst)
+	// The patch metadata is looked up once and the documents are decoded once,
+	// instead of a JSON round trip between the patch creation and its application
+	schema, err := newCachedPatchMeta(reflect.ValueOf(dst).Elem().Interface())
+	if err != nil {
+		return err
+	}
+
+	original, err := toJSONMap(dst)
 	if err != nil {
 		return err
 	}
-	newByte, err := json.Marshal(new)
+	modified, err := toJSONMap(new)
 	if err != nil {
 		return err
 	}
 
-	patch, err := strategicpatch.CreateTwoWayMergePatch(dstByte, newByte, reflect.ValueOf(dst).Elem().Interface())
+	patch, err := strategicpatch.CreateTwoWayMergeMapPatchUsingLookupPatchMeta(original, modified, schema)
 	if err != nil {
 		return err
 	}
 
-	expectedByte, err := strategicpatch.StrategicMergePatch(dstByte, patch, reflect.ValueOf(dst).Elem().Interface())
+	expected, err := strategicpatch.StrategicMergeMapPatchUsingLookupPatchMeta(original, patch, schema)
 	if err != nil {
 		return err
 	}
 
-	if err = json.Unmarshal(expectedByte, dst); err != nil {
+	buf := mergeBufferPool.Get().(*bytes.Buffer)
+	defer mergeBufferPool.Put(buf)
+	buf.Reset()
+	if err = json.NewEncoder(buf).Encode(expected); err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(buf.Bytes(), dst); err != nil {
 		return err
 	}
 
@@ -56,6 +74,31 @@ func MergeK8s(dst any, src, new any) (err error) {
 }
 
 
+// mergeBufferPool is the pool of buffers used to encode objects on merge
+var mergeBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// toJSONMap permit to convert object to JSON map, like strategic patch do
+func toJSONMap(o any) (m strategicpatch.JSONMap, err error) {
+	buf := mergeBufferPool.Get().(*bytes.Buffer)
+	defer mergeBufferPool.Put(buf)
+	buf.Reset()
+
+	if err = json.NewEncoder(buf).Encode(o); err != nil {
+		return nil, err
+	}
+
+	m = strategicpatch.JSONMap{}
+	if err = json.Unmarshal(buf.Bytes(), &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
 // MergeSliceOrDie permit to merge some slice on dst
 // It avoid to set the same item based on key value
 func MergeSliceOrDie(dst *[]any, key string,  src ...[]any) {
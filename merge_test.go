@@ -79,4 +79,33 @@ func TestMergeSliceOrDie(t *testing.T) {
 	assert.Equal(t, expected, dst)
 
 
-}
\ No newline at end of file
+}
+func TestMergeK8s(t *testing.T) {
+	// Fields not set on new are keeped, lists items not set on new are removed
+	dst := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Image: "app:1.0", Env: []corev1.EnvVar{{Name: "A", Value: "1"}}},
+			{Name: "sidecar", Image: "envoy"},
+		},
+		NodeSelector: map[string]string{"disk": "ssd"},
+	}
+	assert.NoError(t, MergeK8s(dst, dst, &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Image: "app:2.0", Env: []corev1.EnvVar{{Name: "B", Value: "<2>"}}},
+		},
+	}))
+	assert.Equal(t, &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Name: "app", Image: "app:2.0", Env: []corev1.EnvVar{{Name: "B", Value: "<2>"}}},
+		},
+		NodeSelector: map[string]string{"disk": "ssd"},
+	}, dst)
+
+	// When dst is pointer of pointer
+	probe := &corev1.Probe{PeriodSeconds: 10, TimeoutSeconds: 5}
+	assert.NoError(t, MergeK8s(&probe, probe, &corev1.Probe{PeriodSeconds: 30}))
+	assert.Equal(t, &corev1.Probe{PeriodSeconds: 30, TimeoutSeconds: 5}, probe)
+
+	// When src is nil
+	assert.Error(t, MergeK8s(dst, nil, dst))
+}
@@ -0,0 +1,97 @@
+package k8sbuilder
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// LabelIstioInject is the label that enable or disable the Istio sidecar injection
+	LabelIstioInject = "sidecar.istio.io/inject"
+
+	// AnnotationIstioExcludeInboundPorts is the annotation to not redirect inbound ports to the Istio sidecar
+	AnnotationIstioExcludeInboundPorts = "traffic.sidecar.istio.io/excludeInboundPorts"
+
+	// AnnotationIstioExcludeOutboundPorts is the annotation to not redirect outbound ports to the Istio sidecar
+	AnnotationIstioExcludeOutboundPorts = "traffic.sidecar.istio.io/excludeOutboundPorts"
+
+	// AnnotationIstioExcludeOutboundIPRanges is the annotation to not redirect outbound IP ranges to the Istio sidecar
+	AnnotationIstioExcludeOutboundIPRanges = "traffic.sidecar.istio.io/excludeOutboundIPRanges"
+
+	// AnnotationLinkerdInject is the annotation that enable or disable the Linkerd proxy injection
+	AnnotationLinkerdInject = "linkerd.io/inject"
+)
+
+// istioAnnotationPrefixes is the prefixes of annotations that configure the Istio sidecar
+var istioAnnotationPrefixes = []string{"sidecar.istio.io/", "traffic.sidecar.istio.io/", "proxy.istio.io/"}
+
+// linkerdAnnotationPrefixes is the prefixes of annotations that configure the Linkerd proxy
+var linkerdAnnotationPrefixes = []string{"config.linkerd.io/", "config.alpha.linkerd.io/"}
+
+// IstioExcludedPorts permit to get the value of Istio traffic exclusion annotations from ports, like 5432,6379
+func IstioExcludedPorts(ports ...int32) string {
+	values := make([]string, 0, len(ports))
+	for _, port := range ports {
+		values = append(values, strconv.Itoa(int(port)))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// WithIstioInjection permit to enable or disable the Istio sidecar injection
+// When enabled, overrides are merged on annotations, like the traffic exclusion annotations.
+// When disabled, all Istio sidecar annotations are removed and overrides are ignored.
+func (h *PodTemplateBuilderDefault) WithIstioInjection(enabled bool, overrides map[string]string) PodTemplateBuilder {
+	defer h.observe("WithIstioInjection")()
+
+	h.own(sharedLabels | sharedAnnotations)
+	if h.podTemplate.Labels == nil {
+		h.podTemplate.Labels = map[string]string{}
+	}
+	h.podTemplate.Labels[LabelIstioInject] = strconv.FormatBool(enabled)
+
+	if !enabled {
+		h.podTemplate.Annotations = withoutKeys(h.podTemplate.Annotations, annotationKeysWithPrefix(h.podTemplate.Annotations, istioAnnotationPrefixes...))
+		return h
+	}
+
+	if len(overrides) > 0 && h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	for key, value := range overrides {
+		h.podTemplate.Annotations[key] = value
+	}
+
+	return h
+}
+
+// WithLinkerdInjection permit to enable or disable the Linkerd proxy injection
+// When disabled, all Linkerd proxy config annotations are removed.
+func (h *PodTemplateBuilderDefault) WithLinkerdInjection(enabled bool) PodTemplateBuilder {
+	defer h.observe("WithLinkerdInjection")()
+
+	h.own(sharedAnnotations)
+	if !enabled {
+		h.podTemplate.Annotations = withoutKeys(h.podTemplate.Annotations, annotationKeysWithPrefix(h.podTemplate.Annotations, linkerdAnnotationPrefixes...))
+	}
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	if enabled {
+		h.podTemplate.Annotations[AnnotationLinkerdInject] = "enabled"
+	} else {
+		h.podTemplate.Annotations[AnnotationLinkerdInject] = "disabled"
+	}
+
+	return h
+}
+
+// annotationKeysWithPrefix permit to get the keys that start with one of prefixes
+func annotationKeysWithPrefix(annotations map[string]string, prefixes ...string) []string {
+	keys := make([]string, 0)
+	for key := range FilterAnnotations(annotations, prefixes...) {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
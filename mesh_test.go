@@ -0,0 +1,46 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPodTemplateWithIstioInjection(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test"}).
+		WithAnnotations(map[string]string{"foo": "bar"}).
+		WithIstioInjection(true, map[string]string{
+			AnnotationIstioExcludeOutboundPorts: IstioExcludedPorts(5432, 6379),
+			"sidecar.istio.io/proxyCPU":         "100m",
+		})
+	pts, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test", "sidecar.istio.io/inject": "true"}, pts.Labels)
+	assert.Equal(t, map[string]string{
+		"foo": "bar",
+		"traffic.sidecar.istio.io/excludeOutboundPorts": "5432,6379",
+		"sidecar.istio.io/proxyCPU":                     "100m",
+	}, pts.Annotations)
+
+	// Disable on other layer
+	pts, err = b.WithIstioInjection(false, map[string]string{"sidecar.istio.io/proxyMemory": "128Mi"}).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test", "sidecar.istio.io/inject": "false"}, pts.Labels)
+	assert.Equal(t, map[string]string{"foo": "bar"}, pts.Annotations)
+}
+
+func TestPodTemplateWithLinkerdInjection(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithAnnotations(map[string]string{"config.linkerd.io/proxy-cpu-request": "100m"}).
+		WithLinkerdInjection(true)
+	pts, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"config.linkerd.io/proxy-cpu-request": "100m", "linkerd.io/inject": "enabled"}, pts.Annotations)
+
+	pts, err = b.WithLinkerdInjection(false).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"linkerd.io/inject": "disabled"}, pts.Annotations)
+}
@@ -0,0 +1,298 @@
+package k8sbuilder
+
+import (
+	"strings"
+
+	"github.com/thoas/go-funk"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ValidateMetadata permit to check the syntax of labels and annotations before send object to API server
+// Keys must be qualified names with an optional DNS-1123 prefix, label values must be valid label values,
+// and the total size of annotations must be lower than 256 KB.
+func ValidateMetadata(o metav1.Object) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+	if o == nil {
+		return errs
+	}
+
+	return append(errs, validateObjectMetaLabels(o.GetLabels(), o.GetAnnotations(), field.NewPath("metadata"))...)
+}
+
+// validateObjectMetaLabels permit to check the syntax of labels and annotations
+func validateObjectMetaLabels(labels, annotations map[string]string, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+	errs = append(errs, metav1validation.ValidateLabels(labels, fldPath.Child("labels"))...)
+	errs = append(errs, apimachineryvalidation.ValidateAnnotations(annotations, fldPath.Child("annotations"))...)
+
+	return errs
+}
+
+// PropagateMetadata permit to apply common namespace, labels and annotations on all objects with Merge semantic
+// Empty namespace is not propagated, and it's not set on cluster scoped objects, like ClusterRole or ClusterRoleBinding.
+// Labels or annotations already set on object are keeped.
+// Labels and annotations of objects are copied before merge, because they can be shared with the caller or between objects.
+// Use BuilderSet.WithMetadata to propagate them on all objects of set.
+func PropagateMetadata(objects []client.Object, namespace string, labels, annotations map[string]string) {
+	for _, o := range objects {
+		if o == nil {
+			continue
+		}
+
+		if namespace != "" && !IsClusterScoped(o) {
+			o.SetNamespace(namespace)
+		}
+		if len(labels) > 0 {
+			o.SetLabels(mergeMap(mergeMap(nil, o.GetLabels()), labels))
+		}
+		if len(annotations) > 0 {
+			o.SetAnnotations(mergeMap(mergeMap(nil, o.GetAnnotations()), annotations))
+		}
+	}
+}
+
+// clusterScopedKinds is the list of known cluster scoped kinds
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:                                                  true,
+	{Group: "", Kind: "Node"}:                                                       true,
+	{Group: "", Kind: "PersistentVolume"}:                                           true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "CSINode"}:                                      true,
+	{Group: "storage.k8s.io", Kind: "VolumeAttachment"}:                             true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "node.k8s.io", Kind: "RuntimeClass"}:                                    true,
+	{Group: "networking.k8s.io", Kind: "IngressClass"}:                              true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "certificates.k8s.io", Kind: "CertificateSigningRequest"}:               true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "FlowSchema"}:                     true,
+	{Group: "flowcontrol.apiserver.k8s.io", Kind: "PriorityLevelConfiguration"}:     true,
+	{Group: "policy", Kind: "PodSecurityPolicy"}:                                    true,
+}
+
+// IsClusterScoped permit to know if object is one of the known cluster scoped kinds, like ClusterRole
+// The kind is read from the type meta, or from the client-go scheme when the type meta is not set.
+func IsClusterScoped(o client.Object) bool {
+	gvk := o.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		var err error
+		if gvk, err = apiutil.GVKForObject(o, scheme.Scheme); err != nil {
+			return false
+		}
+	}
+
+	return clusterScopedKinds[gvk.GroupKind()]
+}
+
+// FilterAnnotations permit to keep only the annotations that start with one of prefixes, like "prometheus.io/"
+// It return nil if no annotation match, so it can be used with Merge option without effect.
+func FilterAnnotations(annotations map[string]string, prefixes ...string) map[string]string {
+	var filtered map[string]string
+	for key, value := range annotations {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				if filtered == nil {
+					filtered = map[string]string{}
+				}
+				filtered[key] = value
+				break
+			}
+		}
+	}
+
+	return filtered
+}
+
+// CopyAnnotationsFrom permit to get the annotations of object, like the custom resource, that start with one of prefixes
+// Use it to propagate selected annotation namespaces on built objects:
+// WithAnnotations(CopyAnnotationsFrom(cr, "prometheus.io/", "sidecar.istio.io/"), Merge)
+func CopyAnnotationsFrom(o metav1.Object, prefixes ...string) map[string]string {
+	if o == nil {
+		return nil
+	}
+
+	return FilterAnnotations(o.GetAnnotations(), prefixes...)
+}
+
+// mergeMap permit to add keys from src not yet on dst
+func mergeMap(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for key, value := range src {
+		if _, ok := dst[key]; !ok {
+			dst[key] = value
+		}
+	}
+
+	return dst
+}
+
+// copyKeys permit to copy the selected keys from src to dst
+// Keys not on src are ignored
+func copyKeys(dst, src map[string]string, keys []string) map[string]string {
+	for _, key := range keys {
+		value, ok := src[key]
+		if !ok {
+			continue
+		}
+		if dst == nil {
+			dst = map[string]string{}
+		}
+		dst[key] = value
+	}
+
+	return dst
+}
+
+// withoutKeys permit to remove keys from map
+// The map is copied before remove keys, because it can be shared with the caller
+func withoutKeys(m map[string]string, keys []string) map[string]string {
+	found := false
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return m
+	}
+
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	for _, key := range keys {
+		delete(copied, key)
+	}
+
+	return copied
+}
+
+// withFinalizer permit to add finalizer if not already set
+// The slice is copied before add finalizer, because it can be shared with the caller
+func withFinalizer(finalizers []string, name string) []string {
+	if funk.ContainsString(finalizers, name) {
+		return finalizers
+	}
+
+	return append(append(make([]string, 0, len(finalizers)+1), finalizers...), name)
+}
+
+// withoutFinalizer permit to remove finalizer if set
+// The slice is copied before remove finalizer, because it can be shared with the caller
+func withoutFinalizer(finalizers []string, name string) []string {
+	if !funk.ContainsString(finalizers, name) {
+		return finalizers
+	}
+
+	copied := make([]string, 0, len(finalizers)-1)
+	for _, finalizer := range finalizers {
+		if finalizer != name {
+			copied = append(copied, finalizer)
+		}
+	}
+
+	return copied
+}
+
+// objectMeta permit to share the metadata setters between object builders
+// Builders embed it with the metadata of the built object, and wrap its methods to keep their fluent interface.
+type objectMeta struct {
+	meta *metav1.ObjectMeta
+}
+
+// newObjectMeta permit to init the metadata setters of object
+func newObjectMeta(o metav1.ObjectMetaAccessor) objectMeta {
+	return objectMeta{meta: o.GetObjectMeta().(*metav1.ObjectMeta)}
+}
+
+// setName permit to set name
+func (h objectMeta) setName(name string, opts []WithOption) {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.meta.Name == "" {
+		h.meta.Name = name
+	}
+}
+
+// setNamespace permit to set namespace
+func (h objectMeta) setNamespace(namespace string, opts []WithOption) {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.meta.Namespace == "" {
+		h.meta.Namespace = namespace
+	}
+}
+
+// setLabels permit to set labels
+func (h objectMeta) setLabels(labels map[string]string, opts []WithOption) {
+	h.meta.Labels = withMap(h.meta.Labels, labels, opts)
+}
+
+// removeLabels permit to remove labels
+func (h objectMeta) removeLabels(keys []string) {
+	h.meta.Labels = withoutKeys(h.meta.Labels, keys)
+}
+
+// setAnnotations permit to set annotations
+func (h objectMeta) setAnnotations(annotations map[string]string, opts []WithOption) {
+	h.meta.Annotations = withMap(h.meta.Annotations, annotations, opts)
+}
+
+// removeAnnotations permit to remove annotations
+func (h objectMeta) removeAnnotations(keys []string) {
+	h.meta.Annotations = withoutKeys(h.meta.Annotations, keys)
+}
+
+// addFinalizer permit to add finalizer, if not already set
+func (h objectMeta) addFinalizer(name string) {
+	h.meta.Finalizers = withFinalizer(h.meta.Finalizers, name)
+}
+
+// removeFinalizer permit to remove finalizer, if set
+func (h objectMeta) removeFinalizer(name string) {
+	h.meta.Finalizers = withoutFinalizer(h.meta.Finalizers, name)
+}
+
+// setOwner permit to set owner reference, or controller reference if controller is true
+func (h objectMeta) setOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) (err error) {
+	return SetOwner(h.meta, owner, scheme, controller)
+}
+
+// withMap permit to set map, like labels or annotations, according to the option
+// The map is copied before it's stored or merged, because it can be shared with the caller.
+func withMap(current, m map[string]string, opts []WithOption) map[string]string {
+	// Overwrite
+	if IsOverwrite(opts) || current == nil {
+		return copyMap(m)
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(current) == 0 {
+		return copyMap(m)
+	}
+
+	// Merge
+	if IsMerge(opts) && m != nil {
+		return mergeMap(copyMap(current), m)
+	}
+
+	return current
+}
@@ -0,0 +1,264 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestPropagateMetadata(t *testing.T) {
+	sharedLabels := map[string]string{"app": "test"}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cm",
+			Namespace:   "other",
+			Labels:      sharedLabels,
+			Annotations: map[string]string{"team": "dev"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "secret",
+			Labels: sharedLabels,
+		},
+	}
+
+	clusterRole := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "cluster-role"}}
+	clusterRoleBinding := &unstructured.Unstructured{}
+	clusterRoleBinding.SetAPIVersion("rbac.authorization.k8s.io/v1")
+	clusterRoleBinding.SetKind("ClusterRoleBinding")
+	clusterRoleBinding.SetName("cluster-role-binding")
+
+	PropagateMetadata([]client.Object{cm, nil, secret, clusterRole, clusterRoleBinding}, "default",
+		map[string]string{"app": "override", "env": "prod"},
+		map[string]string{"team": "ops", "owner": "me"},
+	)
+
+	// Namespace is propagated
+	assert.Equal(t, "default", cm.Namespace)
+	assert.Equal(t, "default", secret.Namespace)
+
+	// Namespace is not set on cluster scoped objects, but labels are
+	assert.Empty(t, clusterRole.Namespace)
+	assert.Equal(t, map[string]string{"app": "override", "env": "prod"}, clusterRole.Labels)
+	assert.Empty(t, clusterRoleBinding.GetNamespace())
+
+	// Keys already set are keeped
+	assert.Equal(t, map[string]string{"app": "test", "env": "prod"}, cm.Labels)
+	assert.Equal(t, map[string]string{"team": "dev", "owner": "me"}, cm.Annotations)
+	assert.Equal(t, map[string]string{"app": "test", "env": "prod"}, secret.Labels)
+	assert.Equal(t, map[string]string{"team": "ops", "owner": "me"}, secret.Annotations)
+
+	// Shared maps are not modified
+	assert.Equal(t, map[string]string{"app": "test"}, sharedLabels)
+
+	// Empty namespace and metadata keep objects as is
+	PropagateMetadata([]client.Object{cm}, "", nil, nil)
+	assert.Equal(t, "default", cm.Namespace)
+	assert.Equal(t, map[string]string{"app": "test", "env": "prod"}, cm.Labels)
+}
+
+func TestFilterAnnotations(t *testing.T) {
+	annotations := map[string]string{
+		"prometheus.io/scrape":               "true",
+		"prometheus.io/port":                 "8080",
+		"sidecar.istio.io/inject":            "false",
+		"kubectl.kubernetes.io/last-applied": "{}",
+	}
+
+	assert.Equal(t, map[string]string{
+		"prometheus.io/scrape":    "true",
+		"prometheus.io/port":      "8080",
+		"sidecar.istio.io/inject": "false",
+	}, FilterAnnotations(annotations, "prometheus.io/", "sidecar.istio.io/"))
+	assert.Nil(t, FilterAnnotations(annotations, "example.com/"))
+	assert.Nil(t, FilterAnnotations(annotations))
+	assert.Nil(t, FilterAnnotations(nil, "prometheus.io/"))
+}
+
+func TestCopyAnnotationsFrom(t *testing.T) {
+	cr := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"prometheus.io/scrape": "true",
+				"other":                "value",
+			},
+		},
+	}
+
+	cm, err := NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithAnnotations(map[string]string{"foo": "bar"}).
+		WithAnnotations(CopyAnnotationsFrom(cr, "prometheus.io/"), Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"foo": "bar", "prometheus.io/scrape": "true"}, cm.Annotations)
+
+	assert.Nil(t, CopyAnnotationsFrom(nil, "prometheus.io/"))
+}
+
+func TestValidateMetadata(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			Labels: map[string]string{
+				"app.kubernetes.io/name": "test",
+			},
+			Annotations: map[string]string{
+				"example.com/description": "Any value is allowed on annotations",
+			},
+		},
+	}
+
+	// When valid
+	assert.Empty(t, ValidateMetadata(cm))
+
+	// When invalid
+	cm.Labels = map[string]string{
+		"Example_.com/name": "test",
+		"version":           "1.0 beta",
+	}
+	cm.Annotations = map[string]string{
+		"-invalid": "test",
+	}
+	errs := ValidateMetadata(cm)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"metadata.labels",
+		"metadata.labels",
+		"metadata.annotations",
+	}, fields)
+
+	// Build return the errors
+	_, err := NewConfigMapBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithLabels(map[string]string{"version": "1.0 beta"}).
+		Build()
+	assert.ErrorContains(t, err, "metadata.labels")
+}
+
+func TestWithMap(t *testing.T) {
+	current := map[string]string{"app": "test"}
+	m := map[string]string{"app": "override", "env": "prod"}
+
+	// Overwrite copy the map
+	result := withMap(current, m, []WithOption{Overwrite})
+	assert.Equal(t, m, result)
+	result["team"] = "ops"
+	assert.Equal(t, map[string]string{"app": "override", "env": "prod"}, m)
+
+	// Nil current map copy the map
+	result = withMap(nil, m, nil)
+	assert.Equal(t, m, result)
+	result["team"] = "ops"
+	assert.Equal(t, map[string]string{"app": "override", "env": "prod"}, m)
+
+	// Overwrite only if default
+	assert.Equal(t, current, withMap(current, m, []WithOption{OverwriteIfDefaultValue}))
+	assert.Equal(t, m, withMap(map[string]string{}, m, []WithOption{OverwriteIfDefaultValue}))
+
+	// Merge keep the current keys and don't modify the current map
+	result = withMap(current, m, []WithOption{Merge})
+	assert.Equal(t, map[string]string{"app": "test", "env": "prod"}, result)
+	assert.Equal(t, map[string]string{"app": "test"}, current)
+}
+
+func TestBuildersCopyCallerMaps(t *testing.T) {
+	testCases := map[string]func(m map[string]string){
+		"deployment": func(m map[string]string) {
+			NewDeploymentBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"statefulSet": func(m map[string]string) {
+			NewStatefulSetBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"job": func(m map[string]string) {
+			NewJobBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"cronJob": func(m map[string]string) {
+			NewCronJobBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"service": func(m map[string]string) {
+			NewServiceBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge).
+				WithSelector(m, Overwrite).WithSelector(map[string]string{"extra": "value"}, Merge)
+		},
+		"configMap": func(m map[string]string) {
+			NewConfigMapBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"secret": func(m map[string]string) {
+			NewSecretBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"role": func(m map[string]string) {
+			NewRoleBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"clusterRole": func(m map[string]string) {
+			NewClusterRoleBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"roleBinding": func(m map[string]string) {
+			NewRoleBindingBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"clusterRoleBinding": func(m map[string]string) {
+			NewClusterRoleBindingBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"hpa": func(m map[string]string) {
+			NewHorizontalPodAutoscalerBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"pdb": func(m map[string]string) {
+			NewPodDisruptionBudgetBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge)
+		},
+		"networkPolicy": func(m map[string]string) {
+			NewNetworkPolicyBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge)
+		},
+		"workload": func(m map[string]string) {
+			NewWorkloadBuilder(WorkloadDeployment).WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge)
+		},
+		"ingress": func(m map[string]string) {
+			_, _ = NewIngressBuilder().WithLabels(m, Overwrite).WithLabels(map[string]string{"extra": "value"}, Merge).
+				WithAnnotations(m, Overwrite).WithAnnotations(map[string]string{"extra": "value"}, Merge).
+				Build()
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := map[string]string{"app": "test"}
+			testCase(m)
+			assert.Equal(t, map[string]string{"app": "test"}, m)
+		})
+	}
+}
+
+func TestIsClusterScoped(t *testing.T) {
+	assert.True(t, IsClusterScoped(&rbacv1.ClusterRole{}))
+	assert.True(t, IsClusterScoped(&rbacv1.ClusterRoleBinding{}))
+	assert.True(t, IsClusterScoped(&corev1.Namespace{}))
+	assert.False(t, IsClusterScoped(&rbacv1.Role{}))
+	assert.False(t, IsClusterScoped(&corev1.ConfigMap{}))
+
+	// Unknown kind is namespaced
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion("example.com/v1")
+	o.SetKind("ClusterRole")
+	assert.False(t, IsClusterScoped(o))
+}
@@ -0,0 +1,114 @@
+package k8sbuilder
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is the prometheus metrics of builders
+// Metrics are disabled until EnableMetrics is called
+type Metrics struct {
+	MergeTotal    *prometheus.CounterVec
+	MergeFailures *prometheus.CounterVec
+	BuildDuration *prometheus.HistogramVec
+
+	PatchMetaCacheHits   prometheus.Counter
+	PatchMetaCacheMisses prometheus.Counter
+}
+
+var metrics atomic.Pointer[Metrics]
+
+// NewMetrics permit to init metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		MergeTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sbuilder_merge_total",
+			Help: "Total number of merge operations",
+		}, []string{"function"}),
+		MergeFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "k8sbuilder_merge_failures_total",
+			Help: "Total number of failed merge operations",
+		}, []string{"function"}),
+		BuildDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "k8sbuilder_build_duration_seconds",
+			Help:    "Duration of Build calls",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}, []string{"builder"}),
+		PatchMetaCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "k8sbuilder_patch_meta_cache_hits_total",
+			Help: "Total number of patch metadata lookups served by the cache",
+		}),
+		PatchMetaCacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "k8sbuilder_patch_meta_cache_misses_total",
+			Help: "Total number of patch metadata lookups computed from struct tags",
+		}),
+	}
+}
+
+// EnableMetrics permit to register the builder metrics on registry and start to collect them
+// Use the controller-runtime registry to expose them with the controller metrics:
+// k8sbuilder.EnableMetrics(metrics.Registry)
+func EnableMetrics(registry prometheus.Registerer) error {
+	m := NewMetrics()
+	for _, collector := range []prometheus.Collector{m.MergeTotal, m.MergeFailures, m.BuildDuration, m.PatchMetaCacheHits, m.PatchMetaCacheMisses} {
+		if err := registry.Register(collector); err != nil {
+			return errors.Wrap(err, "Error when register metrics")
+		}
+	}
+	metrics.Store(m)
+
+	return nil
+}
+
+// DisableMetrics permit to stop to collect metrics
+// Metrics are not unregistered
+func DisableMetrics() {
+	metrics.Store(nil)
+}
+
+// observeMerge permit to count the merge operation and its failure
+func observeMerge(function string, err error) {
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+
+	m.MergeTotal.WithLabelValues(function).Inc()
+	if err != nil {
+		m.MergeFailures.WithLabelValues(function).Inc()
+	}
+}
+
+// observeBuild permit to observe the Build duration
+// Use it with defer: defer observeBuild("Deployment", time.Now())
+func observeBuild(builder string, start time.Time) {
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+
+	m.BuildDuration.WithLabelValues(builder).Observe(time.Since(start).Seconds())
+}
+
+// observePatchMetaCache permit to count the patch metadata cache hit or miss
+func observePatchMetaCache(hit bool) {
+	if hit {
+		patchMetaCacheHits.Add(1)
+	} else {
+		patchMetaCacheMisses.Add(1)
+	}
+
+	m := metrics.Load()
+	if m == nil {
+		return
+	}
+
+	if hit {
+		m.PatchMetaCacheHits.Inc()
+	} else {
+		m.PatchMetaCacheMisses.Inc()
+	}
+}
@@ -0,0 +1,48 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, EnableMetrics(registry))
+	defer DisableMetrics()
+
+	// Can't be registered twice
+	assert.Error(t, EnableMetrics(registry))
+
+	m := metrics.Load()
+
+	_, err := NewPodTemplateBuilder().WithDefaults(&Defaults{}).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.BuildDuration, "k8sbuilder_build_duration_seconds"))
+
+	dst := &corev1.Pod{}
+	assert.NoError(t, MergeK8s(dst, &corev1.Pod{}, &corev1.Pod{}))
+	assert.Error(t, MergeK8s(dst, nil, &corev1.Pod{}))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.MergeTotal.WithLabelValues("MergeK8s")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.MergeFailures.WithLabelValues("MergeK8s")))
+
+	// When disabled
+	DisableMetrics()
+	assert.NoError(t, MergeK8s(dst, &corev1.Pod{}, &corev1.Pod{}))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.MergeTotal.WithLabelValues("MergeK8s")))
+}
+
+func TestMetricsPatchMetaCache(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, EnableMetrics(registry))
+	defer DisableMetrics()
+
+	m := metrics.Load()
+
+	assert.NoError(t, MergeK8s(&corev1.Service{}, &corev1.Service{}, &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}}}))
+	assert.NoError(t, MergeK8s(&corev1.Service{}, &corev1.Service{}, &corev1.Service{Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 80}}}}))
+	assert.Greater(t, testutil.ToFloat64(m.PatchMetaCacheHits), float64(0))
+}
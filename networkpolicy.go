@@ -0,0 +1,325 @@
+package k8sbuilder
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// LabelNamespaceName is the label set by kubernetes on each namespace with its name
+	LabelNamespaceName = "kubernetes.io/metadata.name"
+)
+
+// NetworkPolicyBuilder is the network policy builder interface
+type NetworkPolicyBuilder interface {
+	WithName(name string, opts ...WithOption) NetworkPolicyBuilder
+	WithNamespace(namespace string, opts ...WithOption) NetworkPolicyBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) NetworkPolicyBuilder
+	WithoutLabels(keys ...string) NetworkPolicyBuilder
+	WithFinalizer(name string) NetworkPolicyBuilder
+	WithoutFinalizer(name string) NetworkPolicyBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) NetworkPolicyBuilder
+	WithPodSelector(selector metav1.LabelSelector) NetworkPolicyBuilder
+	WithIngressRules(rules []networkingv1.NetworkPolicyIngressRule, opts ...WithOption) NetworkPolicyBuilder
+	WithEgressRules(rules []networkingv1.NetworkPolicyEgressRule, opts ...WithOption) NetworkPolicyBuilder
+	DenyAllIngress() NetworkPolicyBuilder
+	DenyAllEgress() NetworkPolicyBuilder
+	AllowSameNamespace() NetworkPolicyBuilder
+	AllowFrom(labels map[string]string, ports ...networkingv1.NetworkPolicyPort) NetworkPolicyBuilder
+	AllowFromNamespace(namespace string, ports ...networkingv1.NetworkPolicyPort) NetworkPolicyBuilder
+	AllowDNSEgress() NetworkPolicyBuilder
+	WithDefaults(defaults *Defaults) NetworkPolicyBuilder
+	NetworkPolicy() *networkingv1.NetworkPolicy
+	Build() (np *networkingv1.NetworkPolicy, err error)
+}
+
+// NetworkPolicyBuilderDefault is the default implementation of network policy builder
+type NetworkPolicyBuilderDefault struct {
+	objectMeta
+	networkPolicy *networkingv1.NetworkPolicy
+	defaults      *Defaults
+	err           error
+}
+
+// NewNetworkPolicyBuilder permit to init network policy builder
+// By default, the policy select all pods of the namespace
+func NewNetworkPolicyBuilder() NetworkPolicyBuilder {
+	networkPolicy := &networkingv1.NetworkPolicy{}
+
+	return &NetworkPolicyBuilderDefault{
+		objectMeta:    newObjectMeta(networkPolicy),
+		networkPolicy: networkPolicy,
+	}
+}
+
+// NetworkPolicyPort permit to get network policy port
+func NetworkPolicyPort(protocol corev1.Protocol, port int) networkingv1.NetworkPolicyPort {
+	p := intstr.FromInt(port)
+
+	return networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &p,
+	}
+}
+
+// NetworkPolicy permit to get current network policy
+func (h *NetworkPolicyBuilderDefault) NetworkPolicy() *networkingv1.NetworkPolicy {
+	return h.networkPolicy
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *NetworkPolicyBuilderDefault) WithDefaults(defaults *Defaults) NetworkPolicyBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the network policy
+func (h *NetworkPolicyBuilderDefault) Build() (np *networkingv1.NetworkPolicy, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.networkPolicy)
+
+	if errs := ValidateMetadata(h.networkPolicy); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.networkPolicy, nil
+}
+
+// WithName permit to set name
+func (h *NetworkPolicyBuilderDefault) WithName(name string, opts ...WithOption) NetworkPolicyBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *NetworkPolicyBuilderDefault) WithNamespace(namespace string, opts ...WithOption) NetworkPolicyBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *NetworkPolicyBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) NetworkPolicyBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *NetworkPolicyBuilderDefault) WithoutLabels(keys ...string) NetworkPolicyBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *NetworkPolicyBuilderDefault) WithFinalizer(name string) NetworkPolicyBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *NetworkPolicyBuilderDefault) WithoutFinalizer(name string) NetworkPolicyBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *NetworkPolicyBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) NetworkPolicyBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithPodSelector permit to set the pods selected by the policy
+func (h *NetworkPolicyBuilderDefault) WithPodSelector(selector metav1.LabelSelector) NetworkPolicyBuilder {
+	h.networkPolicy.Spec.PodSelector = selector
+
+	return h
+}
+
+// WithIngressRules permit to set ingress rules
+// On merge, rules already present are skipped
+func (h *NetworkPolicyBuilderDefault) WithIngressRules(rules []networkingv1.NetworkPolicyIngressRule, opts ...WithOption) NetworkPolicyBuilder {
+	h.withPolicyType(networkingv1.PolicyTypeIngress)
+
+	// Overwrite
+	if IsOverwrite(opts) || h.networkPolicy.Spec.Ingress == nil {
+		h.networkPolicy.Spec.Ingress = rules
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.networkPolicy.Spec.Ingress) == 0 {
+		h.networkPolicy.Spec.Ingress = rules
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+	loopRules:
+		for _, rule := range rules {
+			for _, current := range h.networkPolicy.Spec.Ingress {
+				if reflect.DeepEqual(current, rule) {
+					continue loopRules
+				}
+			}
+			h.networkPolicy.Spec.Ingress = append(h.networkPolicy.Spec.Ingress, rule)
+		}
+	}
+
+	return h
+}
+
+// WithEgressRules permit to set egress rules
+// On merge, rules already present are skipped
+func (h *NetworkPolicyBuilderDefault) WithEgressRules(rules []networkingv1.NetworkPolicyEgressRule, opts ...WithOption) NetworkPolicyBuilder {
+	h.withPolicyType(networkingv1.PolicyTypeEgress)
+
+	// Overwrite
+	if IsOverwrite(opts) || h.networkPolicy.Spec.Egress == nil {
+		h.networkPolicy.Spec.Egress = rules
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.networkPolicy.Spec.Egress) == 0 {
+		h.networkPolicy.Spec.Egress = rules
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+	loopRules:
+		for _, rule := range rules {
+			for _, current := range h.networkPolicy.Spec.Egress {
+				if reflect.DeepEqual(current, rule) {
+					continue loopRules
+				}
+			}
+			h.networkPolicy.Spec.Egress = append(h.networkPolicy.Spec.Egress, rule)
+		}
+	}
+
+	return h
+}
+
+// DenyAllIngress permit to deny all ingress traffic that is not allowed by an ingress rule
+func (h *NetworkPolicyBuilderDefault) DenyAllIngress() NetworkPolicyBuilder {
+	h.withPolicyType(networkingv1.PolicyTypeIngress)
+
+	return h
+}
+
+// DenyAllEgress permit to deny all egress traffic that is not allowed by an egress rule
+func (h *NetworkPolicyBuilderDefault) DenyAllEgress() NetworkPolicyBuilder {
+	h.withPolicyType(networkingv1.PolicyTypeEgress)
+
+	return h
+}
+
+// AllowSameNamespace permit to allow ingress traffic from all pods of the same namespace
+func (h *NetworkPolicyBuilderDefault) AllowSameNamespace() NetworkPolicyBuilder {
+	return h.WithIngressRules([]networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{},
+				},
+			},
+		},
+	}, Merge)
+}
+
+// AllowFrom permit to allow ingress traffic from the pods of the same namespace that match labels
+// If no ports is provided, all ports are allowed
+func (h *NetworkPolicyBuilderDefault) AllowFrom(labels map[string]string, ports ...networkingv1.NetworkPolicyPort) NetworkPolicyBuilder {
+	return h.WithIngressRules([]networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: mergeMap(nil, labels),
+					},
+				},
+			},
+			Ports: ports,
+		},
+	}, Merge)
+}
+
+// AllowFromNamespace permit to allow ingress traffic from all pods of another namespace
+// If no ports is provided, all ports are allowed
+func (h *NetworkPolicyBuilderDefault) AllowFromNamespace(namespace string, ports ...networkingv1.NetworkPolicyPort) NetworkPolicyBuilder {
+	return h.WithIngressRules([]networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							LabelNamespaceName: namespace,
+						},
+					},
+				},
+			},
+			Ports: ports,
+		},
+	}, Merge)
+}
+
+// AllowDNSEgress permit to allow DNS requests to the kube-dns pods of kube-system namespace
+// It's needed when egress traffic is denied
+func (h *NetworkPolicyBuilderDefault) AllowDNSEgress() NetworkPolicyBuilder {
+	return h.WithEgressRules([]networkingv1.NetworkPolicyEgressRule{
+		{
+			To: []networkingv1.NetworkPolicyPeer{
+				{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							LabelNamespaceName: metav1.NamespaceSystem,
+						},
+					},
+					PodSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"k8s-app": "kube-dns",
+						},
+					},
+				},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{
+				NetworkPolicyPort(corev1.ProtocolUDP, 53),
+				NetworkPolicyPort(corev1.ProtocolTCP, 53),
+			},
+		},
+	}, Merge)
+}
+
+// withPolicyType permit to add policy type if not yet present
+func (h *NetworkPolicyBuilderDefault) withPolicyType(policyType networkingv1.PolicyType) {
+	for _, current := range h.networkPolicy.Spec.PolicyTypes {
+		if current == policyType {
+			return
+		}
+	}
+	h.networkPolicy.Spec.PolicyTypes = append(h.networkPolicy.Spec.PolicyTypes, policyType)
+}
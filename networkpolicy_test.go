@@ -0,0 +1,57 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkPolicyBuilder(t *testing.T) {
+	np, err := NewNetworkPolicyBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithPodSelector(metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}).
+		DenyAllIngress().
+		DenyAllEgress().
+		AllowSameNamespace().
+		AllowFrom(map[string]string{"app": "front"}, NetworkPolicyPort(corev1.ProtocolTCP, 8080)).
+		AllowFromNamespace("monitoring").
+		AllowDNSEgress().
+		AllowDNSEgress().
+		AllowSameNamespace().
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, np.Spec.PolicyTypes)
+	assert.Equal(t, []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+		},
+		{
+			From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "front"}}}},
+			Ports: []networkingv1.NetworkPolicyPort{NetworkPolicyPort(corev1.ProtocolTCP, 8080)},
+		},
+		{
+			From: []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "monitoring"}}}},
+		},
+	}, np.Spec.Ingress)
+	assert.Len(t, np.Spec.Egress, 1)
+	assert.Equal(t, []networkingv1.NetworkPolicyPort{
+		NetworkPolicyPort(corev1.ProtocolUDP, 53),
+		NetworkPolicyPort(corev1.ProtocolTCP, 53),
+	}, np.Spec.Egress[0].Ports)
+
+	// Deny all ingress only
+	np, err = NewNetworkPolicyBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("deny-all").
+		DenyAllIngress().
+		DenyAllIngress().
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, np.Spec.PolicyTypes)
+	assert.Empty(t, np.Spec.Ingress)
+}
@@ -0,0 +1,65 @@
+package k8sbuilder
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NormalizePodTemplate permit to sort the lists of pod template that have no meaning order, so repeated builds are stable
+// Volumes are sorted by name, tolerations by key, operator, value and effect, and volume mounts by mount path.
+// Env are sorted by name, except if one env reference other env with $(VAR_NAME), because of they need to be defined before.
+// Map keys need no sort, because of they are always serialized in order.
+func NormalizePodTemplate(pts *corev1.PodTemplateSpec) {
+	if pts == nil {
+		return
+	}
+
+	sort.SliceStable(pts.Spec.Volumes, func(i, j int) bool {
+		return pts.Spec.Volumes[i].Name < pts.Spec.Volumes[j].Name
+	})
+	sort.SliceStable(pts.Spec.Tolerations, func(i, j int) bool {
+		return tolerationKey(pts.Spec.Tolerations[i]) < tolerationKey(pts.Spec.Tolerations[j])
+	})
+
+	for _, c := range allContainers(pts) {
+		sort.SliceStable(c.VolumeMounts, func(i, j int) bool {
+			if c.VolumeMounts[i].MountPath != c.VolumeMounts[j].MountPath {
+				return c.VolumeMounts[i].MountPath < c.VolumeMounts[j].MountPath
+			}
+			return c.VolumeMounts[i].Name < c.VolumeMounts[j].Name
+		})
+
+		if !hasEnvReference(c.Env) {
+			sort.SliceStable(c.Env, func(i, j int) bool {
+				return c.Env[i].Name < c.Env[j].Name
+			})
+		}
+	}
+}
+
+func tolerationKey(toleration corev1.Toleration) string {
+	return strings.Join([]string{toleration.Key, string(toleration.Operator), toleration.Value, string(toleration.Effect)}, "|")
+}
+
+// hasEnvReference permit to know if one env value reference other env, like $(VAR_NAME)
+func hasEnvReference(envs []corev1.EnvVar) bool {
+	for _, env := range envs {
+		if strings.Contains(env.Value, "$(") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithNormalization permit to normalize the pod template on Build, so the lists order not drift between builds
+// See NormalizePodTemplate.
+func (h *PodTemplateBuilderDefault) WithNormalization() PodTemplateBuilder {
+	defer h.observe("WithNormalization")()
+
+	h.normalize = true
+
+	return h
+}
@@ -0,0 +1,46 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNormalizePodTemplate(t *testing.T) {
+	volumes := []corev1.Volume{{Name: "data"}, {Name: "config"}}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithNormalization().
+		WithVolumes(volumes).
+		WithTolerations([]corev1.Toleration{
+			{Key: "spot", Operator: corev1.TolerationOpExists},
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "db"},
+		}).
+		WithContainers([]corev1.Container{
+			{
+				Name:         "app",
+				Env:          []corev1.EnvVar{{Name: "B", Value: "b"}, {Name: "A", Value: "a"}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/var/lib"}, {Name: "config", MountPath: "/etc/app"}},
+			},
+			{
+				Name: "sidecar",
+				Env:  []corev1.EnvVar{{Name: "URL", Value: "http://$(HOST)"}, {Name: "HOST", Value: "localhost"}},
+			},
+		}).
+		Build()
+	assert.NoError(t, err)
+
+	assert.Equal(t, []corev1.Volume{{Name: "config"}, {Name: "data"}}, pts.Spec.Volumes)
+	assert.Equal(t, "dedicated", pts.Spec.Tolerations[0].Key)
+	assert.Equal(t, []corev1.EnvVar{{Name: "A", Value: "a"}, {Name: "B", Value: "b"}}, pts.Spec.Containers[0].Env)
+	assert.Equal(t, "/etc/app", pts.Spec.Containers[0].VolumeMounts[0].MountPath)
+
+	// Env with reference keep their order
+	assert.Equal(t, "URL", pts.Spec.Containers[1].Env[0].Name)
+
+	// Caller slice is not modified
+	assert.Equal(t, "data", volumes[0].Name)
+
+	NormalizePodTemplate(nil)
+}
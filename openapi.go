@@ -0,0 +1,117 @@
+package k8sbuilder
+
+import (
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"k8s.io/kube-openapi/pkg/util/proto/validation"
+)
+
+const groupVersionKindExtensionKey = "x-kubernetes-group-version-kind"
+
+// OpenAPIValidator permit to validate objects against OpenAPI schema
+type OpenAPIValidator struct {
+	models map[schema.GroupVersionKind]proto.Schema
+}
+
+// NewOpenAPIValidator permit to get OpenAPI validator from the schema exposed by cluster
+func NewOpenAPIValidator(client discovery.OpenAPISchemaInterface) (*OpenAPIValidator, error) {
+	doc, err := client.OpenAPISchema()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when get OpenAPI schema from cluster")
+	}
+
+	return NewOpenAPIValidatorFromDocument(doc)
+}
+
+// NewOpenAPIValidatorFromDocument permit to get OpenAPI validator from OpenAPI document
+// It usefull to validate objects without cluster access. No schema is bundled on this package,
+// so the document must be provided by caller, for instance with openapi_v2.ParseDocument on the swagger.json of the target Kubernetes version.
+func NewOpenAPIValidatorFromDocument(doc *openapi_v2.Document) (*OpenAPIValidator, error) {
+	if doc == nil {
+		return nil, errors.New("OpenAPI document can't be nil")
+	}
+
+	models, err := proto.NewOpenAPIData(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when parse OpenAPI document")
+	}
+
+	v := &OpenAPIValidator{
+		models: map[schema.GroupVersionKind]proto.Schema{},
+	}
+	for _, name := range models.ListModels() {
+		model := models.LookupModel(name)
+		for _, gvk := range parseGroupVersionKind(model) {
+			v.models[gvk] = model
+		}
+	}
+
+	return v, nil
+}
+
+// Validate permit to validate object against OpenAPI schema
+// The object must have apiVersion and kind
+func (h *OpenAPIValidator) Validate(o runtime.Object) (err error) {
+	if o == nil {
+		return errors.New("Object can't be nil")
+	}
+
+	gvk := o.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		return errors.New("ApiVersion and kind must be set to validate object")
+	}
+
+	model, ok := h.models[gvk]
+	if !ok {
+		return errors.Errorf("No OpenAPI schema found for %s", gvk.String())
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return errors.Wrap(err, "Error when convert object to unstructured")
+	}
+
+	return utilerrors.NewAggregate(validation.ValidateModel(obj, model, gvk.Kind))
+}
+
+// parseGroupVersionKind permit to read the group version kind extension of model
+func parseGroupVersionKind(s proto.Schema) []schema.GroupVersionKind {
+	gvks := make([]schema.GroupVersionKind, 0)
+
+	gvkList, ok := s.GetExtensions()[groupVersionKindExtensionKey].([]any)
+	if !ok {
+		return gvks
+	}
+
+	for _, item := range gvkList {
+		gvkMap, ok := item.(map[any]any)
+		if !ok {
+			continue
+		}
+		group, ok := gvkMap["group"].(string)
+		if !ok {
+			continue
+		}
+		version, ok := gvkMap["version"].(string)
+		if !ok {
+			continue
+		}
+		kind, ok := gvkMap["kind"].(string)
+		if !ok {
+			continue
+		}
+
+		gvks = append(gvks, schema.GroupVersionKind{
+			Group:   group,
+			Version: version,
+			Kind:    kind,
+		})
+	}
+
+	return gvks
+}
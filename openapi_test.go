@@ -0,0 +1,67 @@
+package k8sbuilder
+
+import (
+	"os"
+	"testing"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOpenAPIValidator(t *testing.T) {
+	data, err := os.ReadFile("testdata/openapi/swagger.json")
+	assert.NoError(t, err)
+	doc, err := openapi_v2.ParseDocument(data)
+	assert.NoError(t, err)
+
+	validator, err := NewOpenAPIValidatorFromDocument(doc)
+	assert.NoError(t, err)
+
+	// Valid object
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Data: map[string]string{"key": "value"},
+	}
+	assert.NoError(t, validator.Validate(cm))
+
+	// Invalid object
+	invalid := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]any{
+				"name": "test",
+			},
+			"immutable": "yes",
+			"unknown":   "field",
+		},
+	}
+	err = validator.Validate(invalid)
+	assert.ErrorContains(t, err, "immutable")
+	assert.ErrorContains(t, err, "unknown")
+
+	// Without type meta
+	assert.ErrorContains(t, validator.Validate(&corev1.ConfigMap{}), "ApiVersion and kind must be set")
+
+	// Unknown kind
+	secret := &corev1.Secret{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}}
+	assert.ErrorContains(t, validator.Validate(secret), "No OpenAPI schema found for /v1, Kind=Secret")
+
+	// Nil object
+	assert.Error(t, validator.Validate(nil))
+
+	// Nil document
+	_, err = NewOpenAPIValidatorFromDocument(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SetOwner permit to set owner reference on object
+// If controller is true, it set the controller reference, so the owner will be reconciled when object change
+func SetOwner(o, owner metav1.Object, scheme *runtime.Scheme, controller bool) (err error) {
+	if owner == nil {
+		return errors.New("Owner can't be nil")
+	}
+	if scheme == nil {
+		return errors.New("Scheme can't be nil")
+	}
+
+	if controller {
+		if err = controllerutil.SetControllerReference(owner, o, scheme); err != nil {
+			return errors.Wrap(err, "Error when set controller reference")
+		}
+		return nil
+	}
+
+	if err = controllerutil.SetOwnerReference(owner, o, scheme); err != nil {
+		return errors.Wrap(err, "Error when set owner reference")
+	}
+
+	return nil
+}
+
+// OwnerReferenceBuilder is the fluent builder of owner reference, for exemple:
+// NewOwnerReferenceBuilder(cr, scheme).AsController().WithBlockOwnerDeletion(true).Build()
+type OwnerReferenceBuilder interface {
+	AsController() OwnerReferenceBuilder
+	WithBlockOwnerDeletion(block bool) OwnerReferenceBuilder
+	Build() (ownerReference *metav1.OwnerReference, err error)
+}
+
+// OwnerReferenceBuilderDefault is the default implementation of owner reference builder
+type OwnerReferenceBuilderDefault struct {
+	owner              client.Object
+	scheme             *runtime.Scheme
+	controller         bool
+	blockOwnerDeletion *bool
+}
+
+// NewOwnerReferenceBuilder permit to init owner reference builder of owner
+// The scheme is used to get the apiVersion and kind of owner.
+func NewOwnerReferenceBuilder(owner client.Object, scheme *runtime.Scheme) OwnerReferenceBuilder {
+	return &OwnerReferenceBuilderDefault{
+		owner:  owner,
+		scheme: scheme,
+	}
+}
+
+// AsController permit to set the controller flag, so the owner is reconciled when object change
+// BlockOwnerDeletion is set to true, like controller-runtime do, except if it's set explicitly.
+func (h *OwnerReferenceBuilderDefault) AsController() OwnerReferenceBuilder {
+	h.controller = true
+
+	return h
+}
+
+// WithBlockOwnerDeletion permit to set if the owner can be deleted before this object with foreground deletion
+func (h *OwnerReferenceBuilderDefault) WithBlockOwnerDeletion(block bool) OwnerReferenceBuilder {
+	h.blockOwnerDeletion = pointer.Bool(block)
+
+	return h
+}
+
+// Build permit to get the owner reference
+// It fail if owner has no UID, because of the reference is resolved by UID.
+func (h *OwnerReferenceBuilderDefault) Build() (ownerReference *metav1.OwnerReference, err error) {
+	if h.owner == nil {
+		return nil, errors.New("Owner can't be nil")
+	}
+	if h.scheme == nil {
+		return nil, errors.New("Scheme can't be nil")
+	}
+	if h.owner.GetUID() == "" {
+		return nil, errors.Errorf("Owner %s has no UID", h.owner.GetName())
+	}
+
+	gvk, err := apiutil.GVKForObject(h.owner, h.scheme)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when get apiVersion and kind of owner")
+	}
+
+	ownerReference = &metav1.OwnerReference{
+		APIVersion:         gvk.GroupVersion().String(),
+		Kind:               gvk.Kind,
+		Name:               h.owner.GetName(),
+		UID:                h.owner.GetUID(),
+		BlockOwnerDeletion: h.blockOwnerDeletion,
+	}
+	if h.controller {
+		ownerReference.Controller = pointer.Bool(true)
+		if ownerReference.BlockOwnerDeletion == nil {
+			ownerReference.BlockOwnerDeletion = pointer.Bool(true)
+		}
+	}
+
+	return ownerReference, nil
+}
+
+// MergeOwnerReferences permit to add owner references on existing ones, merged by UID
+// A reference on owner with the same group, kind and name but other UID is stale, because of the owner was recreated, so it's replaced.
+// It fail if it add a controller reference when other owner is already the controller, because of only one controller is allowed.
+func MergeOwnerReferences(existing []metav1.OwnerReference, ownerReferences ...metav1.OwnerReference) (merged []metav1.OwnerReference, err error) {
+	merged = make([]metav1.OwnerReference, 0, len(existing)+len(ownerReferences))
+	for _, ownerReference := range existing {
+		merged = append(merged, *ownerReference.DeepCopy())
+	}
+
+	for _, ownerReference := range ownerReferences {
+		index := -1
+		for i, o := range merged {
+			if o.UID == ownerReference.UID || isSameOwner(o, ownerReference) {
+				index = i
+				break
+			}
+		}
+
+		if ownerReference.Controller != nil && *ownerReference.Controller {
+			for i, o := range merged {
+				if i != index && o.Controller != nil && *o.Controller {
+					return nil, errors.Errorf("Object is already controlled by %s %s", o.Kind, o.Name)
+				}
+			}
+		}
+
+		if index == -1 {
+			merged = append(merged, *ownerReference.DeepCopy())
+		} else {
+			merged[index] = *ownerReference.DeepCopy()
+		}
+	}
+
+	return merged, nil
+}
+
+// isSameOwner permit to know if references target the same owner, by group, kind and name
+func isSameOwner(a, b metav1.OwnerReference) bool {
+	aGV, err := schema.ParseGroupVersion(a.APIVersion)
+	if err != nil {
+		return false
+	}
+	bGV, err := schema.ParseGroupVersion(b.APIVersion)
+	if err != nil {
+		return false
+	}
+
+	return aGV.Group == bGV.Group && a.Kind == b.Kind && a.Name == b.Name
+}
@@ -0,0 +1,187 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+)
+
+func TestOwnerReferenceBuilder(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", UID: "uid1"}}
+
+	ref, err := NewOwnerReferenceBuilder(owner, scheme.Scheme).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "uid1"}, ref)
+
+	// Controller
+	ref, err = NewOwnerReferenceBuilder(owner, scheme.Scheme).AsController().Build()
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Bool(true), ref.Controller)
+	assert.Equal(t, pointer.Bool(true), ref.BlockOwnerDeletion)
+
+	ref, err = NewOwnerReferenceBuilder(owner, scheme.Scheme).AsController().WithBlockOwnerDeletion(false).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Bool(false), ref.BlockOwnerDeletion)
+
+	// Errors
+	_, err = NewOwnerReferenceBuilder(nil, scheme.Scheme).Build()
+	assert.Error(t, err)
+	_, err = NewOwnerReferenceBuilder(owner, nil).Build()
+	assert.Error(t, err)
+	_, err = NewOwnerReferenceBuilder(&corev1.ConfigMap{}, scheme.Scheme).Build()
+	assert.Error(t, err)
+}
+
+func TestMergeOwnerReferences(t *testing.T) {
+	existing := []metav1.OwnerReference{
+		{APIVersion: "example.com/v1", Kind: "Elasticsearch", Name: "es", UID: "uid1", Controller: pointer.Bool(true)},
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "cm", UID: "uid2"},
+	}
+
+	// Merge by UID
+	merged, err := MergeOwnerReferences(existing,
+		metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "cm", UID: "uid2", BlockOwnerDeletion: pointer.Bool(true)},
+		metav1.OwnerReference{APIVersion: "v1", Kind: "Secret", Name: "secret", UID: "uid3"},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, merged, 3)
+	assert.Equal(t, pointer.Bool(true), merged[1].BlockOwnerDeletion)
+	assert.Nil(t, existing[1].BlockOwnerDeletion)
+
+	// Stale reference of recreated owner is replaced, even with other version
+	merged, err = MergeOwnerReferences(existing,
+		metav1.OwnerReference{APIVersion: "example.com/v2", Kind: "Elasticsearch", Name: "es", UID: "uid4", Controller: pointer.Bool(true)},
+	)
+	assert.NoError(t, err)
+	assert.Len(t, merged, 2)
+	assert.Equal(t, "uid4", string(merged[0].UID))
+
+	// Only one controller
+	_, err = MergeOwnerReferences(existing,
+		metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Kibana", Name: "kb", UID: "uid5", Controller: pointer.Bool(true)},
+	)
+	assert.Error(t, err)
+}
+
+func TestBuildersWithOwner(t *testing.T) {
+	podLabels := func(ptb PodTemplateBuilder) {
+		ptb.WithLabels(map[string]string{"app": "test"})
+	}
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}
+	target := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+	}
+
+	testCases := map[string]func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error){
+		"deployment": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewDeploymentBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithPodTemplate(podLabels).Build()
+		},
+		"statefulSet": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewStatefulSetBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithPodTemplate(podLabels).Build()
+		},
+		"job": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewJobBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).Build()
+		},
+		"cronJob": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewCronJobBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithSchedule("*/5 * * * *").Build()
+		},
+		"service": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewServiceBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithPorts([]corev1.ServicePort{{Name: "http", Port: 80}}).Build()
+		},
+		"configMap": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewConfigMapBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).Build()
+		},
+		"secret": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewSecretBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).Build()
+		},
+		"role": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewRoleBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).Build()
+		},
+		"clusterRole": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewClusterRoleBuilder().WithDefaults(&Defaults{}).WithName("test").
+				WithOwner(owner, s, true).Build()
+		},
+		"roleBinding": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewRoleBindingBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithRole("test").Build()
+		},
+		"clusterRoleBinding": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewClusterRoleBindingBuilder().WithDefaults(&Defaults{}).WithName("test").
+				WithOwner(owner, s, true).WithClusterRole("test").Build()
+		},
+		"hpa": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewHorizontalPodAutoscalerBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithScaleTarget(target).WithMaxReplicas(5).Build()
+		},
+		"pdb": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewPodDisruptionBudgetBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithSelector(selector).WithMinAvailable(intstr.FromInt(1)).Build()
+		},
+		"networkPolicy": func(owner metav1.Object, s *runtime.Scheme) (o metav1.Object, err error) {
+			return NewNetworkPolicyBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+				WithOwner(owner, s, true).WithPodSelector(*selector).Build()
+		},
+	}
+
+	// Cluster scoped owner can own namespaced and cluster scoped objects
+	owner := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "owner", UID: "uid1"}}
+	expected := []metav1.OwnerReference{{
+		APIVersion:         "v1",
+		Kind:               "Namespace",
+		Name:               "owner",
+		UID:                "uid1",
+		Controller:         pointer.Bool(true),
+		BlockOwnerDeletion: pointer.Bool(true),
+	}}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			o, err := testCase(owner, scheme.Scheme)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, o.GetOwnerReferences())
+
+			// Error is returned on Build
+			_, err = testCase(owner, nil)
+			assert.ErrorContains(t, err, "Error when set owner")
+		})
+	}
+}
+
+func TestBuilderWithOwnerNamespaced(t *testing.T) {
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: "uid1"}}
+
+	// Owner reference
+	cm, err := NewConfigMapBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("default").
+		WithOwner(owner, scheme.Scheme, false).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "uid1"}}, cm.OwnerReferences)
+
+	// When namespaced owner is on other namespace
+	_, err = NewConfigMapBuilder().WithDefaults(&Defaults{}).WithName("test").WithNamespace("other").
+		WithOwner(owner, scheme.Scheme, true).
+		Build()
+	assert.Error(t, err)
+
+	// When namespaced owner own cluster scoped object
+	_, err = NewClusterRoleBuilder().WithDefaults(&Defaults{}).WithName("test").
+		WithOwner(owner, scheme.Scheme, true).
+		Build()
+	assert.Error(t, err)
+}
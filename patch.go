@@ -0,0 +1,213 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SSAPatch permit to get the server side apply patch and the patch options for the built object
+// The object must have apiVersion and kind, because of server side apply need them.
+// The status is keeped by default. Use ExcludeStatus to not take ownership of status fields.
+// You can use it with `client.Patch(ctx, o, client.RawPatch(types.ApplyPatchType, patch), opts...)`
+func SSAPatch(o client.Object, fieldManager string, exportOpts ...ExportOption) (patch []byte, opts []client.PatchOption, err error) {
+	if o == nil {
+		return nil, nil, errors.New("Object can't be nil")
+	}
+	if fieldManager == "" {
+		return nil, nil, errors.New("Field manager can't be empty")
+	}
+	if o.GetObjectKind().GroupVersionKind().Empty() {
+		return nil, nil, errors.New("ApiVersion and kind must be set to use server side apply")
+	}
+
+	// Server side apply not accept managedFields and we never want to take a lock on resourceVersion
+	applyObject := o.DeepCopyObject().(client.Object)
+	applyObject.SetManagedFields(nil)
+	applyObject.SetResourceVersion("")
+
+	patch, err = marshalObject(applyObject, withoutStatus(false, exportOpts))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	opts = []client.PatchOption{
+		client.FieldOwner(fieldManager),
+		client.ForceOwnership,
+	}
+
+	return patch, opts, nil
+}
+
+// SetLastAppliedConfiguration permit to record the object on the kubectl last-applied-configuration annotation
+// It will be read back by PatchAgainst to remove fields that are not more on the expected object.
+// It's never called by builders: the caller must call it on the expected object before write it on cluster,
+// or use Reconcile with RecordLastAppliedConfiguration.
+func SetLastAppliedConfiguration(o client.Object) (err error) {
+	if o == nil {
+		return errors.New("Object can't be nil")
+	}
+
+	// The annotation must not contain itself
+	lastAppliedObject := o.DeepCopyObject().(client.Object)
+	annotations := lastAppliedObject.GetAnnotations()
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	lastAppliedObject.SetAnnotations(annotations)
+
+	lastApplied, err := json.Marshal(lastAppliedObject)
+	if err != nil {
+		return errors.Wrap(err, "Error when marshal object")
+	}
+
+	annotations = o.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[corev1.LastAppliedConfigAnnotation] = string(lastApplied)
+	o.SetAnnotations(annotations)
+
+	return nil
+}
+
+// GetLastAppliedConfiguration permit to get the last applied configuration recorded on object
+// It return nil if there are no last-applied-configuration annotation
+func GetLastAppliedConfiguration(o client.Object) []byte {
+	if o == nil {
+		return nil
+	}
+
+	if lastApplied, ok := o.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok && lastApplied != "" {
+		return []byte(lastApplied)
+	}
+
+	return nil
+}
+
+// PatchAgainst permit to compute the strategic merge patch that move the live object to the expected object
+// Only the fields set on expected object are patched, so fields defaulted by the API server are keeped.
+// If live object have the last-applied-configuration annotation, it's used as original object for three-way merge,
+// like kubectl apply do. So fields removed from expected object since the last apply are removed from live object.
+// The status and the metadata fields set by API server (creationTimestamp, resourceVersion, uid, managedFields, generation)
+// are never patched.
+// The annotation is only set by SetLastAppliedConfiguration, and it's removed by Sanitize: don't sanitize the live object before.
+// Without it, fields removed from expected object are keeped on live object.
+// You can use it with `client.Patch(ctx, live, client.RawPatch(patchType, patch))`
+func PatchAgainst(expected, live client.Object) (patch []byte, patchType types.PatchType, err error) {
+	return patchAgainst(expected, live, true)
+}
+
+// patchAgainst permit to compute the strategic merge patch that move the live object to the expected object, with or without the status
+func patchAgainst(expected, live client.Object, excludeStatus bool) (patch []byte, patchType types.PatchType, err error) {
+	if expected == nil || live == nil {
+		return nil, "", errors.New("Expected and live object can't be nil")
+	}
+	if reflect.TypeOf(expected) != reflect.TypeOf(live) {
+		return nil, "", errors.Errorf("Expected object (%T) and live object (%T) must have the same type", expected, live)
+	}
+
+	expectedByte, err := marshalForPatch(expected, excludeStatus)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when marshal expected object")
+	}
+	liveByte, err := marshalForPatch(live, excludeStatus)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when marshal live object")
+	}
+
+	patchMeta, err := newCachedPatchMeta(expected)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when get patch metadata")
+	}
+
+	// Use last applied configuration or expected object as original to never remove fields only managed by API server
+	originalByte := expectedByte
+	if lastApplied := GetLastAppliedConfiguration(live); lastApplied != nil {
+		if originalByte, err = withoutServerFields(lastApplied, excludeStatus); err != nil {
+			return nil, "", errors.Wrap(err, "Error when read last applied configuration")
+		}
+	}
+
+	patch, err = strategicpatch.CreateThreeWayMergePatch(originalByte, expectedByte, liveByte, patchMeta, true)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when create strategic merge patch")
+	}
+
+	return patch, types.StrategicMergePatchType, nil
+}
+
+// JSONPatchAgainst permit to compute the JSON patch (RFC 6902) that move the live object to the expected object
+// Like PatchAgainst, only the fields set on expected object are patched, and the status and the metadata fields
+// set by API server are never patched.
+// You can use it with `client.Patch(ctx, live, client.RawPatch(patchType, patch))`
+func JSONPatchAgainst(expected, live client.Object) (patch []byte, patchType types.PatchType, err error) {
+	strategicPatch, _, err := PatchAgainst(expected, live)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// The fields set by API server are not on both documents, so no operation is emitted on them
+	liveByte, err := marshalForPatch(live, true)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when marshal live object")
+	}
+
+	targetByte, err := strategicpatch.StrategicMergePatch(liveByte, strategicPatch, expected)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when apply strategic merge patch on live object")
+	}
+
+	operations, err := jsonpatch.CreatePatch(liveByte, targetByte)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when create JSON patch")
+	}
+
+	// Always return a list, even if there are no operation
+	if operations == nil {
+		operations = make([]jsonpatch.Operation, 0)
+	}
+
+	patch, err = json.Marshal(operations)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Error when marshal JSON patch")
+	}
+
+	return patch, types.JSONPatchType, nil
+}
+
+// serverMetadataFields are the metadata fields set by API server, that are never patched
+var serverMetadataFields = []string{"creationTimestamp", "resourceVersion", "uid", "managedFields", "generation", "selfLink"}
+
+// marshalForPatch permit to marshal object as JSON without the metadata fields set by API server, and with or without the status
+// Typed objects marshal empty creationTimestamp as null, so patch would remove it from live object.
+func marshalForPatch(o client.Object, excludeStatus bool) (data []byte, err error) {
+	if data, err = json.Marshal(o); err != nil {
+		return nil, err
+	}
+
+	return withoutServerFields(data, excludeStatus)
+}
+
+// withoutServerFields permit to remove the metadata fields set by API server, and the status if excluded, from JSON object
+func withoutServerFields(data []byte, excludeStatus bool) ([]byte, error) {
+	content := map[string]any{}
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+
+	if excludeStatus {
+		delete(content, "status")
+	}
+	if metadata, ok := content["metadata"].(map[string]any); ok {
+		for _, field := range serverMetadataFields {
+			delete(metadata, field)
+		}
+	}
+
+	return json.Marshal(content)
+}
@@ -0,0 +1,94 @@
+package k8sbuilder
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// patchMetaCache is the cache of patch metadata, by Go type and field
+// The number of entries is bounded by the number of fields of the merged types, so it's never evicted.
+var (
+	patchMetaCache       sync.Map
+	patchMetaCacheHits   atomic.Uint64
+	patchMetaCacheMisses atomic.Uint64
+)
+
+type patchMetaCacheKey struct {
+	t     reflect.Type
+	key   string
+	slice bool
+}
+
+type patchMetaCacheEntry struct {
+	lookup    strategicpatch.LookupPatchMeta
+	patchMeta strategicpatch.PatchMeta
+	err       error
+}
+
+// PatchMetaCacheStats is the statistics of patch metadata cache
+type PatchMetaCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// GetPatchMetaCacheStats permit to get the statistics of patch metadata cache
+func GetPatchMetaCacheStats() PatchMetaCacheStats {
+	return PatchMetaCacheStats{
+		Hits:   patchMetaCacheHits.Load(),
+		Misses: patchMetaCacheMisses.Load(),
+	}
+}
+
+// cachedPatchMeta is the patch metadata lookup that cache the struct tags parsing of each field
+type cachedPatchMeta struct {
+	strategicpatch.PatchMetaFromStruct
+}
+
+// newCachedPatchMeta permit to get the cached patch metadata lookup of object
+func newCachedPatchMeta(dataStruct any) (strategicpatch.LookupPatchMeta, error) {
+	meta, err := strategicpatch.NewPatchMetaFromStruct(dataStruct)
+	if err != nil {
+		return nil, err
+	}
+
+	return cachedPatchMeta{PatchMetaFromStruct: meta}, nil
+}
+
+// LookupPatchMetadataForStruct permit to get the patch metadata of struct field
+func (h cachedPatchMeta) LookupPatchMetadataForStruct(key string) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	return h.lookup(key, false)
+}
+
+// LookupPatchMetadataForSlice permit to get the patch metadata of slice field
+func (h cachedPatchMeta) LookupPatchMetadataForSlice(key string) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	return h.lookup(key, true)
+}
+
+func (h cachedPatchMeta) lookup(key string, slice bool) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	cacheKey := patchMetaCacheKey{t: h.T, key: key, slice: slice}
+	if value, ok := patchMetaCache.Load(cacheKey); ok {
+		observePatchMetaCache(true)
+		entry := value.(patchMetaCacheEntry)
+		return entry.lookup, entry.patchMeta, entry.err
+	}
+	observePatchMetaCache(false)
+
+	entry := patchMetaCacheEntry{}
+	if slice {
+		entry.lookup, entry.patchMeta, entry.err = h.PatchMetaFromStruct.LookupPatchMetadataForSlice(key)
+	} else {
+		entry.lookup, entry.patchMeta, entry.err = h.PatchMetaFromStruct.LookupPatchMetadataForStruct(key)
+	}
+
+	// The nested lookups need to use the cache too
+	if meta, ok := entry.lookup.(strategicpatch.PatchMetaFromStruct); ok {
+		entry.lookup = cachedPatchMeta{PatchMetaFromStruct: meta}
+	}
+
+	patchMetaCache.Store(cacheKey, entry)
+
+	return entry.lookup, entry.patchMeta, entry.err
+}
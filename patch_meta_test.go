@@ -0,0 +1,49 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPatchMetaCache(t *testing.T) {
+	current := func() *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "test", Image: "nginx:old"}},
+		}
+	}
+	dst := current()
+	src := &corev1.PodSpec{}
+	new := &corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:  "test",
+				Image: "nginx",
+				Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 80}},
+			},
+		},
+	}
+
+	// First merge can fill the cache
+	assert.NoError(t, MergeK8s(dst, src, new))
+	before := GetPatchMetaCacheStats()
+
+	// Same types, all lookups must be served by the cache
+	dst = current()
+	assert.NoError(t, MergeK8s(dst, src, new))
+	after := GetPatchMetaCacheStats()
+	assert.Equal(t, before.Misses, after.Misses)
+	assert.Greater(t, after.Hits, before.Hits)
+	assert.Equal(t, new.Containers, dst.Containers)
+
+	// Nested lookups keep the patch strategy
+	schema, err := newCachedPatchMeta(corev1.PodSpec{})
+	assert.NoError(t, err)
+	containerSchema, patchMeta, err := schema.LookupPatchMetadataForSlice("containers")
+	assert.NoError(t, err)
+	assert.Equal(t, "name", patchMeta.GetPatchMergeKey())
+	assert.Equal(t, []string{"merge"}, patchMeta.GetPatchStrategies())
+	_, ok := containerSchema.(cachedPatchMeta)
+	assert.True(t, ok)
+}
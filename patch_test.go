@@ -0,0 +1,260 @@
+package k8sbuilder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSSAPatch(t *testing.T) {
+	i := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			ResourceVersion: "12",
+		},
+	}
+
+	// When no type meta
+	_, _, err := SSAPatch(i, "operator")
+	assert.Error(t, err)
+
+	// When no field manager
+	i.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+	_, _, err = SSAPatch(i, "")
+	assert.Error(t, err)
+
+	// When all is right
+	patch, opts, err := SSAPatch(i, "operator")
+	assert.NoError(t, err)
+	assert.Equal(t, []client.PatchOption{client.FieldOwner("operator"), client.ForceOwnership}, opts)
+
+	expected := map[string]any{}
+	if err = json.Unmarshal(patch, &expected); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "networking.k8s.io/v1", expected["apiVersion"])
+	assert.Equal(t, "Ingress", expected["kind"])
+	assert.NotContains(t, expected["metadata"], "resourceVersion")
+	assert.Equal(t, "12", i.ResourceVersion)
+}
+
+func TestPatchAgainst(t *testing.T) {
+	live := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			ResourceVersion: "12",
+			Labels: map[string]string{
+				"app":   "test",
+				"label": "old",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+	}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "new",
+			},
+		},
+	}
+
+	patch, patchType, err := PatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.Equal(t, types.StrategicMergePatchType, patchType)
+	assert.JSONEq(t, `{"metadata":{"labels":{"label":"new"}}}`, string(patch))
+
+	// When no diff
+	patch, _, err = PatchAgainst(live, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(patch))
+
+	// When not the same type
+	_, _, err = PatchAgainst(expected, &networkingv1.IngressClass{})
+	assert.Error(t, err)
+}
+
+func TestPatchAgainstLiveObject(t *testing.T) {
+	// Live object like the API server return it
+	live := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			Namespace:         "default",
+			ResourceVersion:   "12",
+			UID:               types.UID("5c3b2b1e-7f3a-4c39-9d77-2f0e0c1b9a10"),
+			Generation:        3,
+			CreationTimestamp: metav1.NewTime(time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)),
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "operator", Operation: metav1.ManagedFieldsOperationUpdate},
+			},
+			Labels: map[string]string{"label": "old"},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels:    map[string]string{"label": "new"},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+	}
+
+	patch, _, err := PatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"labels":{"label":"new"}}}`, string(patch))
+
+	// With last applied configuration recorded from typed object
+	lastApplied := expected.DeepCopy()
+	lastApplied.Labels["removed"] = "true"
+	assert.NoError(t, SetLastAppliedConfiguration(lastApplied))
+	live.Annotations = lastApplied.Annotations
+	live.Labels["removed"] = "true"
+
+	patch, _, err = PatchAgainst(expected, live)
+	assert.NoError(t, err)
+	patchMap := map[string]any{}
+	assert.NoError(t, json.Unmarshal(patch, &patchMap))
+	assert.Equal(t, map[string]any{"label": "new", "removed": nil}, patchMap["metadata"].(map[string]any)["labels"])
+	assert.NotContains(t, string(patch), "creationTimestamp")
+	assert.NotContains(t, string(patch), "status")
+}
+
+func TestJSONPatchAgainst(t *testing.T) {
+	live := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":   "test",
+				"label": "old",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+	}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "new",
+			},
+		},
+	}
+
+	patch, patchType, err := JSONPatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.Equal(t, types.JSONPatchType, patchType)
+	assert.JSONEq(t, `[{"op":"replace","path":"/metadata/labels/label","value":"new"}]`, string(patch))
+
+	// When no diff
+	patch, _, err = JSONPatchAgainst(live, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(patch))
+}
+
+func TestJSONPatchAgainstLiveObject(t *testing.T) {
+	// Live object like the API server return it
+	live := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			Namespace:         "default",
+			ResourceVersion:   "12",
+			UID:               types.UID("5c3b2b1e-7f3a-4c39-9d77-2f0e0c1b9a10"),
+			Generation:        3,
+			CreationTimestamp: metav1.NewTime(time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)),
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "operator", Operation: metav1.ManagedFieldsOperationUpdate},
+			},
+			Labels: map[string]string{"label": "old"},
+		},
+		Status: networkingv1.IngressStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+		},
+	}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels:    map[string]string{"label": "new"},
+		},
+	}
+
+	patch, _, err := JSONPatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"op":"replace","path":"/metadata/labels/label","value":"new"}]`, string(patch))
+
+	// When no diff
+	expected.Labels["label"] = "old"
+	patch, _, err = JSONPatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(patch))
+}
+
+func TestLastAppliedConfiguration(t *testing.T) {
+	lastApplied := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label":   "old",
+				"removed": "true",
+			},
+		},
+	}
+	assert.Nil(t, GetLastAppliedConfiguration(lastApplied))
+	assert.NoError(t, SetLastAppliedConfiguration(lastApplied))
+	assert.NotNil(t, GetLastAppliedConfiguration(lastApplied))
+
+	// Annotation must not contain itself when set twice
+	assert.NoError(t, SetLastAppliedConfiguration(lastApplied))
+	assert.NotContains(t, string(GetLastAppliedConfiguration(lastApplied)), "last-applied-configuration")
+
+	live := lastApplied.DeepCopy()
+	live.Labels["app"] = "test"
+
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "new",
+			},
+		},
+	}
+
+	// Field removed since last apply must be removed, field managed by other must be keeped
+	patch, _, err := PatchAgainst(expected, live)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"labels":{"label":"new","removed":null}}}`, string(patch))
+}
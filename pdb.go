@@ -0,0 +1,219 @@
+package k8sbuilder
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodDisruptionBudgetBuilder is the PDB builder interface
+type PodDisruptionBudgetBuilder interface {
+	WithName(name string, opts ...WithOption) PodDisruptionBudgetBuilder
+	WithNamespace(namespace string, opts ...WithOption) PodDisruptionBudgetBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) PodDisruptionBudgetBuilder
+	WithoutLabels(keys ...string) PodDisruptionBudgetBuilder
+	WithFinalizer(name string) PodDisruptionBudgetBuilder
+	WithoutFinalizer(name string) PodDisruptionBudgetBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) PodDisruptionBudgetBuilder
+	WithSelector(selector *metav1.LabelSelector, opts ...WithOption) PodDisruptionBudgetBuilder
+	WithMinAvailable(minAvailable intstr.IntOrString) PodDisruptionBudgetBuilder
+	WithMaxUnavailable(maxUnavailable intstr.IntOrString) PodDisruptionBudgetBuilder
+	WithDefaults(defaults *Defaults) PodDisruptionBudgetBuilder
+	PodDisruptionBudget() *policyv1.PodDisruptionBudget
+	Build() (pdb *policyv1.PodDisruptionBudget, err error)
+}
+
+// PodDisruptionBudgetBuilderDefault is the default implementation of PDB builder
+type PodDisruptionBudgetBuilderDefault struct {
+	objectMeta
+	pdb      *policyv1.PodDisruptionBudget
+	defaults *Defaults
+	err      error
+}
+
+// NewPodDisruptionBudgetBuilder permit to init PDB builder
+func NewPodDisruptionBudgetBuilder() PodDisruptionBudgetBuilder {
+	pdb := &policyv1.PodDisruptionBudget{}
+
+	return &PodDisruptionBudgetBuilderDefault{
+		objectMeta: newObjectMeta(pdb),
+		pdb:        pdb,
+	}
+}
+
+// NewPodDisruptionBudgetBuilderFromWorkload permit to init PDB builder from the built workload
+// It copy name, namespace, labels and selector. If minAvailablePercent is greater than 0, minAvailable is this percentage,
+// else minAvailable is replicas - 1, so one pod can be evicted at a time. DaemonSet need a percentage, because of it has no replicas.
+func NewPodDisruptionBudgetBuilderFromWorkload(workload client.Object, minAvailablePercent int32) (PodDisruptionBudgetBuilder, error) {
+	var (
+		selector *metav1.LabelSelector
+		replicas *int32
+	)
+
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		selector = w.Spec.Selector
+		replicas = w.Spec.Replicas
+	case *appsv1.StatefulSet:
+		selector = w.Spec.Selector
+		replicas = w.Spec.Replicas
+	case *appsv1.DaemonSet:
+		selector = w.Spec.Selector
+		if minAvailablePercent <= 0 {
+			return nil, errors.New("Min available percent is needed for DaemonSet")
+		}
+	default:
+		return nil, errors.Errorf("Workload of type %T is not supported", workload)
+	}
+
+	if selector == nil {
+		return nil, errors.New("Workload selector can't be nil")
+	}
+
+	var minAvailable intstr.IntOrString
+	if minAvailablePercent > 0 {
+		if minAvailablePercent > 100 {
+			return nil, errors.Errorf("Min available percent (%d) must be lower or equal to 100", minAvailablePercent)
+		}
+		minAvailable = intstr.FromString(fmt.Sprintf("%d%%", minAvailablePercent))
+	} else {
+		// Replicas is defaulted to 1 by API server
+		nb := int32(1)
+		if replicas != nil {
+			nb = *replicas
+		}
+		if nb > 0 {
+			nb--
+		}
+		minAvailable = intstr.FromInt(int(nb))
+	}
+
+	return NewPodDisruptionBudgetBuilder().
+		WithName(workload.GetName()).
+		WithNamespace(workload.GetNamespace()).
+		WithLabels(mergeMap(nil, workload.GetLabels())).
+		WithSelector(selector.DeepCopy()).
+		WithMinAvailable(minAvailable), nil
+}
+
+// PodDisruptionBudget permit to get current PDB
+func (h *PodDisruptionBudgetBuilderDefault) PodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	return h.pdb
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *PodDisruptionBudgetBuilderDefault) WithDefaults(defaults *Defaults) PodDisruptionBudgetBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the PDB
+// It fail if selector is not set
+func (h *PodDisruptionBudgetBuilderDefault) Build() (pdb *policyv1.PodDisruptionBudget, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if h.pdb.Spec.Selector == nil {
+		return nil, errors.New("Selector must be set")
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.pdb)
+
+	if errs := ValidateMetadata(h.pdb); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.pdb, nil
+}
+
+// WithName permit to set name
+func (h *PodDisruptionBudgetBuilderDefault) WithName(name string, opts ...WithOption) PodDisruptionBudgetBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *PodDisruptionBudgetBuilderDefault) WithNamespace(namespace string, opts ...WithOption) PodDisruptionBudgetBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *PodDisruptionBudgetBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) PodDisruptionBudgetBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *PodDisruptionBudgetBuilderDefault) WithoutLabels(keys ...string) PodDisruptionBudgetBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *PodDisruptionBudgetBuilderDefault) WithFinalizer(name string) PodDisruptionBudgetBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *PodDisruptionBudgetBuilderDefault) WithoutFinalizer(name string) PodDisruptionBudgetBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *PodDisruptionBudgetBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) PodDisruptionBudgetBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithSelector permit to set selector
+func (h *PodDisruptionBudgetBuilderDefault) WithSelector(selector *metav1.LabelSelector, opts ...WithOption) PodDisruptionBudgetBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.pdb.Spec.Selector == nil {
+		h.pdb.Spec.Selector = selector
+	}
+
+	return h
+}
+
+// WithMinAvailable permit to set min available
+// Max unavailable is removed, because of they can't be set together
+func (h *PodDisruptionBudgetBuilderDefault) WithMinAvailable(minAvailable intstr.IntOrString) PodDisruptionBudgetBuilder {
+	h.pdb.Spec.MinAvailable = &minAvailable
+	h.pdb.Spec.MaxUnavailable = nil
+
+	return h
+}
+
+// WithMaxUnavailable permit to set max unavailable
+// Min available is removed, because of they can't be set together
+func (h *PodDisruptionBudgetBuilderDefault) WithMaxUnavailable(maxUnavailable intstr.IntOrString) PodDisruptionBudgetBuilder {
+	h.pdb.Spec.MaxUnavailable = &maxUnavailable
+	h.pdb.Spec.MinAvailable = nil
+
+	return h
+}
@@ -0,0 +1,43 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestPodDisruptionBudgetFromWorkload(t *testing.T) {
+	w := NewWorkloadBuilder(WorkloadDeployment).
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithReplicas(3).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+
+	// From replicas
+	b, err := w.PodDisruptionBudget(0)
+	assert.NoError(t, err)
+	pdb, err := b.WithDefaults(&Defaults{}).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", pdb.Name)
+	assert.Equal(t, "default", pdb.Namespace)
+	assert.Equal(t, map[string]string{"app": "test"}, pdb.Spec.Selector.MatchLabels)
+	assert.Equal(t, intstr.FromInt(2), *pdb.Spec.MinAvailable)
+
+	// From percentage
+	b, err = w.PodDisruptionBudget(50)
+	assert.NoError(t, err)
+	assert.Equal(t, intstr.FromString("50%"), *b.PodDisruptionBudget().Spec.MinAvailable)
+
+	// DaemonSet need percentage
+	_, err = w.WithKind(WorkloadDaemonSet).PodDisruptionBudget(0)
+	assert.Error(t, err)
+	b, err = w.PodDisruptionBudget(80)
+	assert.NoError(t, err)
+	pdb = b.WithMaxUnavailable(intstr.FromInt(1)).PodDisruptionBudget()
+	assert.Nil(t, pdb.Spec.MinAvailable)
+	assert.Equal(t, intstr.FromInt(1), *pdb.Spec.MaxUnavailable)
+}
@@ -1,32 +1,108 @@
 package k8sbuilder
 
 import (
+	"context"
 	"reflect"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/imdario/mergo"
+	"github.com/pkg/errors"
 	"github.com/thoas/go-funk"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type PodTemplateBuilder interface {
 	WithPodTemplateSpec(pts *corev1.PodTemplateSpec, opts ...WithOption) PodTemplateBuilder
 	WithLabels(labels map[string]string, opts ...WithOption) PodTemplateBuilder
+	WithoutLabels(keys ...string) PodTemplateBuilder
 	WithAnnotations(annotations map[string]string, opts ...WithOption) PodTemplateBuilder
+	WithoutAnnotations(keys ...string) PodTemplateBuilder
+	WithRecommendedLabels(name, instance, version, component, partOf, managedBy string) PodTemplateBuilder
+	WithChecksumAnnotation(key string, inputs ...any) PodTemplateBuilder
+	WithParentMetadata(parent metav1.Object, labelKeys []string, annotationKeys []string) PodTemplateBuilder
 	WithImagePullSecrets(ips []corev1.LocalObjectReference, opts ...WithOption) PodTemplateBuilder
+	WithImagePullSecretNames(names []string, opts ...WithOption) PodTemplateBuilder
 	WithTerminationGracePeriodSeconds(nb int64, opts ...WithOption) PodTemplateBuilder
+	WithGracefulShutdown(periodSeconds int64, preStopSleepSeconds int64, containers ...string) PodTemplateBuilder
+	WithRestartPolicy(restartPolicy corev1.RestartPolicy, opts ...WithOption) PodTemplateBuilder
 	WithTolerations(tolerations []corev1.Toleration, opts ...WithOption) PodTemplateBuilder
+	WithTolerationsForNodePool(ctx context.Context, c client.Reader, selector labels.Selector) PodTemplateBuilder
 	WithNodeSelector(nodeSelector map[string]string, opts ...WithOption) PodTemplateBuilder
+	WithGPUScheduling(resourceName string, nodeSelector map[string]string) PodTemplateBuilder
 	WithInitContainers(containers []corev1.Container, opts ...WithOption) PodTemplateBuilder
+	WithInitContainerBefore(name string, container corev1.Container) PodTemplateBuilder
+	WithInitContainerAfter(name string, container corev1.Container) PodTemplateBuilder
 	WithContainers(containers []corev1.Container, opts ...WithOption) PodTemplateBuilder
+	MoveContainerFirst(name string) PodTemplateBuilder
+	OrderContainers(names ...string) PodTemplateBuilder
 	WithVolumes(volumes []corev1.Volume, opts ...WithOption) PodTemplateBuilder
 	WithAffinity(affinity corev1.Affinity, opts ...WithOption) PodTemplateBuilder
+	WithMultiArchSupport(archs ...string) PodTemplateBuilder
+	WithImageDigests(container string, digests map[string]string) PodTemplateBuilder
+	WithSpotToleration(provider string) PodTemplateBuilder
 	WithSecurityContext(sc *corev1.PodSecurityContext, opts ...WithOption) PodTemplateBuilder
+	WithFSGroup(id int64, opts ...WithOption) PodTemplateBuilder
+	WithFSGroupChangePolicy(policy corev1.PodFSGroupChangePolicy, opts ...WithOption) PodTemplateBuilder
+	WithSupplementalGroups(ids ...int64) PodTemplateBuilder
+	WithWindowsOptions(options *corev1.WindowsSecurityContextOptions) PodTemplateBuilder
+	ForWindows() PodTemplateBuilder
+	WithSeccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) PodTemplateBuilder
+	WithContainerSeccompProfile(container string, profileType corev1.SeccompProfileType, localhostProfile string) PodTemplateBuilder
+	WithAppArmorProfile(container string, profile string) PodTemplateBuilder
+	WithIstioInjection(enabled bool, overrides map[string]string) PodTemplateBuilder
+	WithLinkerdInjection(enabled bool) PodTemplateBuilder
+	WithDefaults(defaults *Defaults) PodTemplateBuilder
+	WithPolicies(mode PolicyMode, policies ...Policy) PodTemplateBuilder
+	WithResourceNormalization(n *ResourceNormalization) PodTemplateBuilder
+	WithContainerResources(name string, requests, limits map[string]string, opts ...WithOption) PodTemplateBuilder
+	WithNormalization() PodTemplateBuilder
+	WithDefaultProbes() PodTemplateBuilder
+	WithTemplateData(data any) PodTemplateBuilder
+	WithLogger(logger logr.Logger) PodTemplateBuilder
+	WithDebug() PodTemplateBuilder
+	WithValidationMode(mode ValidationMode) PodTemplateBuilder
+	Warnings() field.ErrorList
+	DebugCalls() []DebugCall
+	Summary() string
+	IsDrifted(live *corev1.PodTemplateSpec) (bool, []string)
+	AuditPSS(level PSSLevel) (violations field.ErrorList, err error)
 	PodTemplate() *corev1.PodTemplateSpec
+	Selector(keys ...string) (selector *metav1.LabelSelector, err error)
+	Build() (pts *corev1.PodTemplateSpec, err error)
 }
 
 type PodTemplateBuilderDefault struct {
-	podTemplate *corev1.PodTemplateSpec
+	podTemplate           *corev1.PodTemplateSpec
+	defaults              *Defaults
+	policyMode            PolicyMode
+	policies              []Policy
+	resourceNormalization *ResourceNormalization
+	templateData          any
+	logger                logr.Logger
+	debug                 bool
+	debugCalls            []DebugCall
+	observeDepth          int
+	shared                sharedFields
+	windows               bool
+	normalize             bool
+	defaultProbes         bool
+	err                   error
+	mergeBase             *corev1.PodTemplateSpec
+	validationMode        ValidationMode
+	warnings              field.ErrorList
+}
+
+// DebugCall is the diff done on pod template by one call of builder
+type DebugCall struct {
+	Method string
+	Option WithOption
+	Diffs  []FieldDiff
 }
 
 // NewPodTemplateBuilder permit to init pod template builder
@@ -37,31 +113,255 @@ func NewPodTemplateBuilder() PodTemplateBuilder {
 }
 
 // PodTemplate permit to get current pod template
+// Sub-structures can be shared with the pod template given to WithPodTemplateSpec, so use the With methods to modify it
 func (h *PodTemplateBuilderDefault) PodTemplate() *corev1.PodTemplateSpec {
 	return h.podTemplate
 }
 
+// Selector permit to derive label selector from pod template labels
+// Use it to set the selector of workloads, so it always match pod template labels
+// If no keys are provided, only the stable keys are used, because of selector is immutable. See StableSelectorKeys.
+// The keys of default labels are never used, so defaults can change without break the workloads.
+func (h *PodTemplateBuilderDefault) Selector(keys ...string) (selector *metav1.LabelSelector, err error) {
+	if len(keys) == 0 {
+		defaults := h.defaults
+		if defaults == nil {
+			defaults = GlobalDefaults
+		}
+		defaultKeys := make([]string, 0, len(defaults.Labels))
+		for key := range defaults.Labels {
+			defaultKeys = append(defaultKeys, key)
+		}
+
+		if keys = StableSelectorKeys(h.podTemplate.Labels, defaultKeys...); len(keys) == 0 {
+			return nil, errors.New("Can't derive selector from pod template labels, set app.kubernetes.io/name label or the selector")
+		}
+	}
+
+	return SelectorFromLabels(h.podTemplate.Labels, keys...)
+}
+
+// Build permit to get the pod template after apply the defaults, normalize resources and check policies
+// It use the global defaults if no defaults are set on builder
+// If template data is set, the Go templates on string fields are resolved first.
+func (h *PodTemplateBuilderDefault) Build() (pts *corev1.PodTemplateSpec, err error) {
+	defer h.observe("Build")()
+	defer observeBuild("PodTemplate", time.Now())
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	// Interpolation and policies can modify any field
+	if h.templateData != nil || len(h.policies) > 0 {
+		h.own(sharedAll)
+	} else {
+		h.own(sharedLabels | sharedInitContainers | sharedContainers)
+	}
+	if h.windows {
+		h.own(sharedSecurityContext)
+	}
+	if h.normalize {
+		h.own(sharedVolumes | sharedTolerations)
+	}
+
+	if h.templateData != nil {
+		if err = Interpolate(h.podTemplate, h.templateData); err != nil {
+			return nil, errors.Wrap(err, "Error when interpolate pod template")
+		}
+	}
+
+	// Derived probes need the defaults too
+	if h.defaultProbes {
+		DefaultProbesFromPorts(h.podTemplate)
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToPodTemplate(h.podTemplate)
+
+	if h.resourceNormalization != nil {
+		for _, c := range allContainers(h.podTemplate) {
+			NormalizeResources(&c.Resources, h.resourceNormalization)
+		}
+	}
+
+	if h.windows {
+		removeLinuxOnlyFields(h.podTemplate)
+	}
+
+	if err = ApplyPolicies(h.podTemplate, h.policyMode, h.policies...); err != nil {
+		return nil, errors.Wrap(err, "Pod template not respect policies")
+	}
+
+	if h.normalize {
+		NormalizePodTemplate(h.podTemplate)
+	}
+
+	if errs := ValidatePodTemplate(h.podTemplate); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Pod template is invalid")
+	}
+
+	h.warnings = ValidatePodTemplateConflicts(h.podTemplate)
+	if len(h.warnings) > 0 {
+		if h.validationMode == ValidationStrict {
+			return nil, errors.Wrap(h.warnings.ToAggregate(), "Pod template has conflicts")
+		}
+		if h.logger.GetSink() != nil {
+			for _, warning := range h.warnings {
+				h.logger.Info("Pod template has conflict", "warning", warning.Error())
+			}
+		}
+	}
+
+	return h.podTemplate, nil
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *PodTemplateBuilderDefault) WithDefaults(defaults *Defaults) PodTemplateBuilder {
+	defer h.observe("WithDefaults")()
+
+	h.defaults = defaults
+
+	return h
+}
+
+// WithPolicies permit to check policies on Build
+// With PolicyEnforce mode, Build fail if policies are not respected. With PolicyAutoFix mode, policies fix the pod template.
+func (h *PodTemplateBuilderDefault) WithPolicies(mode PolicyMode, policies ...Policy) PodTemplateBuilder {
+	defer h.observe("WithPolicies")()
+
+	h.policyMode = mode
+	h.policies = policies
+
+	return h
+}
+
+// WithResourceNormalization permit to normalize the resources of all containers on Build
+func (h *PodTemplateBuilderDefault) WithResourceNormalization(n *ResourceNormalization) PodTemplateBuilder {
+	defer h.observe("WithResourceNormalization")()
+
+	h.resourceNormalization = n
+
+	return h
+}
+
+// WithTemplateData permit to resolve Go template on string fields on Build, like `{{ .ClusterName }}`
+func (h *PodTemplateBuilderDefault) WithTemplateData(data any) PodTemplateBuilder {
+	defer h.observe("WithTemplateData")()
+
+	h.templateData = data
+
+	return h
+}
+
+// WithLogger permit to log at V(2) each call with the method, the option and the path of changed fields
+// It's usefull to debug why the rendered pod template differ from expectations
+func (h *PodTemplateBuilderDefault) WithLogger(logger logr.Logger) PodTemplateBuilder {
+	h.logger = logger
+
+	return h
+}
+
+// WithDebug permit to capture the diff of each call, to know which call remove or change a field
+// Diffs are retrieved with DebugCalls
+func (h *PodTemplateBuilderDefault) WithDebug() PodTemplateBuilder {
+	h.debug = true
+
+	return h
+}
+
+// WithValidationMode permit to choose if conflicts found on Build, like duplicate env or host ports, fail the Build
+// By default, conflicts are only logged and kept as warnings. See ValidatePodTemplateConflicts.
+func (h *PodTemplateBuilderDefault) WithValidationMode(mode ValidationMode) PodTemplateBuilder {
+	defer h.observe("WithValidationMode")()
+
+	h.validationMode = mode
+
+	return h
+}
+
+// Warnings permit to get the conflicts found by the last Build
+func (h *PodTemplateBuilderDefault) Warnings() field.ErrorList {
+	return h.warnings
+}
+
+// DebugCalls permit to get the diff of each call done since WithDebug
+func (h *PodTemplateBuilderDefault) DebugCalls() []DebugCall {
+	return h.debugCalls
+}
+
+// observe permit to log and capture the call of method when logger or debug is set
+// Use it with defer: defer h.observe("WithLabels", opts...)()
+// Only the outermost call is captured, so the calls done by method itself, like WithRecommendedLabels to WithLabels, are not recorded twice.
+func (h *PodTemplateBuilderDefault) observe(method string, opts ...WithOption) func() {
+	h.observeDepth++
+	if h.observeDepth > 1 || (h.logger.GetSink() == nil && !h.debug) {
+		return func() {
+			h.observeDepth--
+		}
+	}
+
+	before := h.podTemplate.DeepCopy()
+	return func() {
+		h.observeDepth--
+		option := Overwrite
+		if len(opts) > 0 {
+			option = opts[0]
+		}
+		diffs := DiffFields(before, h.podTemplate)
+
+		if h.debug {
+			h.debugCalls = append(h.debugCalls, DebugCall{
+				Method: method,
+				Option: option,
+				Diffs:  diffs,
+			})
+		}
+
+		if h.logger.GetSink() != nil {
+			paths := make([]string, 0, len(diffs))
+			for _, diff := range diffs {
+				paths = append(paths, diff.Path)
+			}
+			h.logger.V(2).Info("Call pod template builder", "method", method, "option", option, "diff", paths)
+		}
+	}
+}
+
 // WithPodTemplateSpec permit to use existing podTemplateSpec
+// The pod template is not copied: its sub-structures are deep copied only when a call modify them (copy on write).
+// So the same pod template can be used by many builders without DeepCopy it.
 func (h *PodTemplateBuilderDefault) WithPodTemplateSpec(pts *corev1.PodTemplateSpec, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithPodTemplateSpec", opts...)()
+
 	if pts == nil {
 		return h
 	}
 
 	// Overwrite
 	if IsOverwrite(opts) {
-		h.podTemplate = pts
+		h.shareTemplate(pts)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate).Elem().IsZero() {
-		h.podTemplate = pts
+		h.shareTemplate(pts)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
-		orgPts := h.podTemplate.DeepCopy()
+		// The current pod template is keeped as is, to merge its lists after
+		orgPts := h.podTemplate
+		h.podTemplate = orgPts.DeepCopy()
+		h.shared = 0
+		if h.mergeBase == nil {
+			h.mergeBase = orgPts
+		}
 
 		if err := MergeK8s(h.podTemplate, h.podTemplate, pts); err != nil {
 			panic(err)
@@ -84,20 +384,25 @@ func (h *PodTemplateBuilderDefault) WithPodTemplateSpec(pts *corev1.PodTemplateS
 
 // WithLabels permit to set labels
 func (h *PodTemplateBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithLabels", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Labels == nil {
 		h.podTemplate.Labels = labels
+		h.share(sharedLabels)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Labels).Elem().IsZero() {
 		h.podTemplate.Labels = labels
+		h.share(sharedLabels)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) && labels != nil {
+		h.own(sharedLabels)
 		if err := mergo.Merge(&h.podTemplate.Labels, labels); err != nil {
 			panic(err)
 		}
@@ -106,22 +411,44 @@ func (h *PodTemplateBuilderDefault) WithLabels(labels map[string]string, opts ..
 	return h
 }
 
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *PodTemplateBuilderDefault) WithoutLabels(keys ...string) PodTemplateBuilder {
+	defer h.observe("WithoutLabels")()
+
+	h.podTemplate.Labels = withoutKeys(h.podTemplate.Labels, keys)
+
+	return h
+}
+
+// WithRecommendedLabels permit to merge the recommended app.kubernetes.io labels
+// Use the same values on object builder to keep labels consistent
+func (h *PodTemplateBuilderDefault) WithRecommendedLabels(name, instance, version, component, partOf, managedBy string) PodTemplateBuilder {
+	defer h.observe("WithRecommendedLabels")()
+
+	return h.WithLabels(RecommendedLabels(name, instance, version, component, partOf, managedBy), Merge)
+}
+
 // WithAnnotations permit to set annotations
 func (h *PodTemplateBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithAnnotations", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Annotations == nil {
 		h.podTemplate.Annotations = annotations
+		h.share(sharedAnnotations)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Annotations).Elem().IsZero() {
 		h.podTemplate.Annotations = annotations
+		h.share(sharedAnnotations)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) && annotations != nil {
+		h.own(sharedAnnotations)
 		if err := mergo.Merge(&h.podTemplate.Annotations, annotations); err != nil {
 			panic(err)
 		}
@@ -130,31 +457,83 @@ func (h *PodTemplateBuilderDefault) WithAnnotations(annotations map[string]strin
 	return h
 }
 
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *PodTemplateBuilderDefault) WithoutAnnotations(keys ...string) PodTemplateBuilder {
+	defer h.observe("WithoutAnnotations")()
+
+	h.podTemplate.Annotations = withoutKeys(h.podTemplate.Annotations, keys)
+
+	return h
+}
+
+// WithChecksumAnnotation permit to set annotation with the checksum of inputs
+// Use it with the same inputs than GenerateNameWithHash, so pods are rolled out when config change
+// Build return error if inputs can't be serialized.
+func (h *PodTemplateBuilderDefault) WithChecksumAnnotation(key string, inputs ...any) PodTemplateBuilder {
+	defer h.observe("WithChecksumAnnotation")()
+
+	checksum, err := Checksum(inputs...)
+	if err != nil {
+		if h.err == nil {
+			h.err = errors.Wrapf(err, "Error when compute checksum annotation %s", key)
+		}
+		return h
+	}
+
+	h.own(sharedAnnotations)
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	h.podTemplate.Annotations[key] = checksum
+
+	return h
+}
+
+// WithParentMetadata permit to copy selected labels and annotations from parent object, like the workload
+// Values from parent overwrite the pod template ones, so they are keeped in sync
+func (h *PodTemplateBuilderDefault) WithParentMetadata(parent metav1.Object, labelKeys []string, annotationKeys []string) PodTemplateBuilder {
+	defer h.observe("WithParentMetadata")()
+
+	if parent == nil {
+		return h
+	}
+
+	h.own(sharedLabels | sharedAnnotations)
+	h.podTemplate.Labels = copyKeys(h.podTemplate.Labels, parent.GetLabels(), labelKeys)
+	h.podTemplate.Annotations = copyKeys(h.podTemplate.Annotations, parent.GetAnnotations(), annotationKeys)
+
+	return h
+}
+
 // WithImagePullSecrets permit to set ImagePullSecret
 func (h *PodTemplateBuilderDefault) WithImagePullSecrets(ips []corev1.LocalObjectReference, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithImagePullSecrets", opts...)()
 
 	var tmpIps []corev1.LocalObjectReference
 
 	// Avoid overwrite ips
 	if ips != nil {
-		tmpIps := make([]corev1.LocalObjectReference, len(ips))
+		tmpIps = make([]corev1.LocalObjectReference, len(ips))
 		copy(tmpIps, ips)
 	}
 
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.ImagePullSecrets == nil {
 		h.podTemplate.Spec.ImagePullSecrets = tmpIps
+		h.share(sharedImagePullSecrets)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.ImagePullSecrets).Elem().IsZero() {
 		h.podTemplate.Spec.ImagePullSecrets = tmpIps
+		h.share(sharedImagePullSecrets)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedImagePullSecrets)
 		for _, ref := range tmpIps {
 			if !funk.Contains(h.podTemplate.Spec.ImagePullSecrets, func(o corev1.LocalObjectReference) bool {
 				return ref.Name == o.Name
@@ -167,8 +546,28 @@ func (h *PodTemplateBuilderDefault) WithImagePullSecrets(ips []corev1.LocalObjec
 	return h
 }
 
+// WithImagePullSecretNames permit to set ImagePullSecret from secret names
+// Duplicate names are ignored
+func (h *PodTemplateBuilderDefault) WithImagePullSecretNames(names []string, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithImagePullSecretNames", opts...)()
+
+	var ips []corev1.LocalObjectReference
+	if names != nil {
+		ips = make([]corev1.LocalObjectReference, 0, len(names))
+		for _, name := range names {
+			if !funk.Contains(ips, corev1.LocalObjectReference{Name: name}) {
+				ips = append(ips, corev1.LocalObjectReference{Name: name})
+			}
+		}
+	}
+
+	return h.WithImagePullSecrets(ips, opts...)
+}
+
 // WithTerminationGracePeriodSeconds permit to set TerminationGracePeriodSeconds
 func (h *PodTemplateBuilderDefault) WithTerminationGracePeriodSeconds(nb int64, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithTerminationGracePeriodSeconds", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || IsMerge(opts) || h.podTemplate.Spec.TerminationGracePeriodSeconds == nil {
 		h.podTemplate.Spec.TerminationGracePeriodSeconds = pointer.Int64(nb)
@@ -178,8 +577,21 @@ func (h *PodTemplateBuilderDefault) WithTerminationGracePeriodSeconds(nb int64,
 	return h
 }
 
+// WithRestartPolicy permit to set restart policy
+func (h *PodTemplateBuilderDefault) WithRestartPolicy(restartPolicy corev1.RestartPolicy, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithRestartPolicy", opts...)()
+
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.podTemplate.Spec.RestartPolicy == "" {
+		h.podTemplate.Spec.RestartPolicy = restartPolicy
+	}
+
+	return h
+}
+
 // WithTolerations permit to set tolerations
 func (h *PodTemplateBuilderDefault) WithTolerations(tolerations []corev1.Toleration, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithTolerations", opts...)()
 
 	var tmpTolerations []corev1.Toleration
 
@@ -192,17 +604,20 @@ func (h *PodTemplateBuilderDefault) WithTolerations(tolerations []corev1.Tolerat
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.Tolerations == nil {
 		h.podTemplate.Spec.Tolerations = tmpTolerations
+		h.share(sharedTolerations)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.Tolerations).Elem().IsZero() {
 		h.podTemplate.Spec.Tolerations = tmpTolerations
+		h.share(sharedTolerations)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedTolerations)
 		for _, toleration := range tmpTolerations {
 			if !funk.Contains(h.podTemplate.Spec.Tolerations, toleration) {
 				h.podTemplate.Spec.Tolerations = append(h.podTemplate.Spec.Tolerations, toleration)
@@ -215,20 +630,25 @@ func (h *PodTemplateBuilderDefault) WithTolerations(tolerations []corev1.Tolerat
 
 // WithNodeSelector permit to set nodeSelector
 func (h *PodTemplateBuilderDefault) WithNodeSelector(nodeSelector map[string]string, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithNodeSelector", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.NodeSelector == nil {
 		h.podTemplate.Spec.NodeSelector = nodeSelector
+		h.share(sharedNodeSelector)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.NodeSelector).Elem().IsZero() {
 		h.podTemplate.Spec.NodeSelector = nodeSelector
+		h.share(sharedNodeSelector)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) && nodeSelector != nil {
+		h.own(sharedNodeSelector)
 		if err := mergo.Merge(&h.podTemplate.Spec.NodeSelector, nodeSelector); err != nil {
 			panic(err)
 		}
@@ -239,6 +659,7 @@ func (h *PodTemplateBuilderDefault) WithNodeSelector(nodeSelector map[string]str
 
 // WithInitContainers permit to set init containers
 func (h *PodTemplateBuilderDefault) WithInitContainers(containers []corev1.Container, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithInitContainers", opts...)()
 
 	var tmpContainers []corev1.Container
 
@@ -251,17 +672,20 @@ func (h *PodTemplateBuilderDefault) WithInitContainers(containers []corev1.Conta
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.InitContainers == nil {
 		h.podTemplate.Spec.InitContainers = tmpContainers
+		h.share(sharedInitContainers)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.InitContainers).Elem().IsZero() {
 		h.podTemplate.Spec.InitContainers = tmpContainers
+		h.share(sharedInitContainers)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedInitContainers)
 		for _, container := range tmpContainers {
 			index := funk.IndexOf(h.podTemplate.Spec.InitContainers, func(o corev1.Container) bool {
 				return container.Name == o.Name
@@ -283,6 +707,7 @@ func (h *PodTemplateBuilderDefault) WithInitContainers(containers []corev1.Conta
 
 // WithContainers permit to set containers
 func (h *PodTemplateBuilderDefault) WithContainers(containers []corev1.Container, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithContainers", opts...)()
 
 	var tmpContainers []corev1.Container
 
@@ -295,19 +720,22 @@ func (h *PodTemplateBuilderDefault) WithContainers(containers []corev1.Container
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.Containers == nil {
 		h.podTemplate.Spec.Containers = tmpContainers
+		h.share(sharedContainers)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.Containers).Elem().IsZero() {
 		h.podTemplate.Spec.Containers = tmpContainers
+		h.share(sharedContainers)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedContainers)
 		for _, container := range tmpContainers {
-			index := funk.IndexOf(h.podTemplate.Spec.InitContainers, func(o corev1.Container) bool {
+			index := funk.IndexOf(h.podTemplate.Spec.Containers, func(o corev1.Container) bool {
 				return container.Name == o.Name
 			})
 			if index == -1 {
@@ -326,6 +754,7 @@ func (h *PodTemplateBuilderDefault) WithContainers(containers []corev1.Container
 
 // WithContainers permit to set containers
 func (h *PodTemplateBuilderDefault) WithVolumes(volumes []corev1.Volume, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithVolumes", opts...)()
 
 	var tmpVolumes []corev1.Volume
 
@@ -338,17 +767,20 @@ func (h *PodTemplateBuilderDefault) WithVolumes(volumes []corev1.Volume, opts ..
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.Volumes == nil {
 		h.podTemplate.Spec.Volumes = tmpVolumes
+		h.share(sharedVolumes)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.Volumes).Elem().IsZero() {
 		h.podTemplate.Spec.Volumes = tmpVolumes
+		h.share(sharedVolumes)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedVolumes)
 		for _, volume := range tmpVolumes {
 			index := funk.IndexOf(h.podTemplate.Spec.Volumes, func(o corev1.Volume) bool {
 				return volume.Name == o.Name
@@ -368,20 +800,25 @@ func (h *PodTemplateBuilderDefault) WithVolumes(volumes []corev1.Volume, opts ..
 
 // WithAffinity permit to set affinity
 func (h *PodTemplateBuilderDefault) WithAffinity(affinity corev1.Affinity, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithAffinity", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.Affinity == nil {
 		h.podTemplate.Spec.Affinity = &affinity
+		h.share(sharedAffinity)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.Affinity).Elem().IsZero() {
 		h.podTemplate.Spec.Affinity = &affinity
+		h.share(sharedAffinity)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedAffinity)
 		if err := MergeK8s(h.podTemplate.Spec.Affinity, h.podTemplate.Spec.Affinity, affinity); err != nil {
 			panic(err)
 		}
@@ -392,20 +829,25 @@ func (h *PodTemplateBuilderDefault) WithAffinity(affinity corev1.Affinity, opts
 
 // WithSecurityContext permit to set security context
 func (h *PodTemplateBuilderDefault) WithSecurityContext(sc *corev1.PodSecurityContext, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithSecurityContext", opts...)()
+
 	// Overwrite
 	if IsOverwrite(opts) || h.podTemplate.Spec.SecurityContext == nil {
 		h.podTemplate.Spec.SecurityContext = sc
+		h.share(sharedSecurityContext)
 		return h
 	}
 
 	// Overwrite only if not default
 	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.podTemplate.Spec.SecurityContext).Elem().IsZero() {
 		h.podTemplate.Spec.SecurityContext = sc
+		h.share(sharedSecurityContext)
 		return h
 	}
 
 	// Merge
 	if IsMerge(opts) {
+		h.own(sharedSecurityContext)
 		if err := MergeK8s(h.podTemplate.Spec.SecurityContext, h.podTemplate.Spec.SecurityContext, sc); err != nil {
 			panic(err)
 		}
@@ -413,3 +855,59 @@ func (h *PodTemplateBuilderDefault) WithSecurityContext(sc *corev1.PodSecurityCo
 
 	return h
 }
+
+// WithFSGroup permit to set the group that own the volumes
+func (h *PodTemplateBuilderDefault) WithFSGroup(id int64, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithFSGroup", opts...)()
+
+	if h.podTemplate.Spec.SecurityContext != nil && h.podTemplate.Spec.SecurityContext.FSGroup != nil && IsOverwriteIfDefaultValue(opts) {
+		return h
+	}
+
+	h.own(sharedSecurityContext)
+	if h.podTemplate.Spec.SecurityContext == nil {
+		h.podTemplate.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	h.podTemplate.Spec.SecurityContext.FSGroup = pointer.Int64(id)
+
+	return h
+}
+
+// WithFSGroupChangePolicy permit to set how the volumes ownership is changed, like OnRootMismatch to speed up mount of big volumes
+func (h *PodTemplateBuilderDefault) WithFSGroupChangePolicy(policy corev1.PodFSGroupChangePolicy, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithFSGroupChangePolicy", opts...)()
+
+	if h.podTemplate.Spec.SecurityContext != nil && h.podTemplate.Spec.SecurityContext.FSGroupChangePolicy != nil && IsOverwriteIfDefaultValue(opts) {
+		return h
+	}
+
+	h.own(sharedSecurityContext)
+	if h.podTemplate.Spec.SecurityContext == nil {
+		h.podTemplate.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	h.podTemplate.Spec.SecurityContext.FSGroupChangePolicy = &policy
+
+	return h
+}
+
+// WithSupplementalGroups permit to add groups to the first process of each container
+// Groups already set are keeped, so layers can add their own groups.
+func (h *PodTemplateBuilderDefault) WithSupplementalGroups(ids ...int64) PodTemplateBuilder {
+	defer h.observe("WithSupplementalGroups")()
+
+	if len(ids) == 0 {
+		return h
+	}
+
+	h.own(sharedSecurityContext)
+	if h.podTemplate.Spec.SecurityContext == nil {
+		h.podTemplate.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	for _, id := range ids {
+		if !funk.ContainsInt64(h.podTemplate.Spec.SecurityContext.SupplementalGroups, id) {
+			h.podTemplate.Spec.SecurityContext.SupplementalGroups = append(h.podTemplate.Spec.SecurityContext.SupplementalGroups, id)
+		}
+	}
+
+	return h
+}
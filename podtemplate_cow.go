@@ -0,0 +1,129 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sharedFields is the set of pod template sub-structures that the builder share with its caller
+// Shared sub-structures are deep copied only before the builder modify them (copy on write)
+type sharedFields uint16
+
+const (
+	sharedLabels sharedFields = 1 << iota
+	sharedAnnotations
+	sharedImagePullSecrets
+	sharedTolerations
+	sharedNodeSelector
+	sharedInitContainers
+	sharedContainers
+	sharedVolumes
+	sharedAffinity
+	sharedSecurityContext
+	// sharedOthers is all other fields of pod template
+	sharedOthers
+
+	sharedAll = sharedOthers<<1 - 1
+)
+
+// shareTemplate permit to use the pod template without copy it
+// Only the top level struct is copied, all sub-structures are shared until they are modified
+func (h *PodTemplateBuilderDefault) shareTemplate(pts *corev1.PodTemplateSpec) {
+	tmpPts := *pts
+	h.podTemplate = &tmpPts
+	h.shared = sharedAll
+}
+
+// share permit to mark sub-structures as shared with the caller
+func (h *PodTemplateBuilderDefault) share(fields sharedFields) {
+	h.shared |= fields
+}
+
+// own permit to deep copy the sub-structures that are still shared, before modify them
+func (h *PodTemplateBuilderDefault) own(fields sharedFields) {
+	fields &= h.shared
+	if fields == 0 {
+		return
+	}
+	h.shared &^= fields
+
+	pts := h.podTemplate
+	if fields&sharedLabels != 0 {
+		pts.Labels = copyMap(pts.Labels)
+	}
+	if fields&sharedAnnotations != 0 {
+		pts.Annotations = copyMap(pts.Annotations)
+	}
+	if fields&sharedNodeSelector != 0 {
+		pts.Spec.NodeSelector = copyMap(pts.Spec.NodeSelector)
+	}
+	if fields&sharedImagePullSecrets != 0 && pts.Spec.ImagePullSecrets != nil {
+		pts.Spec.ImagePullSecrets = append(make([]corev1.LocalObjectReference, 0, len(pts.Spec.ImagePullSecrets)), pts.Spec.ImagePullSecrets...)
+	}
+	if fields&sharedTolerations != 0 && pts.Spec.Tolerations != nil {
+		tolerations := make([]corev1.Toleration, len(pts.Spec.Tolerations))
+		for i := range pts.Spec.Tolerations {
+			pts.Spec.Tolerations[i].DeepCopyInto(&tolerations[i])
+		}
+		pts.Spec.Tolerations = tolerations
+	}
+	if fields&sharedInitContainers != 0 {
+		pts.Spec.InitContainers = copyContainers(pts.Spec.InitContainers)
+	}
+	if fields&sharedContainers != 0 {
+		pts.Spec.Containers = copyContainers(pts.Spec.Containers)
+	}
+	if fields&sharedVolumes != 0 && pts.Spec.Volumes != nil {
+		volumes := make([]corev1.Volume, len(pts.Spec.Volumes))
+		for i := range pts.Spec.Volumes {
+			pts.Spec.Volumes[i].DeepCopyInto(&volumes[i])
+		}
+		pts.Spec.Volumes = volumes
+	}
+	if fields&sharedAffinity != 0 {
+		pts.Spec.Affinity = pts.Spec.Affinity.DeepCopy()
+	}
+	if fields&sharedSecurityContext != 0 {
+		pts.Spec.SecurityContext = pts.Spec.SecurityContext.DeepCopy()
+	}
+	if fields&sharedOthers != 0 {
+		// Deep copy all fields, without the tracked sub-structures that are keeped as is
+		others := *pts
+		others.Labels, others.Annotations = nil, nil
+		others.Spec.ImagePullSecrets, others.Spec.Tolerations, others.Spec.NodeSelector = nil, nil, nil
+		others.Spec.InitContainers, others.Spec.Containers, others.Spec.Volumes = nil, nil, nil
+		others.Spec.Affinity, others.Spec.SecurityContext = nil, nil
+		copied := others.DeepCopy()
+
+		copied.Labels, copied.Annotations = pts.Labels, pts.Annotations
+		copied.Spec.ImagePullSecrets, copied.Spec.Tolerations, copied.Spec.NodeSelector = pts.Spec.ImagePullSecrets, pts.Spec.Tolerations, pts.Spec.NodeSelector
+		copied.Spec.InitContainers, copied.Spec.Containers, copied.Spec.Volumes = pts.Spec.InitContainers, pts.Spec.Containers, pts.Spec.Volumes
+		copied.Spec.Affinity, copied.Spec.SecurityContext = pts.Spec.Affinity, pts.Spec.SecurityContext
+		*pts = *copied
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+
+	return copied
+}
+
+func copyContainers(containers []corev1.Container) []corev1.Container {
+	if containers == nil {
+		return nil
+	}
+
+	copied := make([]corev1.Container, len(containers))
+	for i := range containers {
+		containers[i].DeepCopyInto(&copied[i])
+	}
+
+	return copied
+}
@@ -0,0 +1,417 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestPodTemplateBuilderLogger(t *testing.T) {
+	logs := make([]string, 0)
+	logger := funcr.New(func(prefix, args string) {
+		logs = append(logs, args)
+	}, funcr.Options{Verbosity: 2})
+
+	_, err := NewPodTemplateBuilder().
+		WithLogger(logger).
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test"}).
+		WithRestartPolicy(corev1.RestartPolicyAlways).
+		WithRestartPolicy(corev1.RestartPolicyNever, OverwriteIfDefaultValue).
+		WithContainers([]corev1.Container{{Name: "app", Image: "nginx"}}, Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`"level"=2 "msg"="Call pod template builder" "method"="WithDefaults" "option"="overwrite" "diff"=[]`,
+		`"level"=2 "msg"="Call pod template builder" "method"="WithLabels" "option"="overwrite" "diff"=["ObjectMeta.Labels"]`,
+		`"level"=2 "msg"="Call pod template builder" "method"="WithRestartPolicy" "option"="overwrite" "diff"=["Spec.RestartPolicy"]`,
+		`"level"=2 "msg"="Call pod template builder" "method"="WithRestartPolicy" "option"="overwriteIfDefaultValue" "diff"=[]`,
+		`"level"=2 "msg"="Call pod template builder" "method"="WithContainers" "option"="merge" "diff"=["Spec.Containers"]`,
+		`"level"=2 "msg"="Call pod template builder" "method"="Build" "option"="overwrite" "diff"=[]`,
+	}, logs)
+
+	// When logger is not enabled at V(2)
+	logs = make([]string, 0)
+	NewPodTemplateBuilder().
+		WithLogger(funcr.New(func(prefix, args string) { logs = append(logs, args) }, funcr.Options{})).
+		WithLabels(map[string]string{"app": "test"})
+	assert.Empty(t, logs)
+}
+
+func TestPodTemplateBuilderDebug(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test"}).
+		WithContainers([]corev1.Container{{Name: "app", Image: "nginx"}}).
+		WithDebug().
+		WithLabels(map[string]string{"app": "other", "env": "dev"}, Merge).
+		WithContainers([]corev1.Container{{Name: "sidecar", Image: "envoy"}}, Merge).
+		WithContainers([]corev1.Container{{Name: "sidecar", Image: "envoy"}})
+
+	_, err := b.Build()
+	assert.NoError(t, err)
+	calls := b.DebugCalls()
+	assert.Len(t, calls, 4)
+
+	assert.Equal(t, "WithLabels", calls[0].Method)
+	assert.Equal(t, Merge, calls[0].Option)
+	assert.Equal(t, []FieldDiff{{Path: "ObjectMeta.Labels[env]", Before: "<none>", After: "dev"}}, calls[0].Diffs)
+
+	assert.Equal(t, "WithContainers", calls[1].Method)
+	assert.Len(t, calls[1].Diffs, 1)
+	assert.Equal(t, "Spec.Containers[1]", calls[1].Diffs[0].Path)
+	assert.Equal(t, "<none>", calls[1].Diffs[0].Before)
+
+	// The user container disappeared on overwrite
+	assert.Equal(t, Overwrite, calls[2].Option)
+	assert.Len(t, calls[2].Diffs, 1)
+	assert.Equal(t, "Spec.Containers[0]", calls[2].Diffs[0].Path)
+	assert.Equal(t, "<none>", calls[2].Diffs[0].After)
+
+	assert.Equal(t, "Build", calls[3].Method)
+	assert.Empty(t, calls[3].Diffs)
+}
+
+func TestPodTemplateBuilderDebugOutermostCall(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app", Image: "nginx"}, {Name: "sidecar", Image: "envoy"}}).
+		WithDebug().
+		WithRecommendedLabels("test", "test", "1.0.0", "server", "app", "operator").
+		MoveContainerFirst("sidecar").
+		WithLabels(map[string]string{"env": "dev"}, Merge)
+
+	_, err := b.Build()
+	assert.NoError(t, err)
+
+	methods := make([]string, 0, len(b.DebugCalls()))
+	for _, call := range b.DebugCalls() {
+		methods = append(methods, call.Method)
+	}
+	assert.Equal(t, []string{"WithRecommendedLabels", "MoveContainerFirst", "WithLabels", "Build"}, methods)
+
+	// The diff of nested calls is on outermost call
+	assert.NotEmpty(t, b.DebugCalls()[0].Diffs)
+	assert.Equal(t, "Spec.Containers[0].Name", b.DebugCalls()[1].Diffs[0].Path)
+}
+
+func TestPodTemplateBuilderCopyOnWrite(t *testing.T) {
+	base := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"app": "test"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "nginx", Args: []string{"run"}}},
+			Volumes:    []corev1.Volume{{Name: "data"}},
+			NodeSelector: map[string]string{
+				"disk": "ssd",
+			},
+		},
+	}
+	expectedBase := base.DeepCopy()
+
+	for _, nodeSet := range []string{"master", "data"} {
+		pts, err := NewPodTemplateBuilder().
+			WithDefaults(&Defaults{ImagePullPolicy: corev1.PullAlways}).
+			WithPodTemplateSpec(base).
+			WithLabels(map[string]string{"nodeSet": nodeSet}, Merge).
+			WithNodeSelector(map[string]string{"zone": nodeSet}, Merge).
+			WithContainers([]corev1.Container{{Name: "sidecar", Image: "envoy"}}, Merge).
+			WithChecksumAnnotation("checksum", nodeSet).
+			Build()
+		assert.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"app": "test", "nodeSet": nodeSet}, pts.Labels)
+		assert.Equal(t, map[string]string{"disk": "ssd", "zone": nodeSet}, pts.Spec.NodeSelector)
+		assert.Len(t, pts.Spec.Containers, 2)
+		assert.Equal(t, corev1.PullAlways, pts.Spec.Containers[0].ImagePullPolicy)
+
+		// Not modified sub-structures are shared
+		assert.Same(t, &base.Spec.Volumes[0], &pts.Spec.Volumes[0])
+		assert.NotSame(t, &base.Spec.Containers[0], &pts.Spec.Containers[0])
+	}
+
+	// Base pod template is never modified
+	assert.Equal(t, expectedBase, base)
+
+	// Merge keep the current pod template as is
+	current := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{Name: "data"}},
+		},
+	}
+	expectedCurrent := current.DeepCopy()
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(current).
+		WithPodTemplateSpec(&corev1.PodTemplateSpec{Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}}}, Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCurrent, current)
+	assert.NotNil(t, pts.Spec.Volumes[0].EmptyDir)
+}
+
+func TestPodTemplateBuilderWithImagePullSecretNames(t *testing.T) {
+	names := []string{"registry", "mirror", "registry"}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithImagePullSecretNames(names).
+		WithImagePullSecretNames([]string{"mirror", "private"}, Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}, {Name: "private"}}, pts.Spec.ImagePullSecrets)
+	assert.Equal(t, []string{"registry", "mirror", "registry"}, names)
+
+	// Input slice is copied
+	ips := []corev1.LocalObjectReference{{Name: "registry"}}
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithImagePullSecrets(ips).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, ips, pts.Spec.ImagePullSecrets)
+	ips[0].Name = "other"
+	assert.Equal(t, "registry", pts.Spec.ImagePullSecrets[0].Name)
+}
+
+func TestPodTemplateBuilderWithoutMetadata(t *testing.T) {
+	base := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "test", "debug": "true"},
+			Annotations: map[string]string{"debug.example.com/trace": "true", "foo": "bar"},
+		},
+	}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(base).
+		WithoutLabels("debug").
+		WithoutAnnotations("debug.example.com/trace").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "test"}, pts.Labels)
+	assert.Equal(t, map[string]string{"foo": "bar"}, pts.Annotations)
+
+	// Inherited pod template is not modified
+	assert.Len(t, base.Labels, 2)
+	assert.Len(t, base.Annotations, 2)
+}
+
+func TestPodTemplateBuilderWithRecommendedLabels(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithLabels(map[string]string{"app": "test", LabelVersion: "1.0.0"}).
+		WithRecommendedLabels("elasticsearch", "logs", "", "database", "", "operator").
+		Build()
+	assert.NoError(t, err)
+
+	// Labels are merged and empty values are skipped
+	assert.Equal(t, map[string]string{
+		"app":                          "test",
+		"app.kubernetes.io/name":       "elasticsearch",
+		"app.kubernetes.io/instance":   "logs",
+		"app.kubernetes.io/version":    "1.0.0",
+		"app.kubernetes.io/component":  "database",
+		"app.kubernetes.io/managed-by": "operator",
+	}, pts.Labels)
+}
+
+func TestPodTemplateBuilderWithParentMetadata(t *testing.T) {
+	parent := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "parent", "team": "ops", "internal": "true"},
+			Annotations: map[string]string{"owner": "ops", "deployment.kubernetes.io/revision": "3"},
+		},
+	}
+	base := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "test", "env": "dev"},
+			Annotations: map[string]string{"foo": "bar"},
+		},
+	}
+
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(base).
+		WithParentMetadata(parent, []string{"app", "team", "missing"}, []string{"owner"}).
+		Build()
+	assert.NoError(t, err)
+
+	// Only selected keys are copied, parent values overwrite the pod template ones, and other keys are keeped
+	assert.Equal(t, map[string]string{"app": "parent", "team": "ops", "env": "dev"}, pts.Labels)
+	assert.Equal(t, map[string]string{"owner": "ops", "foo": "bar"}, pts.Annotations)
+
+	// Inherited pod template is not modified
+	assert.Equal(t, map[string]string{"app": "test", "env": "dev"}, base.Labels)
+	assert.Equal(t, map[string]string{"foo": "bar"}, base.Annotations)
+
+	// Without keys or parent, pod template is keeped as is
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(base).
+		WithParentMetadata(parent, nil, nil).
+		WithParentMetadata(nil, []string{"app"}, []string{"owner"}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, base.Labels, pts.Labels)
+	assert.Equal(t, base.Annotations, pts.Annotations)
+
+	// Empty pod template
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithParentMetadata(parent, []string{"app"}, []string{"owner"}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "parent"}, pts.Labels)
+	assert.Equal(t, map[string]string{"owner": "ops"}, pts.Annotations)
+}
+
+func TestPodTemplateBuilderSecurityContextGroups(t *testing.T) {
+	sc := &corev1.PodSecurityContext{RunAsNonRoot: pointer.Bool(true), SupplementalGroups: []int64{10}}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithSecurityContext(sc).
+		WithFSGroup(1000).
+		WithFSGroup(2000, OverwriteIfDefaultValue).
+		WithFSGroupChangePolicy(corev1.FSGroupChangeOnRootMismatch).
+		WithSupplementalGroups(10, 20).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &corev1.PodSecurityContext{
+		RunAsNonRoot:        pointer.Bool(true),
+		FSGroup:             pointer.Int64(1000),
+		FSGroupChangePolicy: func() *corev1.PodFSGroupChangePolicy { p := corev1.FSGroupChangeOnRootMismatch; return &p }(),
+		SupplementalGroups:  []int64{10, 20},
+	}, pts.Spec.SecurityContext)
+
+	// The security context given by the caller is not modified
+	assert.Equal(t, &corev1.PodSecurityContext{RunAsNonRoot: pointer.Bool(true), SupplementalGroups: []int64{10}}, sc)
+}
+
+func TestValidatePodTemplate(t *testing.T) {
+	pts := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{Name: "data"}},
+			InitContainers: []corev1.Container{
+				{
+					Name:         "init",
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  "app",
+					Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+					EnvFrom: []corev1.EnvFromSource{
+						{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}}},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							HTTPGet: &corev1.HTTPGetAction{Port: intstr.FromString("http")},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// When valid
+	assert.Empty(t, ValidatePodTemplate(pts))
+
+	// When invalid
+	pts.Spec.InitContainers[0].Name = "app"
+	pts.Spec.InitContainers[0].VolumeMounts[0].Name = "cache"
+	pts.Spec.Containers[0].EnvFrom[0].SecretRef.Name = ""
+	pts.Spec.Containers[0].Env = []corev1.EnvVar{
+		{
+			Name: "PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}},
+			},
+		},
+	}
+	pts.Spec.Containers[0].ReadinessProbe.HTTPGet.Port = intstr.FromString("metrics")
+	pts.Spec.Containers[0].LivenessProbe = &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(8080)},
+		},
+	}
+	errs := ValidatePodTemplate(pts)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{
+		"spec.initContainers[0].volumeMounts[0].name",
+		"spec.containers[0].name",
+		"spec.containers[0].envFrom[0].secretRef.name",
+		"spec.containers[0].env[0].valueFrom.secretKeyRef.key",
+		"spec.containers[0].readinessProbe.httpGet.port",
+	}, fields)
+
+	// Build return the errors
+	_, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(pts).
+		Build()
+	assert.ErrorContains(t, err, "spec.containers[0].name")
+}
+
+func TestValidatePodTemplateConflicts(t *testing.T) {
+	pts := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "app",
+					Env:   []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}},
+					Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, HostPort: 80}},
+				},
+				{
+					Name:  "sidecar",
+					Ports: []corev1.ContainerPort{{Name: "dns", ContainerPort: 8080, Protocol: corev1.ProtocolUDP}},
+				},
+			},
+		},
+	}
+
+	// When no conflict
+	assert.Empty(t, ValidatePodTemplateConflicts(pts))
+
+	// When conflicts
+	pts.Spec.HostNetwork = true
+	pts.Spec.Containers[0].Env = append(pts.Spec.Containers[0].Env, corev1.EnvVar{Name: "LOG_LEVEL", Value: "debug"})
+	pts.Spec.Containers[1].Ports = append(pts.Spec.Containers[1].Ports, corev1.ContainerPort{Name: "admin", ContainerPort: 8080, HostPort: 80})
+	errs := ValidatePodTemplateConflicts(pts)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{
+		"spec.containers[0].env[1].name",
+		"spec.containers[0].ports[0].hostPort",
+		"spec.containers[1].ports[1].containerPort",
+		"spec.containers[1].ports[1].hostPort",
+		"spec.containers[1].ports[1].hostPort",
+	}, fields)
+
+	// Warnings by default
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(pts)
+	_, err := b.Build()
+	assert.NoError(t, err)
+	assert.Len(t, b.Warnings(), 5)
+
+	// Errors when strict
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(pts).
+		WithValidationMode(ValidationStrict).
+		Build()
+	assert.ErrorContains(t, err, "spec.containers[0].env[1].name")
+}
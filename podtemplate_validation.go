@@ -0,0 +1,211 @@
+package k8sbuilder
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidatePodTemplate permit to check the consistency of pod template before send it to API server
+// Labels and annotations must have a valid syntax, volume mounts must reference existing volumes, env sources must
+// have a name, container names must be unique across containers and init containers, and named ports used by probes
+// must exist on the container. Resource quantities must be positive and requests must not be greater than limits.
+func ValidatePodTemplate(pts *corev1.PodTemplateSpec) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+	if pts == nil {
+		return errs
+	}
+
+	errs = append(errs, validateObjectMetaLabels(pts.Labels, pts.Annotations, field.NewPath("metadata"))...)
+
+	specPath := field.NewPath("spec")
+
+	volumes := sets.NewString()
+	for _, volume := range pts.Spec.Volumes {
+		volumes.Insert(volume.Name)
+	}
+
+	names := sets.NewString()
+	validate := func(containers []corev1.Container, fldPath *field.Path) {
+		for index := range containers {
+			c := &containers[index]
+			containerPath := fldPath.Index(index)
+			if names.Has(c.Name) {
+				errs = append(errs, field.Duplicate(containerPath.Child("name"), c.Name))
+			}
+			names.Insert(c.Name)
+
+			errs = append(errs, validateContainerVolumeMounts(c, volumes, containerPath)...)
+			errs = append(errs, validateContainerEnv(c, containerPath)...)
+			errs = append(errs, validateContainerProbes(c, containerPath)...)
+			errs = append(errs, validateContainerResources(&c.Resources, containerPath.Child("resources"))...)
+		}
+	}
+	validate(pts.Spec.InitContainers, specPath.Child("initContainers"))
+	validate(pts.Spec.Containers, specPath.Child("containers"))
+
+	return errs
+}
+
+func validateContainerVolumeMounts(c *corev1.Container, volumes sets.String, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	for index, volumeMount := range c.VolumeMounts {
+		if !volumes.Has(volumeMount.Name) {
+			errs = append(errs, field.NotFound(fldPath.Child("volumeMounts").Index(index).Child("name"), volumeMount.Name))
+		}
+	}
+	for index, volumeDevice := range c.VolumeDevices {
+		if !volumes.Has(volumeDevice.Name) {
+			errs = append(errs, field.NotFound(fldPath.Child("volumeDevices").Index(index).Child("name"), volumeDevice.Name))
+		}
+	}
+
+	return errs
+}
+
+func validateContainerEnv(c *corev1.Container, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	for index, envFrom := range c.EnvFrom {
+		envFromPath := fldPath.Child("envFrom").Index(index)
+		if envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == "" {
+			errs = append(errs, field.Required(envFromPath.Child("configMapRef", "name"), "name must be specified"))
+		}
+		if envFrom.SecretRef != nil && envFrom.SecretRef.Name == "" {
+			errs = append(errs, field.Required(envFromPath.Child("secretRef", "name"), "name must be specified"))
+		}
+	}
+
+	for index, env := range c.Env {
+		if env.ValueFrom == nil {
+			continue
+		}
+		valueFromPath := fldPath.Child("env").Index(index).Child("valueFrom")
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			if ref.Name == "" {
+				errs = append(errs, field.Required(valueFromPath.Child("secretKeyRef", "name"), "name must be specified"))
+			}
+			if ref.Key == "" {
+				errs = append(errs, field.Required(valueFromPath.Child("secretKeyRef", "key"), "key must be specified"))
+			}
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			if ref.Name == "" {
+				errs = append(errs, field.Required(valueFromPath.Child("configMapKeyRef", "name"), "name must be specified"))
+			}
+			if ref.Key == "" {
+				errs = append(errs, field.Required(valueFromPath.Child("configMapKeyRef", "key"), "key must be specified"))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateContainerProbes(c *corev1.Container, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	ports := sets.NewString()
+	for _, port := range c.Ports {
+		if port.Name != "" {
+			ports.Insert(port.Name)
+		}
+	}
+
+	validatePort := func(port intstr.IntOrString, portPath *field.Path) {
+		if port.Type == intstr.String && !ports.Has(port.StrVal) {
+			errs = append(errs, field.NotFound(portPath, port.StrVal))
+		}
+	}
+
+	probes := []struct {
+		name  string
+		probe *corev1.Probe
+	}{
+		{name: "livenessProbe", probe: c.LivenessProbe},
+		{name: "readinessProbe", probe: c.ReadinessProbe},
+		{name: "startupProbe", probe: c.StartupProbe},
+	}
+	for _, p := range probes {
+		probe := p.probe
+		if probe == nil {
+			continue
+		}
+		probePath := fldPath.Child(p.name)
+		if probe.HTTPGet != nil {
+			validatePort(probe.HTTPGet.Port, probePath.Child("httpGet", "port"))
+		}
+		if probe.TCPSocket != nil {
+			validatePort(probe.TCPSocket.Port, probePath.Child("tcpSocket", "port"))
+		}
+	}
+
+	return errs
+}
+
+const (
+	// ValidationWarn log the conflicts with the builder logger and keep them as warnings
+	ValidationWarn ValidationMode = "warn"
+
+	// ValidationStrict fail the Build when there are conflicts
+	ValidationStrict ValidationMode = "strict"
+)
+
+// ValidationMode is the way to handle conflicts found on pod template
+type ValidationMode string
+
+// ValidatePodTemplateConflicts permit to find the conflicts of pod template that API server or kubelet may not reject,
+// but that break the pod at runtime: duplicate env names on container, duplicate container ports or host ports
+// across containers, and host ports that differ from container ports when host network is used.
+func ValidatePodTemplateConflicts(pts *corev1.PodTemplateSpec) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+	if pts == nil {
+		return errs
+	}
+
+	specPath := field.NewPath("spec")
+	containerPorts := sets.NewString()
+	hostPorts := sets.NewString()
+
+	for index := range pts.Spec.Containers {
+		c := &pts.Spec.Containers[index]
+		containerPath := specPath.Child("containers").Index(index)
+
+		envs := sets.NewString()
+		for indexEnv, env := range c.Env {
+			if envs.Has(env.Name) {
+				errs = append(errs, field.Duplicate(containerPath.Child("env").Index(indexEnv).Child("name"), env.Name))
+			}
+			envs.Insert(env.Name)
+		}
+
+		for indexPort, port := range c.Ports {
+			portPath := containerPath.Child("ports").Index(indexPort)
+			protocol := portProtocol(port)
+
+			key := fmt.Sprintf("%d/%s", port.ContainerPort, protocol)
+			if containerPorts.Has(key) {
+				errs = append(errs, field.Duplicate(portPath.Child("containerPort"), key))
+			}
+			containerPorts.Insert(key)
+
+			if port.HostPort != 0 {
+				key = fmt.Sprintf("%d/%s", port.HostPort, protocol)
+				if hostPorts.Has(key) {
+					errs = append(errs, field.Duplicate(portPath.Child("hostPort"), key))
+				}
+				hostPorts.Insert(key)
+
+				if pts.Spec.HostNetwork && port.HostPort != port.ContainerPort {
+					errs = append(errs, field.Invalid(portPath.Child("hostPort"), port.HostPort, "must match containerPort when hostNetwork is true"))
+				}
+			}
+		}
+	}
+
+	return errs
+}
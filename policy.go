@@ -0,0 +1,255 @@
+package k8sbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// PolicyEnforce fail the Build when policy is not respected
+	PolicyEnforce PolicyMode = "enforce"
+
+	// PolicyAutoFix fix the pod template when policy is not respected
+	// It fail the Build only if the policy can't fix the pod template
+	PolicyAutoFix PolicyMode = "autoFix"
+)
+
+// PolicyMode is the way to handle policy violations
+type PolicyMode string
+
+// Policy is a rule checked on pod template at Build
+type Policy interface {
+	// Name is the policy name, used on errors
+	Name() string
+
+	// Check return the violations of the pod template
+	Check(pts *corev1.PodTemplateSpec) (violations []string)
+
+	// Fix permit to fix the pod template
+	// It return error if the violations can't be fixed
+	Fix(pts *corev1.PodTemplateSpec) (err error)
+}
+
+// policyDefault is the default policy implementation, base on check and fix functions
+type policyDefault struct {
+	name  string
+	check func(pts *corev1.PodTemplateSpec) []string
+	fix   func(pts *corev1.PodTemplateSpec) error
+}
+
+func (h *policyDefault) Name() string {
+	return h.name
+}
+
+func (h *policyDefault) Check(pts *corev1.PodTemplateSpec) []string {
+	return h.check(pts)
+}
+
+func (h *policyDefault) Fix(pts *corev1.PodTemplateSpec) error {
+	if h.fix == nil {
+		return errors.New("Can't be fixed automatically")
+	}
+	return h.fix(pts)
+}
+
+// ApplyPolicies permit to check policies on pod template, and fix it when mode is PolicyAutoFix
+// It return all violations that are not fixed
+func ApplyPolicies(pts *corev1.PodTemplateSpec, mode PolicyMode, policies ...Policy) (err error) {
+	errs := make([]error, 0)
+
+	for _, policy := range policies {
+		violations := policy.Check(pts)
+		if len(violations) == 0 {
+			continue
+		}
+
+		if mode == PolicyAutoFix {
+			if err = policy.Fix(pts); err == nil {
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "Policy %s", policy.Name()))
+		}
+
+		for _, violation := range violations {
+			errs = append(errs, errors.Errorf("Policy %s: %s", policy.Name(), violation))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NoLatestTagPolicy refuse images with latest tag or without tag
+func NoLatestTagPolicy() Policy {
+	return &policyDefault{
+		name: "noLatestTag",
+		check: func(pts *corev1.PodTemplateSpec) []string {
+			violations := make([]string, 0)
+			for _, c := range allContainers(pts) {
+				if tag, hasDigest := imageTag(c.Image); !hasDigest && (tag == "" || tag == "latest") {
+					violations = append(violations, fmt.Sprintf("container %s use image %s without fixed tag", c.Name, c.Image))
+				}
+			}
+			return violations
+		},
+	}
+}
+
+// NoPrivilegedPolicy refuse privileged containers
+// On auto fix, it set privileged to false
+func NoPrivilegedPolicy() Policy {
+	return &policyDefault{
+		name: "noPrivileged",
+		check: func(pts *corev1.PodTemplateSpec) []string {
+			violations := make([]string, 0)
+			for _, c := range allContainers(pts) {
+				if c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged {
+					violations = append(violations, fmt.Sprintf("container %s is privileged", c.Name))
+				}
+			}
+			return violations
+		},
+		fix: func(pts *corev1.PodTemplateSpec) error {
+			for _, c := range allContainers(pts) {
+				if c.SecurityContext != nil && c.SecurityContext.Privileged != nil {
+					c.SecurityContext.Privileged = pointer.Bool(false)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// ResourcesRequiredPolicy require cpu and memory requests and memory limit on all containers
+// On auto fix, it set the missing values from defaults. It can't fix without defaults.
+func ResourcesRequiredPolicy(defaults *corev1.ResourceRequirements) Policy {
+	policy := &policyDefault{
+		name: "resourcesRequired",
+		check: func(pts *corev1.PodTemplateSpec) []string {
+			violations := make([]string, 0)
+			for _, c := range allContainers(pts) {
+				for _, name := range missingResources(c) {
+					violations = append(violations, fmt.Sprintf("container %s not have %s", c.Name, name))
+				}
+			}
+			return violations
+		},
+	}
+
+	if defaults != nil {
+		policy.fix = func(pts *corev1.PodTemplateSpec) error {
+			for _, c := range allContainers(pts) {
+				if c.Resources.Requests == nil {
+					c.Resources.Requests = corev1.ResourceList{}
+				}
+				if c.Resources.Limits == nil {
+					c.Resources.Limits = corev1.ResourceList{}
+				}
+				for name, quantity := range defaults.Requests {
+					if _, ok := c.Resources.Requests[name]; !ok {
+						c.Resources.Requests[name] = quantity.DeepCopy()
+					}
+				}
+				for name, quantity := range defaults.Limits {
+					if _, ok := c.Resources.Limits[name]; !ok {
+						c.Resources.Limits[name] = quantity.DeepCopy()
+					}
+				}
+				if missing := missingResources(c); len(missing) > 0 {
+					return errors.Errorf("Defaults not contain %s", strings.Join(missing, ", "))
+				}
+			}
+			return nil
+		}
+	}
+
+	return policy
+}
+
+// AllowedRegistriesPolicy refuse images that not come from allowed registries
+// Images without registry come from docker.io
+func AllowedRegistriesPolicy(registries ...string) Policy {
+	return &policyDefault{
+		name: "allowedRegistries",
+		check: func(pts *corev1.PodTemplateSpec) []string {
+			violations := make([]string, 0)
+		loopContainer:
+			for _, c := range allContainers(pts) {
+				registry := imageRegistry(c.Image)
+				for _, allowedRegistry := range registries {
+					if registry == allowedRegistry {
+						continue loopContainer
+					}
+				}
+				violations = append(violations, fmt.Sprintf("container %s use image from not allowed registry %s", c.Name, registry))
+			}
+			return violations
+		},
+	}
+}
+
+// allContainers permit to get pointer on all init containers and containers
+func allContainers(pts *corev1.PodTemplateSpec) []*corev1.Container {
+	containers := make([]*corev1.Container, 0, len(pts.Spec.InitContainers)+len(pts.Spec.Containers))
+	for i := range pts.Spec.InitContainers {
+		containers = append(containers, &pts.Spec.InitContainers[i])
+	}
+	for i := range pts.Spec.Containers {
+		containers = append(containers, &pts.Spec.Containers[i])
+	}
+
+	return containers
+}
+
+// missingResources return the required resources not set on container
+func missingResources(c *corev1.Container) []string {
+	missing := make([]string, 0)
+	if _, ok := c.Resources.Requests[corev1.ResourceCPU]; !ok {
+		missing = append(missing, "cpu request")
+	}
+	if _, ok := c.Resources.Requests[corev1.ResourceMemory]; !ok {
+		missing = append(missing, "memory request")
+	}
+	if _, ok := c.Resources.Limits[corev1.ResourceMemory]; !ok {
+		missing = append(missing, "memory limit")
+	}
+
+	return missing
+}
+
+// imageTag permit to get the tag of image and if it use digest
+func imageTag(image string) (tag string, hasDigest bool) {
+	if index := strings.Index(image, "@"); index != -1 {
+		image = image[:index]
+		hasDigest = true
+	}
+
+	name := image
+	if index := strings.LastIndex(image, "/"); index != -1 {
+		name = image[index+1:]
+	}
+	if index := strings.LastIndex(name, ":"); index != -1 {
+		tag = name[index+1:]
+	}
+
+	return tag, hasDigest
+}
+
+// imageRegistry permit to get the registry of image
+func imageRegistry(image string) string {
+	index := strings.Index(image, "/")
+	if index == -1 {
+		return "docker.io"
+	}
+
+	registry := image[:index]
+	if strings.ContainsAny(registry, ".:") || registry == "localhost" {
+		return registry
+	}
+
+	return "docker.io"
+}
@@ -0,0 +1,62 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+func TestPolicies(t *testing.T) {
+	containers := []corev1.Container{
+		{
+			Name:  "latest",
+			Image: "nginx",
+			SecurityContext: &corev1.SecurityContext{
+				Privileged: pointer.Bool(true),
+			},
+		},
+		{
+			Name:  "registry",
+			Image: "quay.io/test/nginx:1.0",
+		},
+	}
+	defaults := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+
+	// When enforce
+	_, err := NewPodTemplateBuilder().
+		WithContainers(containers).
+		WithPolicies(PolicyEnforce, NoLatestTagPolicy(), NoPrivilegedPolicy(), ResourcesRequiredPolicy(defaults), AllowedRegistriesPolicy("docker.io")).
+		Build()
+	assert.ErrorContains(t, err, "noLatestTag: container latest")
+	assert.ErrorContains(t, err, "noPrivileged: container latest")
+	assert.ErrorContains(t, err, "resourcesRequired: container registry not have cpu request")
+	assert.ErrorContains(t, err, "allowedRegistries: container registry use image from not allowed registry quay.io")
+
+	// When auto fix
+	pts, err := NewPodTemplateBuilder().
+		WithContainers(containers).
+		WithPolicies(PolicyAutoFix, NoPrivilegedPolicy(), ResourcesRequiredPolicy(defaults), AllowedRegistriesPolicy("docker.io", "quay.io")).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Bool(false), pts.Spec.Containers[0].SecurityContext.Privileged)
+	assert.Equal(t, *defaults, pts.Spec.Containers[1].Resources)
+
+	// When auto fix not possible
+	_, err = NewPodTemplateBuilder().
+		WithContainers(containers).
+		WithPolicies(PolicyAutoFix, NoLatestTagPolicy(), ResourcesRequiredPolicy(nil)).
+		Build()
+	assert.ErrorContains(t, err, "noLatestTag")
+	assert.ErrorContains(t, err, "resourcesRequired")
+}
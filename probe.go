@@ -0,0 +1,63 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// defaultLivenessInitialDelaySeconds is the initial delay of derived liveness probe, to not kill slow starting containers
+const defaultLivenessInitialDelaySeconds = 30
+
+// DefaultProbesFromPorts permit to set TCP readiness and liveness probes on containers without probes
+// Probes check the first named port of container. Containers without named port or with one probe at least are keeped as is.
+// Init containers are not handled, because of they not support probes.
+func DefaultProbesFromPorts(pts *corev1.PodTemplateSpec) {
+	if pts == nil {
+		return
+	}
+
+	for i := range pts.Spec.Containers {
+		c := &pts.Spec.Containers[i]
+		if c.LivenessProbe != nil || c.ReadinessProbe != nil || c.StartupProbe != nil {
+			continue
+		}
+
+		portName := firstNamedPort(c.Ports)
+		if portName == "" {
+			continue
+		}
+
+		c.ReadinessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(portName)},
+			},
+		}
+		c.LivenessProbe = &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(portName)},
+			},
+			InitialDelaySeconds: defaultLivenessInitialDelaySeconds,
+		}
+	}
+}
+
+// firstNamedPort permit to get the name of first TCP named port, or empty string
+func firstNamedPort(ports []corev1.ContainerPort) string {
+	for _, port := range ports {
+		if port.Name != "" && portProtocol(port) == corev1.ProtocolTCP {
+			return port.Name
+		}
+	}
+
+	return ""
+}
+
+// WithDefaultProbes permit to derive TCP readiness and liveness probes from container ports on Build, for containers without probes
+// See DefaultProbesFromPorts.
+func (h *PodTemplateBuilderDefault) WithDefaultProbes() PodTemplateBuilder {
+	defer h.observe("WithDefaultProbes")()
+
+	h.defaultProbes = true
+
+	return h
+}
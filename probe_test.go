@@ -0,0 +1,53 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDefaultProbes(t *testing.T) {
+	readiness := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"true"}}}}
+	containers := []corev1.Container{
+		{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{ContainerPort: 9090}, {Name: "dns", ContainerPort: 53, Protocol: corev1.ProtocolUDP}, {Name: "http", ContainerPort: 8080}},
+		},
+		{
+			Name:           "probed",
+			Ports:          []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			ReadinessProbe: readiness,
+		},
+		{
+			Name:  "unnamed",
+			Ports: []corev1.ContainerPort{{ContainerPort: 8080}},
+		},
+	}
+
+	// Disabled by default
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers(containers).
+		Build()
+	assert.NoError(t, err)
+	assert.Nil(t, pts.Spec.Containers[0].ReadinessProbe)
+	assert.Nil(t, pts.Spec.Containers[0].LivenessProbe)
+
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithDefaultProbes().
+		WithContainers(containers).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, intstr.FromString("http"), pts.Spec.Containers[0].ReadinessProbe.TCPSocket.Port)
+	assert.Equal(t, intstr.FromString("http"), pts.Spec.Containers[0].LivenessProbe.TCPSocket.Port)
+	assert.Equal(t, int32(defaultLivenessInitialDelaySeconds), pts.Spec.Containers[0].LivenessProbe.InitialDelaySeconds)
+	assert.Equal(t, readiness.Exec, pts.Spec.Containers[1].ReadinessProbe.Exec)
+	assert.Nil(t, pts.Spec.Containers[1].LivenessProbe)
+	assert.Nil(t, pts.Spec.Containers[2].ReadinessProbe)
+
+	// Original containers are not modified
+	assert.Nil(t, containers[0].ReadinessProbe)
+}
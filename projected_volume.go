@@ -0,0 +1,125 @@
+package k8sbuilder
+
+import (
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// ProjectedVolumeBuilder is the builder interface of projected volume
+// Sources are merged by identity: path for service account token, name for configMap and secret.
+type ProjectedVolumeBuilder interface {
+	WithDefaultMode(mode int32) ProjectedVolumeBuilder
+	WithServiceAccountToken(path string, audience string, expirationSeconds int64) ProjectedVolumeBuilder
+	WithConfigMap(name string, items ...corev1.KeyToPath) ProjectedVolumeBuilder
+	WithSecret(name string, items ...corev1.KeyToPath) ProjectedVolumeBuilder
+	Volume() *corev1.Volume
+}
+
+// ProjectedVolumeBuilderDefault is the default implementation of projected volume builder
+type ProjectedVolumeBuilderDefault struct {
+	volume *corev1.Volume
+}
+
+// NewProjectedVolumeBuilder permit to init projected volume builder
+// Add the volume on pod template with WithVolumes
+func NewProjectedVolumeBuilder(name string) ProjectedVolumeBuilder {
+	return &ProjectedVolumeBuilderDefault{
+		volume: &corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{},
+			},
+		},
+	}
+}
+
+// Volume permit to get current volume
+func (h *ProjectedVolumeBuilderDefault) Volume() *corev1.Volume {
+	return h.volume
+}
+
+// WithDefaultMode permit to set the default mode of projected files
+func (h *ProjectedVolumeBuilderDefault) WithDefaultMode(mode int32) ProjectedVolumeBuilder {
+	h.volume.Projected.DefaultMode = pointer.Int32(mode)
+
+	return h
+}
+
+// WithServiceAccountToken permit to project service account token on path
+// If expirationSeconds is 0, the API server default is used
+func (h *ProjectedVolumeBuilderDefault) WithServiceAccountToken(path string, audience string, expirationSeconds int64) ProjectedVolumeBuilder {
+	token := &corev1.ServiceAccountTokenProjection{
+		Path:     path,
+		Audience: audience,
+	}
+	if expirationSeconds > 0 {
+		token.ExpirationSeconds = pointer.Int64(expirationSeconds)
+	}
+
+	index := funk.IndexOf(h.volume.Projected.Sources, func(o corev1.VolumeProjection) bool {
+		return o.ServiceAccountToken != nil && o.ServiceAccountToken.Path == path
+	})
+	if index == -1 {
+		h.volume.Projected.Sources = append(h.volume.Projected.Sources, corev1.VolumeProjection{ServiceAccountToken: token})
+	} else {
+		h.volume.Projected.Sources[index].ServiceAccountToken = token
+	}
+
+	return h
+}
+
+// WithConfigMap permit to project configMap
+// If no items, all keys are projected. Items are merged by key.
+func (h *ProjectedVolumeBuilderDefault) WithConfigMap(name string, items ...corev1.KeyToPath) ProjectedVolumeBuilder {
+	index := funk.IndexOf(h.volume.Projected.Sources, func(o corev1.VolumeProjection) bool {
+		return o.ConfigMap != nil && o.ConfigMap.Name == name
+	})
+	if index == -1 {
+		h.volume.Projected.Sources = append(h.volume.Projected.Sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Items:                mergeKeyToPath(nil, items),
+			},
+		})
+	} else {
+		h.volume.Projected.Sources[index].ConfigMap.Items = mergeKeyToPath(h.volume.Projected.Sources[index].ConfigMap.Items, items)
+	}
+
+	return h
+}
+
+// WithSecret permit to project secret
+// If no items, all keys are projected. Items are merged by key.
+func (h *ProjectedVolumeBuilderDefault) WithSecret(name string, items ...corev1.KeyToPath) ProjectedVolumeBuilder {
+	index := funk.IndexOf(h.volume.Projected.Sources, func(o corev1.VolumeProjection) bool {
+		return o.Secret != nil && o.Secret.Name == name
+	})
+	if index == -1 {
+		h.volume.Projected.Sources = append(h.volume.Projected.Sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Items:                mergeKeyToPath(nil, items),
+			},
+		})
+	} else {
+		h.volume.Projected.Sources[index].Secret.Items = mergeKeyToPath(h.volume.Projected.Sources[index].Secret.Items, items)
+	}
+
+	return h
+}
+
+func mergeKeyToPath(dst []corev1.KeyToPath, items []corev1.KeyToPath) []corev1.KeyToPath {
+	for _, item := range items {
+		index := funk.IndexOf(dst, func(o corev1.KeyToPath) bool {
+			return item.Key == o.Key
+		})
+		if index == -1 {
+			dst = append(dst, *item.DeepCopy())
+		} else {
+			dst[index] = *item.DeepCopy()
+		}
+	}
+
+	return dst
+}
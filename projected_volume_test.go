@@ -0,0 +1,52 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestProjectedVolumeBuilder(t *testing.T) {
+	v := NewProjectedVolumeBuilder("config").
+		WithDefaultMode(0440).
+		WithServiceAccountToken("token", "vault", 0).
+		WithConfigMap("app", corev1.KeyToPath{Key: "app.yaml", Path: "app.yaml"}).
+		WithSecret("credentials").
+		WithServiceAccountToken("token", "vault", 3600).
+		WithConfigMap("app", corev1.KeyToPath{Key: "app.yaml", Path: "config/app.yaml"}, corev1.KeyToPath{Key: "log.yaml", Path: "log.yaml"}).
+		Volume()
+
+	assert.Equal(t, &corev1.Volume{
+		Name: "config",
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				DefaultMode: pointer.Int32(0440),
+				Sources: []corev1.VolumeProjection{
+					{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Path:              "token",
+							Audience:          "vault",
+							ExpirationSeconds: pointer.Int64(3600),
+						},
+					},
+					{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "app"},
+							Items: []corev1.KeyToPath{
+								{Key: "app.yaml", Path: "config/app.yaml"},
+								{Key: "log.yaml", Path: "log.yaml"},
+							},
+						},
+					},
+					{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+						},
+					},
+				},
+			},
+		},
+	}, v)
+}
@@ -0,0 +1,287 @@
+package k8sbuilder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// PSSPrivileged is the unrestricted Pod Security Standards profile
+	PSSPrivileged PSSLevel = "privileged"
+
+	// PSSBaseline is the Pod Security Standards profile that prevent known privilege escalations
+	PSSBaseline PSSLevel = "baseline"
+
+	// PSSRestricted is the Pod Security Standards profile that follow the pod hardening best practices
+	// It include the baseline profile.
+	PSSRestricted PSSLevel = "restricted"
+)
+
+// PSSLevel is a Pod Security Standards profile, like set on pod-security.kubernetes.io/enforce namespace label
+type PSSLevel string
+
+var (
+	pssBaselineCapabilities = []string{
+		"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE",
+		"SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+	}
+	pssSafeSysctls = []string{
+		"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range",
+	}
+	pssSELinuxTypes = []string{"", "container_t", "container_init_t", "container_kvm_t"}
+)
+
+// pssContainer is a container with its field path
+type pssContainer struct {
+	container *corev1.Container
+	path      *field.Path
+}
+
+// AuditPSS permit to get the violations of pod template against the Pod Security Standards profile
+// Use it to warn users before namespaces with PSS enforcement reject the pods. The privileged profile never has violations.
+// See https://kubernetes.io/docs/concepts/security/pod-security-standards/
+func AuditPSS(pts *corev1.PodTemplateSpec, level PSSLevel) (violations field.ErrorList) {
+	violations = field.ErrorList{}
+	if pts == nil || (level != PSSBaseline && level != PSSRestricted) {
+		return violations
+	}
+
+	specPath := field.NewPath("spec")
+	containers := make([]pssContainer, 0, len(pts.Spec.InitContainers)+len(pts.Spec.Containers))
+	for i := range pts.Spec.InitContainers {
+		containers = append(containers, pssContainer{container: &pts.Spec.InitContainers[i], path: specPath.Child("initContainers").Index(i)})
+	}
+	for i := range pts.Spec.Containers {
+		containers = append(containers, pssContainer{container: &pts.Spec.Containers[i], path: specPath.Child("containers").Index(i)})
+	}
+
+	violations = append(violations, auditPSSBaseline(pts, containers)...)
+	if level == PSSRestricted {
+		violations = append(violations, auditPSSRestricted(pts, containers)...)
+	}
+
+	return violations
+}
+
+// AuditPSS permit to build the pod template and get its violations against the Pod Security Standards profile
+// See AuditPSS.
+func (h *PodTemplateBuilderDefault) AuditPSS(level PSSLevel) (violations field.ErrorList, err error) {
+	pts, err := h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return AuditPSS(pts, level), nil
+}
+
+// PSSPolicy refuse pod templates that not respect the Pod Security Standards profile
+// It can't be fixed automatically.
+func PSSPolicy(level PSSLevel) Policy {
+	return &policyDefault{
+		name: fmt.Sprintf("pss-%s", level),
+		check: func(pts *corev1.PodTemplateSpec) []string {
+			violations := make([]string, 0)
+			for _, violation := range AuditPSS(pts, level) {
+				violations = append(violations, violation.Error())
+			}
+			return violations
+		},
+	}
+}
+
+func auditPSSBaseline(pts *corev1.PodTemplateSpec, containers []pssContainer) (violations field.ErrorList) {
+	violations = field.ErrorList{}
+	specPath := field.NewPath("spec")
+	forbidden := func(fldPath *field.Path, detail string) {
+		violations = append(violations, field.Forbidden(fldPath, detail+" (baseline)"))
+	}
+
+	// Host namespaces
+	if pts.Spec.HostNetwork {
+		forbidden(specPath.Child("hostNetwork"), "host network is not allowed")
+	}
+	if pts.Spec.HostPID {
+		forbidden(specPath.Child("hostPID"), "host PID namespace is not allowed")
+	}
+	if pts.Spec.HostIPC {
+		forbidden(specPath.Child("hostIPC"), "host IPC namespace is not allowed")
+	}
+
+	// Pod security context
+	if sc := pts.Spec.SecurityContext; sc != nil {
+		scPath := specPath.Child("securityContext")
+		if sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+			forbidden(scPath.Child("windowsOptions", "hostProcess"), "host process is not allowed")
+		}
+		if sc.SELinuxOptions != nil {
+			auditPSSSELinux(sc.SELinuxOptions, scPath.Child("seLinuxOptions"), forbidden)
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			forbidden(scPath.Child("seccompProfile", "type"), "unconfined seccomp profile is not allowed")
+		}
+		for i, sysctl := range sc.Sysctls {
+			if !funk.ContainsString(pssSafeSysctls, sysctl.Name) {
+				forbidden(scPath.Child("sysctls").Index(i).Child("name"), fmt.Sprintf("unsafe sysctl %s is not allowed", sysctl.Name))
+			}
+		}
+	}
+
+	// Volumes
+	for i, volume := range pts.Spec.Volumes {
+		if volume.HostPath != nil {
+			forbidden(specPath.Child("volumes").Index(i).Child("hostPath"), fmt.Sprintf("hostPath volume %s is not allowed", volume.Name))
+		}
+	}
+
+	// AppArmor
+	keys := make([]string, 0, len(pts.Annotations))
+	for key := range pts.Annotations {
+		if strings.HasPrefix(key, corev1.AppArmorBetaContainerAnnotationKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := pts.Annotations[key]; value != corev1.AppArmorBetaProfileRuntimeDefault && !strings.HasPrefix(value, corev1.AppArmorBetaProfileNamePrefix) {
+			forbidden(field.NewPath("metadata", "annotations").Key(key), fmt.Sprintf("AppArmor profile %s is not allowed", value))
+		}
+	}
+
+	for _, pc := range containers {
+		c := pc.container
+		for i, port := range c.Ports {
+			if port.HostPort != 0 {
+				forbidden(pc.path.Child("ports").Index(i).Child("hostPort"), fmt.Sprintf("host port %d is not allowed", port.HostPort))
+			}
+		}
+
+		sc := c.SecurityContext
+		if sc == nil {
+			continue
+		}
+		scPath := pc.path.Child("securityContext")
+		if sc.Privileged != nil && *sc.Privileged {
+			forbidden(scPath.Child("privileged"), fmt.Sprintf("container %s must not be privileged", c.Name))
+		}
+		if sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+			forbidden(scPath.Child("windowsOptions", "hostProcess"), "host process is not allowed")
+		}
+		if sc.Capabilities != nil {
+			for i, capability := range sc.Capabilities.Add {
+				if !funk.ContainsString(pssBaselineCapabilities, string(capability)) {
+					forbidden(scPath.Child("capabilities", "add").Index(i), fmt.Sprintf("capability %s is not allowed", capability))
+				}
+			}
+		}
+		if sc.SELinuxOptions != nil {
+			auditPSSSELinux(sc.SELinuxOptions, scPath.Child("seLinuxOptions"), forbidden)
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			forbidden(scPath.Child("procMount"), fmt.Sprintf("proc mount %s is not allowed", *sc.ProcMount))
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			forbidden(scPath.Child("seccompProfile", "type"), "unconfined seccomp profile is not allowed")
+		}
+	}
+
+	return violations
+}
+
+func auditPSSSELinux(options *corev1.SELinuxOptions, fldPath *field.Path, forbidden func(fldPath *field.Path, detail string)) {
+	if !funk.ContainsString(pssSELinuxTypes, options.Type) {
+		forbidden(fldPath.Child("type"), fmt.Sprintf("SELinux type %s is not allowed", options.Type))
+	}
+	if options.User != "" {
+		forbidden(fldPath.Child("user"), "custom SELinux user is not allowed")
+	}
+	if options.Role != "" {
+		forbidden(fldPath.Child("role"), "custom SELinux role is not allowed")
+	}
+}
+
+func auditPSSRestricted(pts *corev1.PodTemplateSpec, containers []pssContainer) (violations field.ErrorList) {
+	violations = field.ErrorList{}
+	specPath := field.NewPath("spec")
+	forbidden := func(fldPath *field.Path, detail string) {
+		violations = append(violations, field.Forbidden(fldPath, detail+" (restricted)"))
+	}
+	required := func(fldPath *field.Path, detail string) {
+		violations = append(violations, field.Required(fldPath, detail+" (restricted)"))
+	}
+
+	// Volume types
+	for i, volume := range pts.Spec.Volumes {
+		source := volume.VolumeSource
+		if source.ConfigMap == nil && source.CSI == nil && source.DownwardAPI == nil && source.EmptyDir == nil &&
+			source.Ephemeral == nil && source.PersistentVolumeClaim == nil && source.Projected == nil && source.Secret == nil &&
+			source.HostPath == nil {
+			forbidden(specPath.Child("volumes").Index(i), fmt.Sprintf("volume %s has a type not allowed", volume.Name))
+		}
+	}
+
+	podSC := pts.Spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	podSCPath := specPath.Child("securityContext")
+	if podSC.RunAsUser != nil && *podSC.RunAsUser == 0 {
+		forbidden(podSCPath.Child("runAsUser"), "run as root user is not allowed")
+	}
+	if podSC.RunAsNonRoot != nil && !*podSC.RunAsNonRoot {
+		forbidden(podSCPath.Child("runAsNonRoot"), "runAsNonRoot must not be false")
+	}
+
+	for _, pc := range containers {
+		c := pc.container
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		scPath := pc.path.Child("securityContext")
+
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			required(scPath.Child("allowPrivilegeEscalation"), fmt.Sprintf("container %s must set allowPrivilegeEscalation to false", c.Name))
+		}
+
+		runAsNonRoot := podSC.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if runAsNonRoot == nil {
+			required(scPath.Child("runAsNonRoot"), fmt.Sprintf("container %s or pod must set runAsNonRoot to true", c.Name))
+		} else if sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+			forbidden(scPath.Child("runAsNonRoot"), "runAsNonRoot must not be false")
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			forbidden(scPath.Child("runAsUser"), "run as root user is not allowed")
+		}
+
+		seccompProfile := podSC.SeccompProfile
+		if sc.SeccompProfile != nil {
+			seccompProfile = sc.SeccompProfile
+		}
+		if seccompProfile == nil {
+			required(scPath.Child("seccompProfile"), fmt.Sprintf("container %s or pod must set seccomp profile to RuntimeDefault or Localhost", c.Name))
+		}
+
+		if sc.Capabilities == nil || !funk.Contains(sc.Capabilities.Drop, corev1.Capability("ALL")) {
+			required(scPath.Child("capabilities", "drop"), fmt.Sprintf("container %s must drop ALL capabilities", c.Name))
+		}
+		if sc.Capabilities != nil {
+			// Capabilities not allowed by baseline are already reported
+			for i, capability := range sc.Capabilities.Add {
+				if capability != "NET_BIND_SERVICE" && funk.ContainsString(pssBaselineCapabilities, string(capability)) {
+					forbidden(scPath.Child("capabilities", "add").Index(i), fmt.Sprintf("capability %s is not allowed", capability))
+				}
+			}
+		}
+	}
+
+	return violations
+}
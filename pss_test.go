@@ -0,0 +1,101 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestAuditPSS(t *testing.T) {
+	pts := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			HostNetwork: true,
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "docker", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}},
+			},
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: pointer.Bool(true),
+						Capabilities: &corev1.Capabilities{
+							Add: []corev1.Capability{"SYS_ADMIN", "CHOWN"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	fields := func(errs field.ErrorList) []string {
+		paths := make([]string, 0, len(errs))
+		for _, err := range errs {
+			paths = append(paths, err.Field)
+		}
+		return paths
+	}
+
+	// Privileged profile allow all
+	assert.Empty(t, AuditPSS(pts, PSSPrivileged))
+
+	// Baseline
+	assert.Equal(t, []string{
+		"spec.hostNetwork",
+		"spec.volumes[1].hostPath",
+		"spec.containers[0].securityContext.privileged",
+		"spec.containers[0].securityContext.capabilities.add[0]",
+	}, fields(AuditPSS(pts, PSSBaseline)))
+
+	// Restricted include baseline
+	assert.Equal(t, []string{
+		"spec.hostNetwork",
+		"spec.volumes[1].hostPath",
+		"spec.containers[0].securityContext.privileged",
+		"spec.containers[0].securityContext.capabilities.add[0]",
+		"spec.containers[0].securityContext.allowPrivilegeEscalation",
+		"spec.containers[0].securityContext.runAsNonRoot",
+		"spec.containers[0].securityContext.seccompProfile",
+		"spec.containers[0].securityContext.capabilities.drop",
+		"spec.containers[0].securityContext.capabilities.add[1]",
+	}, fields(AuditPSS(pts, PSSRestricted)))
+
+	// Compliant pod template
+	compliant := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   pointer.Bool(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: pointer.Bool(false),
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+							Add:  []corev1.Capability{"NET_BIND_SERVICE"},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Empty(t, AuditPSS(compliant, PSSRestricted))
+
+	// From builder
+	violations, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithPodTemplateSpec(pts).
+		AuditPSS(PSSBaseline)
+	assert.NoError(t, err)
+	assert.Len(t, violations, 4)
+
+	// As policy
+	err = ApplyPolicies(pts.DeepCopy(), PolicyEnforce, PSSPolicy(PSSBaseline))
+	assert.ErrorContains(t, err, "spec.hostNetwork")
+	assert.NoError(t, ApplyPolicies(compliant.DeepCopy(), PolicyEnforce, PSSPolicy(PSSRestricted)))
+}
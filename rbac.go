@@ -0,0 +1,420 @@
+package k8sbuilder
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// LabelAggregateToPrefix is the label prefix used to aggregate cluster role into another one
+	LabelAggregateToPrefix = "rbac.authorization.k8s.io/aggregate-to-"
+)
+
+// AggregateToLabel permit to get the label key that aggregate cluster role into role, like rbac.authorization.k8s.io/aggregate-to-admin
+func AggregateToLabel(role string) string {
+	return LabelAggregateToPrefix + role
+}
+
+// AggregateToSelector permit to get the selector that match all cluster roles aggregated into role
+func AggregateToSelector(role string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			AggregateToLabel(role): "true",
+		},
+	}
+}
+
+// RoleBuilder is the role builder interface
+type RoleBuilder interface {
+	WithName(name string, opts ...WithOption) RoleBuilder
+	WithNamespace(namespace string, opts ...WithOption) RoleBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) RoleBuilder
+	WithoutLabels(keys ...string) RoleBuilder
+	WithFinalizer(name string) RoleBuilder
+	WithoutFinalizer(name string) RoleBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) RoleBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) RoleBuilder
+	WithoutAnnotations(keys ...string) RoleBuilder
+	WithRules(rules []rbacv1.PolicyRule, opts ...WithOption) RoleBuilder
+	Allow(verbs ...string) PolicyRuleBuilder
+	WithDefaults(defaults *Defaults) RoleBuilder
+	Role() *rbacv1.Role
+	Build() (r *rbacv1.Role, err error)
+}
+
+// ClusterRoleBuilder is the cluster role builder interface
+type ClusterRoleBuilder interface {
+	WithName(name string, opts ...WithOption) ClusterRoleBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) ClusterRoleBuilder
+	WithoutLabels(keys ...string) ClusterRoleBuilder
+	WithFinalizer(name string) ClusterRoleBuilder
+	WithoutFinalizer(name string) ClusterRoleBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ClusterRoleBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) ClusterRoleBuilder
+	WithoutAnnotations(keys ...string) ClusterRoleBuilder
+	WithRules(rules []rbacv1.PolicyRule, opts ...WithOption) ClusterRoleBuilder
+	WithAggregationRule(selectors ...metav1.LabelSelector) ClusterRoleBuilder
+	AggregateFrom(roles ...string) ClusterRoleBuilder
+	AggregateTo(roles ...string) ClusterRoleBuilder
+	Allow(verbs ...string) PolicyRuleBuilder
+	WithDefaults(defaults *Defaults) ClusterRoleBuilder
+	ClusterRole() *rbacv1.ClusterRole
+	Build() (cr *rbacv1.ClusterRole, err error)
+}
+
+// RoleBuilderDefault is the default implementation of role builder
+type RoleBuilderDefault struct {
+	objectMeta
+	role     *rbacv1.Role
+	rules    []PolicyRuleBuilder
+	defaults *Defaults
+	err      error
+}
+
+// ClusterRoleBuilderDefault is the default implementation of cluster role builder
+type ClusterRoleBuilderDefault struct {
+	objectMeta
+	clusterRole *rbacv1.ClusterRole
+	rules       []PolicyRuleBuilder
+	defaults    *Defaults
+	err         error
+}
+
+// NewRoleBuilder permit to init role builder
+func NewRoleBuilder() RoleBuilder {
+	role := &rbacv1.Role{}
+
+	return &RoleBuilderDefault{
+		objectMeta: newObjectMeta(role),
+		role:       role,
+		rules:      make([]PolicyRuleBuilder, 0),
+	}
+}
+
+// NewClusterRoleBuilder permit to init cluster role builder
+func NewClusterRoleBuilder() ClusterRoleBuilder {
+	clusterRole := &rbacv1.ClusterRole{}
+
+	return &ClusterRoleBuilderDefault{
+		objectMeta:  newObjectMeta(clusterRole),
+		clusterRole: clusterRole,
+		rules:       make([]PolicyRuleBuilder, 0),
+	}
+}
+
+// Role permit to get current role
+func (h *RoleBuilderDefault) Role() *rbacv1.Role {
+	return h.role
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *RoleBuilderDefault) WithDefaults(defaults *Defaults) RoleBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Allow permit to add rule with the fluent rule builder
+// The rule is added on Build
+func (h *RoleBuilderDefault) Allow(verbs ...string) PolicyRuleBuilder {
+	rule := NewPolicyRuleBuilder(verbs...)
+	h.rules = append(h.rules, rule)
+
+	return rule
+}
+
+// Build permit to get the role
+// Rules are compacted into a minimal set
+func (h *RoleBuilderDefault) Build() (r *rbacv1.Role, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	for _, rule := range h.rules {
+		h.role.Rules = append(h.role.Rules, *rule.Rule())
+	}
+	h.rules = make([]PolicyRuleBuilder, 0)
+	h.role.Rules = CompactPolicyRules(h.role.Rules)
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.role)
+
+	if errs := ValidateMetadata(h.role); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.role, nil
+}
+
+// WithName permit to set name
+func (h *RoleBuilderDefault) WithName(name string, opts ...WithOption) RoleBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *RoleBuilderDefault) WithNamespace(namespace string, opts ...WithOption) RoleBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *RoleBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) RoleBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *RoleBuilderDefault) WithoutLabels(keys ...string) RoleBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *RoleBuilderDefault) WithFinalizer(name string) RoleBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *RoleBuilderDefault) WithoutFinalizer(name string) RoleBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *RoleBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) RoleBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *RoleBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) RoleBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *RoleBuilderDefault) WithoutAnnotations(keys ...string) RoleBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithRules permit to set rules
+// On merge, rules are appended and compacted on Build
+func (h *RoleBuilderDefault) WithRules(rules []rbacv1.PolicyRule, opts ...WithOption) RoleBuilder {
+	h.role.Rules = withPolicyRules(h.role.Rules, rules, opts...)
+
+	return h
+}
+
+// ClusterRole permit to get current cluster role
+func (h *ClusterRoleBuilderDefault) ClusterRole() *rbacv1.ClusterRole {
+	return h.clusterRole
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *ClusterRoleBuilderDefault) WithDefaults(defaults *Defaults) ClusterRoleBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Allow permit to add rule with the fluent rule builder
+// The rule is added on Build
+func (h *ClusterRoleBuilderDefault) Allow(verbs ...string) PolicyRuleBuilder {
+	rule := NewPolicyRuleBuilder(verbs...)
+	h.rules = append(h.rules, rule)
+
+	return rule
+}
+
+// Build permit to get the cluster role
+// Rules are compacted into a minimal set
+// It return error when aggregation rule is set with rules, because the controller overwrite them
+func (h *ClusterRoleBuilderDefault) Build() (cr *rbacv1.ClusterRole, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	for _, rule := range h.rules {
+		h.clusterRole.Rules = append(h.clusterRole.Rules, *rule.Rule())
+	}
+	h.rules = make([]PolicyRuleBuilder, 0)
+	h.clusterRole.Rules = CompactPolicyRules(h.clusterRole.Rules)
+
+	if h.clusterRole.AggregationRule != nil && len(h.clusterRole.Rules) > 0 {
+		return nil, errors.Errorf("ClusterRole %s can't have rules and aggregation rule, rules are managed by the controller", h.clusterRole.Name)
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.clusterRole)
+
+	if errs := ValidateMetadata(h.clusterRole); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.clusterRole, nil
+}
+
+// WithName permit to set name
+func (h *ClusterRoleBuilderDefault) WithName(name string, opts ...WithOption) ClusterRoleBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *ClusterRoleBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) ClusterRoleBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *ClusterRoleBuilderDefault) WithoutLabels(keys ...string) ClusterRoleBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *ClusterRoleBuilderDefault) WithFinalizer(name string) ClusterRoleBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *ClusterRoleBuilderDefault) WithoutFinalizer(name string) ClusterRoleBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *ClusterRoleBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ClusterRoleBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *ClusterRoleBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) ClusterRoleBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *ClusterRoleBuilderDefault) WithoutAnnotations(keys ...string) ClusterRoleBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithRules permit to set rules
+// On merge, rules are appended and compacted on Build
+func (h *ClusterRoleBuilderDefault) WithRules(rules []rbacv1.PolicyRule, opts ...WithOption) ClusterRoleBuilder {
+	h.clusterRole.Rules = withPolicyRules(h.clusterRole.Rules, rules, opts...)
+
+	return h
+}
+
+// WithAggregationRule permit to add cluster role selectors on aggregation rule
+// Selectors already present are skipped
+func (h *ClusterRoleBuilderDefault) WithAggregationRule(selectors ...metav1.LabelSelector) ClusterRoleBuilder {
+	if h.clusterRole.AggregationRule == nil {
+		h.clusterRole.AggregationRule = &rbacv1.AggregationRule{}
+	}
+
+	for _, selector := range selectors {
+		isFound := false
+		for _, current := range h.clusterRole.AggregationRule.ClusterRoleSelectors {
+			if reflect.DeepEqual(current, selector) {
+				isFound = true
+				break
+			}
+		}
+		if !isFound {
+			h.clusterRole.AggregationRule.ClusterRoleSelectors = append(h.clusterRole.AggregationRule.ClusterRoleSelectors, *selector.DeepCopy())
+		}
+	}
+
+	return h
+}
+
+// AggregateFrom permit to aggregate all cluster roles labeled with aggregate-to-<role>
+func (h *ClusterRoleBuilderDefault) AggregateFrom(roles ...string) ClusterRoleBuilder {
+	for _, role := range roles {
+		h.WithAggregationRule(AggregateToSelector(role))
+	}
+
+	return h
+}
+
+// AggregateTo permit to aggregate the cluster role into other cluster roles, like admin, edit or view
+// It add the aggregate-to-<role> labels
+func (h *ClusterRoleBuilderDefault) AggregateTo(roles ...string) ClusterRoleBuilder {
+	labels := make(map[string]string, len(roles))
+	for _, role := range roles {
+		labels[AggregateToLabel(role)] = "true"
+	}
+
+	return h.WithLabels(labels, Merge)
+}
+
+func withPolicyRules(current []rbacv1.PolicyRule, rules []rbacv1.PolicyRule, opts ...WithOption) []rbacv1.PolicyRule {
+
+	var tmpRules []rbacv1.PolicyRule
+
+	// Copy to avoid overwrite rules
+	if rules != nil {
+		tmpRules = make([]rbacv1.PolicyRule, 0, len(rules))
+		for _, rule := range rules {
+			tmpRules = append(tmpRules, *rule.DeepCopy())
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || current == nil {
+		return tmpRules
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(current) == 0 {
+		return tmpRules
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		return append(current, tmpRules...)
+	}
+
+	return current
+}
@@ -0,0 +1,431 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RoleBindingBuilder is the role binding builder interface
+type RoleBindingBuilder interface {
+	WithName(name string, opts ...WithOption) RoleBindingBuilder
+	WithNamespace(namespace string, opts ...WithOption) RoleBindingBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) RoleBindingBuilder
+	WithoutLabels(keys ...string) RoleBindingBuilder
+	WithFinalizer(name string) RoleBindingBuilder
+	WithoutFinalizer(name string) RoleBindingBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) RoleBindingBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) RoleBindingBuilder
+	WithoutAnnotations(keys ...string) RoleBindingBuilder
+	WithRole(name string) RoleBindingBuilder
+	WithClusterRole(name string) RoleBindingBuilder
+	WithSubjects(subjects []rbacv1.Subject, opts ...WithOption) RoleBindingBuilder
+	BindServiceAccount(namespace string, name string) RoleBindingBuilder
+	BindUser(name string) RoleBindingBuilder
+	BindGroup(name string) RoleBindingBuilder
+	WithDefaults(defaults *Defaults) RoleBindingBuilder
+	RoleBinding() *rbacv1.RoleBinding
+	Build() (rb *rbacv1.RoleBinding, err error)
+}
+
+// ClusterRoleBindingBuilder is the cluster role binding builder interface
+type ClusterRoleBindingBuilder interface {
+	WithName(name string, opts ...WithOption) ClusterRoleBindingBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) ClusterRoleBindingBuilder
+	WithoutLabels(keys ...string) ClusterRoleBindingBuilder
+	WithFinalizer(name string) ClusterRoleBindingBuilder
+	WithoutFinalizer(name string) ClusterRoleBindingBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ClusterRoleBindingBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) ClusterRoleBindingBuilder
+	WithoutAnnotations(keys ...string) ClusterRoleBindingBuilder
+	WithClusterRole(name string) ClusterRoleBindingBuilder
+	WithSubjects(subjects []rbacv1.Subject, opts ...WithOption) ClusterRoleBindingBuilder
+	BindServiceAccount(namespace string, name string) ClusterRoleBindingBuilder
+	BindUser(name string) ClusterRoleBindingBuilder
+	BindGroup(name string) ClusterRoleBindingBuilder
+	WithDefaults(defaults *Defaults) ClusterRoleBindingBuilder
+	ClusterRoleBinding() *rbacv1.ClusterRoleBinding
+	Build() (crb *rbacv1.ClusterRoleBinding, err error)
+}
+
+// RoleBindingBuilderDefault is the default implementation of role binding builder
+type RoleBindingBuilderDefault struct {
+	objectMeta
+	roleBinding *rbacv1.RoleBinding
+	defaults    *Defaults
+	err         error
+}
+
+// ClusterRoleBindingBuilderDefault is the default implementation of cluster role binding builder
+type ClusterRoleBindingBuilderDefault struct {
+	objectMeta
+	clusterRoleBinding *rbacv1.ClusterRoleBinding
+	defaults           *Defaults
+	err                error
+}
+
+// NewRoleBindingBuilder permit to init role binding builder
+func NewRoleBindingBuilder() RoleBindingBuilder {
+	roleBinding := &rbacv1.RoleBinding{}
+
+	return &RoleBindingBuilderDefault{
+		objectMeta:  newObjectMeta(roleBinding),
+		roleBinding: roleBinding,
+	}
+}
+
+// NewClusterRoleBindingBuilder permit to init cluster role binding builder
+func NewClusterRoleBindingBuilder() ClusterRoleBindingBuilder {
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+
+	return &ClusterRoleBindingBuilderDefault{
+		objectMeta:         newObjectMeta(clusterRoleBinding),
+		clusterRoleBinding: clusterRoleBinding,
+	}
+}
+
+// ServiceAccountSubject permit to get the subject of service account
+func ServiceAccountSubject(namespace string, name string) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:      rbacv1.ServiceAccountKind,
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// UserSubject permit to get the subject of user
+func UserSubject(name string) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:     rbacv1.UserKind,
+		APIGroup: rbacv1.GroupName,
+		Name:     name,
+	}
+}
+
+// GroupSubject permit to get the subject of group
+func GroupSubject(name string) rbacv1.Subject {
+	return rbacv1.Subject{
+		Kind:     rbacv1.GroupKind,
+		APIGroup: rbacv1.GroupName,
+		Name:     name,
+	}
+}
+
+// RoleBinding permit to get current role binding
+func (h *RoleBindingBuilderDefault) RoleBinding() *rbacv1.RoleBinding {
+	return h.roleBinding
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *RoleBindingBuilderDefault) WithDefaults(defaults *Defaults) RoleBindingBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the role binding
+// It return error if the role is not set
+func (h *RoleBindingBuilderDefault) Build() (rb *rbacv1.RoleBinding, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if h.roleBinding.RoleRef.Name == "" {
+		return nil, errors.Errorf("RoleBinding %s must reference a role", h.roleBinding.Name)
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.roleBinding)
+
+	if errs := ValidateMetadata(h.roleBinding); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.roleBinding, nil
+}
+
+// WithName permit to set name
+func (h *RoleBindingBuilderDefault) WithName(name string, opts ...WithOption) RoleBindingBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *RoleBindingBuilderDefault) WithNamespace(namespace string, opts ...WithOption) RoleBindingBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *RoleBindingBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) RoleBindingBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *RoleBindingBuilderDefault) WithoutLabels(keys ...string) RoleBindingBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *RoleBindingBuilderDefault) WithFinalizer(name string) RoleBindingBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *RoleBindingBuilderDefault) WithoutFinalizer(name string) RoleBindingBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *RoleBindingBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) RoleBindingBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *RoleBindingBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) RoleBindingBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *RoleBindingBuilderDefault) WithoutAnnotations(keys ...string) RoleBindingBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithRole permit to bind role of the same namespace
+func (h *RoleBindingBuilderDefault) WithRole(name string) RoleBindingBuilder {
+	h.roleBinding.RoleRef = rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "Role",
+		Name:     name,
+	}
+
+	return h
+}
+
+// WithClusterRole permit to bind cluster role on the namespace
+func (h *RoleBindingBuilderDefault) WithClusterRole(name string) RoleBindingBuilder {
+	h.roleBinding.RoleRef = rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     name,
+	}
+
+	return h
+}
+
+// WithSubjects permit to set subjects
+// On merge, subjects are deduplicated by kind, namespace and name
+func (h *RoleBindingBuilderDefault) WithSubjects(subjects []rbacv1.Subject, opts ...WithOption) RoleBindingBuilder {
+	h.roleBinding.Subjects = withSubjects(h.roleBinding.Subjects, subjects, opts...)
+
+	return h
+}
+
+// BindServiceAccount permit to add service account subject
+func (h *RoleBindingBuilderDefault) BindServiceAccount(namespace string, name string) RoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{ServiceAccountSubject(namespace, name)}, Merge)
+}
+
+// BindUser permit to add user subject
+func (h *RoleBindingBuilderDefault) BindUser(name string) RoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{UserSubject(name)}, Merge)
+}
+
+// BindGroup permit to add group subject
+func (h *RoleBindingBuilderDefault) BindGroup(name string) RoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{GroupSubject(name)}, Merge)
+}
+
+// ClusterRoleBinding permit to get current cluster role binding
+func (h *ClusterRoleBindingBuilderDefault) ClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return h.clusterRoleBinding
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *ClusterRoleBindingBuilderDefault) WithDefaults(defaults *Defaults) ClusterRoleBindingBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the cluster role binding
+// It return error if the cluster role is not set
+func (h *ClusterRoleBindingBuilderDefault) Build() (crb *rbacv1.ClusterRoleBinding, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if h.clusterRoleBinding.RoleRef.Name == "" {
+		return nil, errors.Errorf("ClusterRoleBinding %s must reference a cluster role", h.clusterRoleBinding.Name)
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.clusterRoleBinding)
+
+	if errs := ValidateMetadata(h.clusterRoleBinding); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.clusterRoleBinding, nil
+}
+
+// WithName permit to set name
+func (h *ClusterRoleBindingBuilderDefault) WithName(name string, opts ...WithOption) ClusterRoleBindingBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *ClusterRoleBindingBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) ClusterRoleBindingBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *ClusterRoleBindingBuilderDefault) WithoutLabels(keys ...string) ClusterRoleBindingBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *ClusterRoleBindingBuilderDefault) WithFinalizer(name string) ClusterRoleBindingBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *ClusterRoleBindingBuilderDefault) WithoutFinalizer(name string) ClusterRoleBindingBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *ClusterRoleBindingBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ClusterRoleBindingBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *ClusterRoleBindingBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) ClusterRoleBindingBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *ClusterRoleBindingBuilderDefault) WithoutAnnotations(keys ...string) ClusterRoleBindingBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithClusterRole permit to bind cluster role
+func (h *ClusterRoleBindingBuilderDefault) WithClusterRole(name string) ClusterRoleBindingBuilder {
+	h.clusterRoleBinding.RoleRef = rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     "ClusterRole",
+		Name:     name,
+	}
+
+	return h
+}
+
+// WithSubjects permit to set subjects
+// On merge, subjects are deduplicated by kind, namespace and name
+func (h *ClusterRoleBindingBuilderDefault) WithSubjects(subjects []rbacv1.Subject, opts ...WithOption) ClusterRoleBindingBuilder {
+	h.clusterRoleBinding.Subjects = withSubjects(h.clusterRoleBinding.Subjects, subjects, opts...)
+
+	return h
+}
+
+// BindServiceAccount permit to add service account subject
+func (h *ClusterRoleBindingBuilderDefault) BindServiceAccount(namespace string, name string) ClusterRoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{ServiceAccountSubject(namespace, name)}, Merge)
+}
+
+// BindUser permit to add user subject
+func (h *ClusterRoleBindingBuilderDefault) BindUser(name string) ClusterRoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{UserSubject(name)}, Merge)
+}
+
+// BindGroup permit to add group subject
+func (h *ClusterRoleBindingBuilderDefault) BindGroup(name string) ClusterRoleBindingBuilder {
+	return h.WithSubjects([]rbacv1.Subject{GroupSubject(name)}, Merge)
+}
+
+func withSubjects(current []rbacv1.Subject, subjects []rbacv1.Subject, opts ...WithOption) []rbacv1.Subject {
+
+	// Overwrite
+	if IsOverwrite(opts) || current == nil {
+		return dedupeSubjects(nil, subjects)
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(current) == 0 {
+		return dedupeSubjects(nil, subjects)
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		return dedupeSubjects(current, subjects)
+	}
+
+	return current
+}
+
+// dedupeSubjects permit to append subjects that are not yet on current, by kind, namespace and name
+func dedupeSubjects(current []rbacv1.Subject, subjects []rbacv1.Subject) []rbacv1.Subject {
+	if subjects == nil {
+		return current
+	}
+
+	for _, subject := range subjects {
+		isFound := false
+		for _, s := range current {
+			if s.Kind == subject.Kind && s.Namespace == subject.Namespace && s.Name == subject.Name {
+				isFound = true
+				break
+			}
+		}
+		if !isFound {
+			current = append(current, subject)
+		}
+	}
+
+	return current
+}
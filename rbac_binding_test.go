@@ -0,0 +1,66 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestRoleBindingBuilder(t *testing.T) {
+	rb, err := NewRoleBindingBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithRole("test").
+		BindServiceAccount("default", "operator").
+		BindUser("alice").
+		BindGroup("admins").
+		BindServiceAccount("default", "operator").
+		WithSubjects([]rbacv1.Subject{UserSubject("alice"), UserSubject("bob")}, Merge).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "test"}, rb.RoleRef)
+	assert.Equal(t, []rbacv1.Subject{
+		{Kind: "ServiceAccount", Namespace: "default", Name: "operator"},
+		{Kind: "User", APIGroup: "rbac.authorization.k8s.io", Name: "alice"},
+		{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "admins"},
+		{Kind: "User", APIGroup: "rbac.authorization.k8s.io", Name: "bob"},
+	}, rb.Subjects)
+
+	// When overwrite subjects
+	rb, err = NewRoleBindingBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithClusterRole("view").
+		BindUser("alice").
+		WithSubjects([]rbacv1.Subject{GroupSubject("admins")}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "ClusterRole", rb.RoleRef.Kind)
+	assert.Equal(t, []rbacv1.Subject{GroupSubject("admins")}, rb.Subjects)
+
+	// When no role
+	_, err = NewRoleBindingBuilder().WithDefaults(&Defaults{}).WithName("test").Build()
+	assert.Error(t, err)
+}
+
+func TestClusterRoleBindingBuilder(t *testing.T) {
+	crb, err := NewClusterRoleBindingBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithClusterRole("test").
+		BindServiceAccount("default", "operator").
+		BindServiceAccount("other", "operator").
+		BindServiceAccount("default", "operator").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []rbacv1.Subject{
+		ServiceAccountSubject("default", "operator"),
+		ServiceAccountSubject("other", "operator"),
+	}, crb.Subjects)
+
+	// When no cluster role
+	_, err = NewClusterRoleBindingBuilder().WithDefaults(&Defaults{}).WithName("test").Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package k8sbuilder
+
+import (
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// PolicyRuleBuilder is the fluent builder of RBAC rule, for exemple:
+// role.Allow("get", "list").On("pods", "services").InAPIGroup("")
+type PolicyRuleBuilder interface {
+	On(resources ...string) PolicyRuleBuilder
+	InAPIGroup(apiGroups ...string) PolicyRuleBuilder
+	WithResourceNames(resourceNames ...string) PolicyRuleBuilder
+	OnNonResourceURLs(urls ...string) PolicyRuleBuilder
+	Rule() *rbacv1.PolicyRule
+}
+
+// PolicyRuleBuilderDefault is the default implementation of policy rule builder
+type PolicyRuleBuilderDefault struct {
+	rule *rbacv1.PolicyRule
+}
+
+// NewPolicyRuleBuilder permit to init rule that allow verbs
+func NewPolicyRuleBuilder(verbs ...string) PolicyRuleBuilder {
+	return &PolicyRuleBuilderDefault{
+		rule: &rbacv1.PolicyRule{
+			Verbs: verbs,
+		},
+	}
+}
+
+// Rule permit to get current rule
+func (h *PolicyRuleBuilderDefault) Rule() *rbacv1.PolicyRule {
+	return h.rule
+}
+
+// On permit to add resources
+func (h *PolicyRuleBuilderDefault) On(resources ...string) PolicyRuleBuilder {
+	h.rule.Resources = append(h.rule.Resources, resources...)
+
+	return h
+}
+
+// InAPIGroup permit to add API groups. Use "" for core group
+func (h *PolicyRuleBuilderDefault) InAPIGroup(apiGroups ...string) PolicyRuleBuilder {
+	h.rule.APIGroups = append(h.rule.APIGroups, apiGroups...)
+
+	return h
+}
+
+// WithResourceNames permit to restrict the rule to some resource names
+func (h *PolicyRuleBuilderDefault) WithResourceNames(resourceNames ...string) PolicyRuleBuilder {
+	h.rule.ResourceNames = append(h.rule.ResourceNames, resourceNames...)
+
+	return h
+}
+
+// OnNonResourceURLs permit to add non resource URLs, like /healthz. Only used by ClusterRole
+func (h *PolicyRuleBuilderDefault) OnNonResourceURLs(urls ...string) PolicyRuleBuilder {
+	h.rule.NonResourceURLs = append(h.rule.NonResourceURLs, urls...)
+
+	return h
+}
+
+// CompactPolicyRules permit to merge overlapping rules into a minimal set, without change the granted permissions
+// Rules that differ only by verbs, only by resources, only by API groups or only by non resource URLs are merged. The result is sorted.
+func CompactPolicyRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	compacted := make([]rbacv1.PolicyRule, 0, len(rules))
+	for _, rule := range rules {
+		compacted = append(compacted, normalizePolicyRule(rule))
+	}
+
+	// Each merge can permit new merge, so loop until nothing change
+	for {
+		length := len(compacted)
+		compacted = mergePolicyRules(compacted, func(r rbacv1.PolicyRule) rbacv1.PolicyRule { r.Verbs = nil; return r }, func(dst *rbacv1.PolicyRule, src rbacv1.PolicyRule) {
+			dst.Verbs = append(dst.Verbs, src.Verbs...)
+		})
+		compacted = mergePolicyRules(compacted, func(r rbacv1.PolicyRule) rbacv1.PolicyRule { r.Resources = nil; return r }, func(dst *rbacv1.PolicyRule, src rbacv1.PolicyRule) {
+			dst.Resources = append(dst.Resources, src.Resources...)
+		})
+		compacted = mergePolicyRules(compacted, func(r rbacv1.PolicyRule) rbacv1.PolicyRule { r.APIGroups = nil; return r }, func(dst *rbacv1.PolicyRule, src rbacv1.PolicyRule) {
+			dst.APIGroups = append(dst.APIGroups, src.APIGroups...)
+		})
+		compacted = mergePolicyRules(compacted, func(r rbacv1.PolicyRule) rbacv1.PolicyRule { r.NonResourceURLs = nil; return r }, func(dst *rbacv1.PolicyRule, src rbacv1.PolicyRule) {
+			dst.NonResourceURLs = append(dst.NonResourceURLs, src.NonResourceURLs...)
+		})
+		if len(compacted) == length {
+			break
+		}
+	}
+
+	sort.SliceStable(compacted, func(i, j int) bool {
+		return policyRuleKey(compacted[i]) < policyRuleKey(compacted[j])
+	})
+
+	return compacted
+}
+
+// mergePolicyRules permit to merge rules that have the same key
+func mergePolicyRules(rules []rbacv1.PolicyRule, keyFn func(r rbacv1.PolicyRule) rbacv1.PolicyRule, mergeFn func(dst *rbacv1.PolicyRule, src rbacv1.PolicyRule)) []rbacv1.PolicyRule {
+	merged := make([]rbacv1.PolicyRule, 0, len(rules))
+	indexes := map[string]int{}
+	for _, rule := range rules {
+		key := policyRuleKey(keyFn(rule))
+		if index, ok := indexes[key]; ok {
+			mergeFn(&merged[index], rule)
+			merged[index] = normalizePolicyRule(merged[index])
+			continue
+		}
+		indexes[key] = len(merged)
+		merged = append(merged, rule)
+	}
+
+	return merged
+}
+
+func normalizePolicyRule(rule rbacv1.PolicyRule) rbacv1.PolicyRule {
+	rule = *rule.DeepCopy()
+	rule.Verbs = normalizeStrings(rule.Verbs, true)
+	rule.APIGroups = normalizeStrings(rule.APIGroups, true)
+	rule.Resources = normalizeStrings(rule.Resources, true)
+	rule.ResourceNames = normalizeStrings(rule.ResourceNames, false)
+	rule.NonResourceURLs = normalizeStrings(rule.NonResourceURLs, false)
+
+	return rule
+}
+
+// normalizeStrings permit to sort and dedupe values
+// If wildcard is true and values contain "*", only "*" is keeped
+func normalizeStrings(values []string, wildcard bool) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	set := map[string]bool{}
+	for _, value := range values {
+		if wildcard && value == rbacv1.VerbAll {
+			return []string{rbacv1.VerbAll}
+		}
+		set[value] = true
+	}
+	normalized := make([]string, 0, len(set))
+	for value := range set {
+		normalized = append(normalized, value)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
+func policyRuleKey(rule rbacv1.PolicyRule) string {
+	return strings.Join([]string{
+		strings.Join(rule.APIGroups, ","),
+		strings.Join(rule.Resources, ","),
+		strings.Join(rule.ResourceNames, ","),
+		strings.Join(rule.NonResourceURLs, ","),
+		strings.Join(rule.Verbs, ","),
+	}, "|")
+}
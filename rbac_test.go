@@ -0,0 +1,96 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRoleBuilderRules(t *testing.T) {
+	b := NewRoleBuilder().WithDefaults(&Defaults{}).WithName("test")
+	b.Allow("get", "list").On("pods", "services").InAPIGroup("")
+	b.Allow("watch").On("pods", "services").InAPIGroup("")
+	b.Allow("get", "list", "watch").On("configmaps").InAPIGroup("")
+	b.Allow("get").On("secrets").InAPIGroup("").WithResourceNames("credentials")
+	b.Allow("get", "list", "watch").On("deployments").InAPIGroup("apps")
+	b.Allow("get", "list", "watch").On("deployments").InAPIGroup("extensions")
+
+	r, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{"apps", "extensions"},
+			Resources: []string{"deployments"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"configmaps", "pods", "services"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{"credentials"},
+			Verbs:         []string{"get"},
+		},
+	}, r.Rules)
+}
+
+func TestCompactPolicyRules(t *testing.T) {
+	assert.Equal(t, []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"*"},
+		},
+		{
+			NonResourceURLs: []string{"/healthz", "/metrics"},
+			Verbs:           []string{"get"},
+		},
+	}, CompactPolicyRules([]rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"*"}},
+		{NonResourceURLs: []string{"/metrics"}, Verbs: []string{"get"}},
+		{NonResourceURLs: []string{"/healthz"}, Verbs: []string{"get"}},
+	}))
+}
+
+func TestClusterRoleBuilderAggregation(t *testing.T) {
+	// Aggregate to default roles
+	cr, err := NewClusterRoleBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test-edit").
+		WithLabels(map[string]string{"app": "test"}).
+		AggregateTo("admin", "edit").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app": "test",
+		"rbac.authorization.k8s.io/aggregate-to-admin": "true",
+		"rbac.authorization.k8s.io/aggregate-to-edit":  "true",
+	}, cr.Labels)
+
+	// Aggregate from other roles
+	cr, err = NewClusterRoleBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		AggregateFrom("test-edit").
+		WithAggregationRule(AggregateToSelector("test-edit"), metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &rbacv1.AggregationRule{
+		ClusterRoleSelectors: []metav1.LabelSelector{
+			{MatchLabels: map[string]string{"rbac.authorization.k8s.io/aggregate-to-test-edit": "true"}},
+			{MatchLabels: map[string]string{"app": "test"}},
+		},
+	}, cr.AggregationRule)
+
+	// When rules and aggregation rule
+	b := NewClusterRoleBuilder().WithDefaults(&Defaults{}).WithName("test").AggregateFrom("test-edit")
+	b.Allow("get").On("pods").InAPIGroup("")
+	_, err = b.Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package k8sbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// RecordLastAppliedConfiguration record the expected object on the last-applied-configuration annotation, like kubectl apply do
+	// So the next Reconcile remove from live object the fields that are not more on expected object.
+	RecordLastAppliedConfiguration ReconcileOption = "recordLastAppliedConfiguration"
+)
+
+// ReconcileOption permit to choose how Reconcile write the expected object
+type ReconcileOption string
+
+// Reconcile permit to create the expected object on cluster, or to update it if already exist
+// The update merge the expected object on the live object with the same logic than PatchAgainst,
+// so fields managed by API server or other controllers are keeped.
+// Without RecordLastAppliedConfiguration, the fields removed from expected object are keeped on live object,
+// unless the last-applied-configuration annotation is already set on it.
+func Reconcile(ctx context.Context, c client.Client, expected client.Object, opts ...ReconcileOption) (res controllerutil.OperationResult, err error) {
+	if expected == nil {
+		return controllerutil.OperationResultNone, errors.New("Expected object can't be nil")
+	}
+
+	// The annotation is set on copy, so the expected object given by caller is not modified
+	if funk.Contains(opts, RecordLastAppliedConfiguration) {
+		expected = expected.DeepCopyObject().(client.Object)
+		if err = SetLastAppliedConfiguration(expected); err != nil {
+			return controllerutil.OperationResultNone, errors.Wrap(err, "Error when set last applied configuration")
+		}
+	}
+
+	// The live object is read on empty object, because of the client decode it on the existing fields,
+	// so values of expected object would hide the drift of live object
+	current := newEmptyObject(expected)
+
+	res, err = controllerutil.CreateOrUpdate(ctx, c, current, func() error {
+		return mergeOnLive(expected, current, true)
+	})
+	if err != nil {
+		return res, errors.Wrapf(err, "Error when reconcile %s/%s", expected.GetNamespace(), expected.GetName())
+	}
+
+	return res, nil
+}
+
+// DryRunAgainstCluster permit to submit the expected object to API server with server side dry-run, so nothing is persisted
+// The object is created if not exist, else it's merged on the live object like Reconcile do.
+// It return the object like API server would store it, with defaults and mutating webhooks applied.
+// Validation and admission errors are returned as is, so you can use k8serrors.IsInvalid or k8serrors.IsForbidden on them.
+func DryRunAgainstCluster(ctx context.Context, c client.Client, expected client.Object) (result client.Object, err error) {
+	if expected == nil {
+		return nil, errors.New("Expected object can't be nil")
+	}
+
+	// The live object is read on empty object, so values of expected object not hide the drift of live object
+	result = newEmptyObject(expected)
+
+	if err = c.Get(ctx, client.ObjectKeyFromObject(expected), result); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "Error when get %s/%s", expected.GetNamespace(), expected.GetName())
+		}
+
+		result = expected.DeepCopyObject().(client.Object)
+		if err = c.Create(ctx, result, client.DryRunAll); err != nil {
+			return nil, errors.Wrapf(err, "Error when dry-run create %s/%s", expected.GetNamespace(), expected.GetName())
+		}
+		return result, nil
+	}
+
+	if err = mergeOnLive(expected, result, true); err != nil {
+		return nil, errors.Wrap(err, "Error when merge expected object on live object")
+	}
+	if err = c.Update(ctx, result, client.DryRunAll); err != nil {
+		return nil, errors.Wrapf(err, "Error when dry-run update %s/%s", expected.GetNamespace(), expected.GetName())
+	}
+
+	return result, nil
+}
+
+// newEmptyObject permit to get new object of the same type, with only the apiVersion, kind, name and namespace of object
+func newEmptyObject(o client.Object) client.Object {
+	empty := reflect.New(reflect.TypeOf(o).Elem()).Interface().(client.Object)
+	empty.GetObjectKind().SetGroupVersionKind(o.GetObjectKind().GroupVersionKind())
+	empty.SetName(o.GetName())
+	empty.SetNamespace(o.GetNamespace())
+
+	return empty
+}
+
+// mergeOnLive permit to merge the expected object on the live object, with or without the status
+func mergeOnLive(expected, live client.Object, excludeStatus bool) (err error) {
+	defer func() { observeMerge("mergeOnLive", err) }()
+
+	patch, _, err := patchAgainst(expected, live, excludeStatus)
+	if err != nil {
+		return err
+	}
+
+	liveByte, err := json.Marshal(live)
+	if err != nil {
+		return errors.Wrap(err, "Error when marshal live object")
+	}
+
+	mergedByte, err := strategicpatch.StrategicMergePatch(liveByte, patch, live)
+	if err != nil {
+		return errors.Wrap(err, "Error when apply patch on live object")
+	}
+
+	// Unmarshal on fresh object to remove fields deleted by patch
+	merged := reflect.New(reflect.TypeOf(live).Elem())
+	if err = json.Unmarshal(mergedByte, merged.Interface()); err != nil {
+		return errors.Wrap(err, "Error when unmarshal merged object")
+	}
+	reflect.ValueOf(live).Elem().Set(merged.Elem())
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package k8sbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestReconcile(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "test",
+			},
+		},
+	}
+
+	// When create
+	res, err := Reconcile(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultCreated, res)
+
+	// When nothing change
+	res, err = Reconcile(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultNone, res)
+
+	// When update, fields set by other must be keeped
+	live := &networkingv1.Ingress{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	live.Spec.IngressClassName = pointer.String("nginx")
+	assert.NoError(t, c.Update(context.Background(), live))
+
+	expected.Labels["label"] = "new"
+	res, err = Reconcile(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultUpdated, res)
+
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	assert.Equal(t, "new", live.Labels["label"])
+	assert.Equal(t, pointer.String("nginx"), live.Spec.IngressClassName)
+}
+
+// decodingClient permit to mimic the real client, that decode the API server response on the given object without reset it
+// The fake client reset the object before decode it, so it hide the values keeped from the given object.
+type decodingClient struct {
+	client.Client
+}
+
+func (h *decodingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	live := newEmptyObject(obj)
+	if err := h.Client.Get(ctx, key, live, opts...); err != nil {
+		return err
+	}
+	data, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, obj)
+}
+
+func TestReconcileDrift(t *testing.T) {
+	c := &decodingClient{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "test",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+	}
+	res, err := Reconcile(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultCreated, res)
+
+	// When label is removed from live object, it's set again
+	live := &networkingv1.Ingress{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	live.Labels = map[string]string{"other": "keep"}
+	assert.NoError(t, c.Update(context.Background(), live))
+
+	res, err = Reconcile(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultUpdated, res)
+
+	live = &networkingv1.Ingress{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	assert.Equal(t, map[string]string{"label": "test", "other": "keep"}, live.Labels)
+	assert.Equal(t, pointer.String("nginx"), live.Spec.IngressClassName)
+}
+
+// dryRunClient permit to serve a live object like the API server, without fake client
+// Get decode the live object on the given object without reset it, and dry-run updates are recorded.
+type dryRunClient struct {
+	client.Client
+	live      []byte
+	getTarget client.Object
+	updated   client.Object
+}
+
+func (h *dryRunClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	h.getTarget = obj.DeepCopyObject().(client.Object)
+	return json.Unmarshal(h.live, obj)
+}
+
+func (h *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	h.updated = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func TestDryRunAgainstClusterDrift(t *testing.T) {
+	live, err := json.Marshal(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			ResourceVersion: "12",
+			Labels:          map[string]string{"other": "keep"},
+		},
+	})
+	assert.NoError(t, err)
+	c := &dryRunClient{live: live}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels:    map[string]string{"label": "test"},
+		},
+	}
+
+	// The label removed from live object is set on the dry-run update
+	result, err := DryRunAgainstCluster(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"label": "test", "other": "keep"}, result.GetLabels())
+	assert.Equal(t, "12", result.GetResourceVersion())
+	assert.Equal(t, result, c.updated)
+
+	// The live object is read on empty object, so expected values not hide the live ones
+	assert.Equal(t, &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}, c.getTarget)
+}
+
+func TestReconcileRecordLastAppliedConfiguration(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label":   "test",
+				"removed": "test",
+			},
+		},
+	}
+
+	// When create, the annotation is set on live object only
+	res, err := Reconcile(context.Background(), c, expected, RecordLastAppliedConfiguration)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultCreated, res)
+	assert.Nil(t, GetLastAppliedConfiguration(expected))
+
+	live := &networkingv1.Ingress{}
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	assert.NotNil(t, GetLastAppliedConfiguration(live))
+
+	// When nothing change
+	res, err = Reconcile(context.Background(), c, expected, RecordLastAppliedConfiguration)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultNone, res)
+
+	// When field is removed from expected object, it's removed from live object, and fields set by other are keeped
+	live.Labels["other"] = "keep"
+	assert.NoError(t, c.Update(context.Background(), live))
+
+	delete(expected.Labels, "removed")
+	res, err = Reconcile(context.Background(), c, expected, RecordLastAppliedConfiguration)
+	assert.NoError(t, err)
+	assert.Equal(t, controllerutil.OperationResultUpdated, res)
+
+	assert.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(expected), live))
+	assert.Equal(t, map[string]string{"label": "test", "other": "keep"}, live.Labels)
+	assert.NotContains(t, string(GetLastAppliedConfiguration(live)), "removed")
+}
+
+func TestDiff(t *testing.T) {
+	live := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			ResourceVersion: "12",
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{
+					Manager: "test",
+				},
+			},
+			Labels: map[string]string{
+				"label": "old",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: pointer.String("nginx"),
+		},
+	}
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "old",
+			},
+		},
+	}
+
+	// When no diff
+	diff, err := Diff(expected, live)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	// When diff
+	expected.Labels["label"] = "new"
+	diff, err = Diff(expected, live)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, diff)
+}
+
+func TestDryRunAgainstCluster(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	expected := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels: map[string]string{
+				"label": "test",
+			},
+		},
+	}
+
+	// When not exist, nothing is created
+	result, err := DryRunAgainstCluster(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", result.GetLabels()["label"])
+	err = c.Get(context.Background(), client.ObjectKeyFromObject(expected), &networkingv1.Ingress{})
+	assert.True(t, k8serrors.IsNotFound(err))
+
+	// When exist, expected object is merged on live object and nothing is updated
+	live := expected.DeepCopy()
+	live.Labels["other"] = "keep"
+	if err = c.Create(context.Background(), live); err != nil {
+		t.Fatal(err)
+	}
+	expected.Labels["label"] = "new"
+	result, err = DryRunAgainstCluster(context.Background(), c, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"label": "new", "other": "keep"}, result.GetLabels())
+	live = &networkingv1.Ingress{}
+	if err = c.Get(context.Background(), client.ObjectKeyFromObject(expected), live); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "test", live.Labels["label"])
+
+	// From builder
+	i, err := NewIngressBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithLabels(map[string]string{"label": "builder"}).
+		DryRunAgainstCluster(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "builder", i.Labels["label"])
+	assert.Equal(t, "keep", i.Labels["other"])
+}
@@ -0,0 +1,72 @@
+package k8sbuilder
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// RedactedValue permit to get the placeholder of secret value, like <redacted sha256:abc123>
+// The placeholder keep a short hash of value, so redacted dumps still show when a value change.
+func RedactedValue(value []byte) string {
+	sum := sha256.Sum256(value)
+	return fmt.Sprintf("<redacted sha256:%s>", hex.EncodeToString(sum[:])[:hashSuffixLength])
+}
+
+// RedactSecret permit to get a copy of object where data and stringData values of secret are replaced by a hash placeholder
+// It handle Secret and unstructured Secret. Other objects are returned as is.
+// Use it before dump or log objects, so the output can be shared safely.
+func RedactSecret(o runtime.Object) runtime.Object {
+	switch s := o.(type) {
+	case *corev1.Secret:
+		if s == nil {
+			return o
+		}
+		redacted := s.DeepCopy()
+		for key, value := range redacted.Data {
+			redacted.Data[key] = []byte(RedactedValue(value))
+		}
+		for key, value := range redacted.StringData {
+			redacted.StringData[key] = RedactedValue([]byte(value))
+		}
+		return redacted
+	case *unstructured.Unstructured:
+		if s == nil || s.GetKind() != "Secret" || s.GroupVersionKind().Group != "" {
+			return o
+		}
+		redacted := s.DeepCopy()
+		if data, ok := redacted.Object["data"].(map[string]any); ok {
+			for key, value := range data {
+				str, _ := value.(string)
+				decoded, err := base64.StdEncoding.DecodeString(str)
+				if err != nil {
+					decoded = []byte(str)
+				}
+				data[key] = base64.StdEncoding.EncodeToString([]byte(RedactedValue(decoded)))
+			}
+		}
+		if stringData, ok := redacted.Object["stringData"].(map[string]any); ok {
+			for key, value := range stringData {
+				str, _ := value.(string)
+				stringData[key] = RedactedValue([]byte(str))
+			}
+		}
+		return redacted
+	}
+
+	return o
+}
+
+// redact permit to redact value if it's a secret
+func redact(v any) any {
+	if o, ok := v.(runtime.Object); ok {
+		return RedactSecret(o)
+	}
+
+	return v
+}
@@ -0,0 +1,81 @@
+package k8sbuilder
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRedactSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Data: map[string][]byte{
+			"password": []byte("secret"),
+		},
+		StringData: map[string]string{
+			"token": "secret",
+		},
+	}
+
+	// Secret
+	redacted := RedactSecret(secret).(*corev1.Secret)
+	assert.Equal(t, "test", redacted.Name)
+	assert.Equal(t, RedactedValue([]byte("secret")), string(redacted.Data["password"]))
+	assert.Equal(t, RedactedValue([]byte("secret")), redacted.StringData["token"])
+	assert.True(t, strings.HasPrefix(redacted.StringData["token"], "<redacted sha256:"))
+	assert.NotEqual(t, RedactedValue([]byte("secret")), RedactedValue([]byte("other")))
+	// Original secret is not modified
+	assert.Equal(t, []byte("secret"), secret.Data["password"])
+	assert.Equal(t, "secret", secret.StringData["token"])
+
+	// Unstructured secret
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"data": map[string]any{
+			"password": base64.StdEncoding.EncodeToString([]byte("secret")),
+		},
+		"stringData": map[string]any{
+			"token": "secret",
+		},
+	}}
+	redactedU := RedactSecret(u).(*unstructured.Unstructured)
+	data, _, _ := unstructured.NestedString(redactedU.Object, "data", "password")
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(RedactedValue([]byte("secret")))), data)
+	stringData, _, _ := unstructured.NestedString(redactedU.Object, "stringData", "token")
+	assert.Equal(t, RedactedValue([]byte("secret")), stringData)
+
+	// Other objects
+	cm := &corev1.ConfigMap{Data: map[string]string{"key": "value"}}
+	assert.Same(t, cm, RedactSecret(cm))
+}
+
+func TestDiffRedactSecret(t *testing.T) {
+	live := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Data:       map[string][]byte{"password": []byte("old-password")},
+	}
+	expected := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Data:       map[string][]byte{"password": []byte("new-password")},
+	}
+
+	diff, err := Diff(expected, live)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, diff)
+	assert.NotContains(t, diff, "old-password")
+	assert.NotContains(t, diff, "new-password")
+
+	// Fields diff are computed on placeholders, not on secret values
+	for _, field := range DiffFields(live, expected) {
+		assert.True(t, strings.HasPrefix(field.Path, "Data[password]["))
+		assert.NotContains(t, field.Before+field.After, "password")
+	}
+}
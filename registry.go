@@ -0,0 +1,134 @@
+package k8sbuilder
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectBuilder is a builder got from registry, without know its type
+// Builder is the typed builder, like DeploymentBuilder, to configure it with type assertion.
+// Build permit to build the object, so it can be added on BuilderSet.
+type ObjectBuilder struct {
+	Builder any
+	Build   BuildFunc
+}
+
+// BuilderConstructor is a function that init new builder
+type BuilderConstructor func() ObjectBuilder
+
+// NewObjectBuilder permit to wrap typed builder on ObjectBuilder
+func NewObjectBuilder[T client.Object](builder interface{ Build() (T, error) }) ObjectBuilder {
+	return ObjectBuilder{
+		Builder: builder,
+		Build: func() (client.Object, error) {
+			o, err := builder.Build()
+			if err != nil {
+				return nil, err
+			}
+			return o, nil
+		},
+	}
+}
+
+// BuilderRegistry permit to get the builder of GroupVersionKind, for generic reconcilers that handle many kinds
+// It's safe for concurrent use.
+type BuilderRegistry struct {
+	constructors map[schema.GroupVersionKind]BuilderConstructor
+	mu           sync.RWMutex
+}
+
+// Builders is the package level registry, with the builders of this package
+var Builders = newDefaultBuilderRegistry()
+
+// NewBuilderRegistry permit to init empty builder registry
+func NewBuilderRegistry() *BuilderRegistry {
+	return &BuilderRegistry{
+		constructors: map[schema.GroupVersionKind]BuilderConstructor{},
+	}
+}
+
+// Register permit to add or replace the builder constructor of GroupVersionKind
+func (h *BuilderRegistry) Register(gvk schema.GroupVersionKind, constructor BuilderConstructor) *BuilderRegistry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.constructors[gvk] = constructor
+
+	return h
+}
+
+// For permit to get new builder for GroupVersionKind
+// It return error if no builder is registered for it.
+func (h *BuilderRegistry) For(gvk schema.GroupVersionKind) (builder ObjectBuilder, err error) {
+	h.mu.RLock()
+	constructor, ok := h.constructors[gvk]
+	h.mu.RUnlock()
+
+	if !ok {
+		return ObjectBuilder{}, errors.Errorf("No builder registered for %s", gvk.String())
+	}
+
+	return constructor(), nil
+}
+
+func newDefaultBuilderRegistry() *BuilderRegistry {
+	return NewBuilderRegistry().
+		Register(corev1.SchemeGroupVersion.WithKind("ConfigMap"), func() ObjectBuilder {
+			return NewObjectBuilder[*corev1.ConfigMap](NewConfigMapBuilder())
+		}).
+		Register(corev1.SchemeGroupVersion.WithKind("Secret"), func() ObjectBuilder {
+			return NewObjectBuilder[*corev1.Secret](NewSecretBuilder())
+		}).
+		Register(corev1.SchemeGroupVersion.WithKind("Service"), func() ObjectBuilder {
+			return NewObjectBuilder[*corev1.Service](NewServiceBuilder())
+		}).
+		Register(appsv1.SchemeGroupVersion.WithKind("Deployment"), func() ObjectBuilder {
+			return NewObjectBuilder[*appsv1.Deployment](NewDeploymentBuilder())
+		}).
+		Register(appsv1.SchemeGroupVersion.WithKind("StatefulSet"), func() ObjectBuilder {
+			return NewObjectBuilder[*appsv1.StatefulSet](NewStatefulSetBuilder())
+		}).
+		Register(appsv1.SchemeGroupVersion.WithKind("DaemonSet"), func() ObjectBuilder {
+			return NewObjectBuilder[client.Object](NewWorkloadBuilder(WorkloadDaemonSet))
+		}).
+		Register(batchv1.SchemeGroupVersion.WithKind("Job"), func() ObjectBuilder {
+			return NewObjectBuilder[*batchv1.Job](NewJobBuilder())
+		}).
+		Register(batchv1.SchemeGroupVersion.WithKind("CronJob"), func() ObjectBuilder {
+			return NewObjectBuilder[*batchv1.CronJob](NewCronJobBuilder())
+		}).
+		Register(autoscalingv2.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"), func() ObjectBuilder {
+			return NewObjectBuilder[*autoscalingv2.HorizontalPodAutoscaler](NewHorizontalPodAutoscalerBuilder())
+		}).
+		Register(networkingv1.SchemeGroupVersion.WithKind("Ingress"), func() ObjectBuilder {
+			return NewObjectBuilder[*networkingv1.Ingress](NewIngressBuilder())
+		}).
+		Register(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"), func() ObjectBuilder {
+			return NewObjectBuilder[*networkingv1.NetworkPolicy](NewNetworkPolicyBuilder())
+		}).
+		Register(policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"), func() ObjectBuilder {
+			return NewObjectBuilder[*policyv1.PodDisruptionBudget](NewPodDisruptionBudgetBuilder())
+		}).
+		Register(rbacv1.SchemeGroupVersion.WithKind("Role"), func() ObjectBuilder {
+			return NewObjectBuilder[*rbacv1.Role](NewRoleBuilder())
+		}).
+		Register(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), func() ObjectBuilder {
+			return NewObjectBuilder[*rbacv1.ClusterRole](NewClusterRoleBuilder())
+		}).
+		Register(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), func() ObjectBuilder {
+			return NewObjectBuilder[*rbacv1.RoleBinding](NewRoleBindingBuilder())
+		}).
+		Register(rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"), func() ObjectBuilder {
+			return NewObjectBuilder[*rbacv1.ClusterRoleBinding](NewClusterRoleBindingBuilder())
+		})
+}
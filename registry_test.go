@@ -0,0 +1,57 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBuilderRegistry(t *testing.T) {
+	// Builtin builders
+	b, err := Builders.For(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	assert.NoError(t, err)
+	b.Builder.(DeploymentBuilder).
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+	o, err := b.Build()
+	assert.NoError(t, err)
+	assert.IsType(t, &appsv1.Deployment{}, o)
+	assert.Equal(t, "test", o.GetName())
+
+	b, err = Builders.For(appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+	assert.NoError(t, err)
+	b.Builder.(WorkloadBuilder).
+		WithDefaults(&Defaults{}).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+	o, err = b.Build()
+	assert.NoError(t, err)
+	assert.IsType(t, &appsv1.DaemonSet{}, o)
+
+	// Each call return new builder
+	b1, _ := Builders.For(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	b2, _ := Builders.For(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	assert.NotSame(t, b1.Builder, b2.Builder)
+
+	// Unknown kind
+	_, err = Builders.For(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"})
+	assert.Error(t, err)
+
+	// Custom registry
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+	registry := NewBuilderRegistry().Register(gvk, func() ObjectBuilder {
+		return NewObjectBuilder[*corev1.ConfigMap](NewConfigMapBuilder().WithDefaults(&Defaults{}).WithName("foo"))
+	})
+	b, err = registry.For(gvk)
+	assert.NoError(t, err)
+	o, err = b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", o.GetName())
+}
@@ -0,0 +1,233 @@
+package k8sbuilder
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ResourceNormalization describe how to normalize container resources on Build
+// Requests are always capped to limits.
+type ResourceNormalization struct {
+	// RequestRatios permit to compute missing request from limit, by resource name
+	// For exemple, cpu: 0.5 set cpu request to the half of cpu limit
+	RequestRatios map[corev1.ResourceName]float64
+
+	// LimitRatios permit to compute missing limit from request, by resource name
+	// For exemple, memory: 2 set memory limit to the double of memory request
+	LimitRatios map[corev1.ResourceName]float64
+
+	// RequestsFromLimits permit to fill missing requests from limits
+	RequestsFromLimits bool
+
+	// LimitsFromRequests permit to fill missing limits from requests
+	LimitsFromRequests bool
+}
+
+// NormalizeResources permit to normalize resources
+// Ratios are applied first, then missing values are copied and finally requests are capped to limits.
+func NormalizeResources(resources *corev1.ResourceRequirements, n *ResourceNormalization) {
+	if resources == nil || n == nil {
+		return
+	}
+
+	requests := resources.Requests.DeepCopy()
+	limits := resources.Limits.DeepCopy()
+	if requests == nil {
+		requests = corev1.ResourceList{}
+	}
+	if limits == nil {
+		limits = corev1.ResourceList{}
+	}
+
+	for name, ratio := range n.RequestRatios {
+		if limit, ok := resources.Limits[name]; ok {
+			if _, ok := requests[name]; !ok {
+				requests[name] = multiplyQuantity(limit, ratio)
+			}
+		}
+	}
+	for name, ratio := range n.LimitRatios {
+		if request, ok := resources.Requests[name]; ok {
+			if _, ok := limits[name]; !ok {
+				limits[name] = multiplyQuantity(request, ratio)
+			}
+		}
+	}
+
+	if n.RequestsFromLimits {
+		for name, limit := range limits {
+			if _, ok := requests[name]; !ok {
+				requests[name] = limit.DeepCopy()
+			}
+		}
+	}
+	if n.LimitsFromRequests {
+		for name, request := range requests {
+			if _, ok := limits[name]; !ok {
+				limits[name] = request.DeepCopy()
+			}
+		}
+	}
+
+	// Requests must be lower or equal than limits
+	for name, request := range requests {
+		if limit, ok := limits[name]; ok && request.Cmp(limit) > 0 {
+			requests[name] = limit.DeepCopy()
+		}
+	}
+
+	if len(requests) > 0 {
+		resources.Requests = requests
+	}
+	if len(limits) > 0 {
+		resources.Limits = limits
+	}
+}
+
+// multiplyQuantity permit to multiply quantity by ratio, with milli precision
+func multiplyQuantity(q resource.Quantity, ratio float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(float64(q.MilliValue())*ratio), q.Format)
+}
+
+// ParseResourceList permit to parse quantities given as strings, like {"cpu": "500m", "memory": "1Gi"}
+// It return one error by invalid quantity, with the field path, instead of panic like resource.MustParse.
+func ParseResourceList(values map[string]string, fldPath *field.Path) (list corev1.ResourceList, errs field.ErrorList) {
+	errs = field.ErrorList{}
+	if values == nil {
+		return nil, errs
+	}
+
+	// Sort names, so errors are always in the same order
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list = make(corev1.ResourceList, len(values))
+	for _, name := range names {
+		q, err := resource.ParseQuantity(values[name])
+		if err != nil {
+			errs = append(errs, field.Invalid(fldPath.Key(name), values[name], err.Error()))
+			continue
+		}
+		list[corev1.ResourceName(name)] = q
+	}
+
+	return list, errs
+}
+
+// ParseResourceRequirements permit to parse requests and limits given as strings, like the ones read from user custom resources
+func ParseResourceRequirements(requests, limits map[string]string, fldPath *field.Path) (resources *corev1.ResourceRequirements, errs field.ErrorList) {
+	resources = &corev1.ResourceRequirements{}
+
+	resources.Requests, errs = ParseResourceList(requests, fldPath.Child("requests"))
+	limitList, limitErrs := ParseResourceList(limits, fldPath.Child("limits"))
+	resources.Limits = limitList
+	errs = append(errs, limitErrs...)
+
+	return resources, errs
+}
+
+// WithContainerResources permit to set the resources of container or init container from quantities given as strings
+// When a quantity is invalid, or the container not exist, the error is returned by Build with the container and the field.
+func (h *PodTemplateBuilderDefault) WithContainerResources(name string, requests, limits map[string]string, opts ...WithOption) PodTemplateBuilder {
+	defer h.observe("WithContainerResources", opts...)()
+
+	h.own(sharedInitContainers | sharedContainers)
+
+	var c *corev1.Container
+	var fldPath *field.Path
+	for i := range h.podTemplate.Spec.InitContainers {
+		if h.podTemplate.Spec.InitContainers[i].Name == name {
+			c = &h.podTemplate.Spec.InitContainers[i]
+			fldPath = field.NewPath("spec", "initContainers").Index(i)
+		}
+	}
+	for i := range h.podTemplate.Spec.Containers {
+		if h.podTemplate.Spec.Containers[i].Name == name {
+			c = &h.podTemplate.Spec.Containers[i]
+			fldPath = field.NewPath("spec", "containers").Index(i)
+		}
+	}
+	if c == nil {
+		if h.err == nil {
+			h.err = errors.Errorf("Container %s not found to set resources", name)
+		}
+		return h
+	}
+
+	resources, errs := ParseResourceRequirements(requests, limits, fldPath.Child("resources"))
+	if len(errs) > 0 {
+		if h.err == nil {
+			h.err = errors.Wrapf(errs.ToAggregate(), "Error when parse resources of container %s", name)
+		}
+		return h
+	}
+
+	*c = *NewContainerBuilder().
+		WithContainer(c).
+		WithResource(resources, opts...).
+		Container()
+
+	return h
+}
+
+// validateContainerResources permit to check that quantities are positive, can be marshaled, and requests are not greater than limits
+func validateContainerResources(resources *corev1.ResourceRequirements, fldPath *field.Path) (errs field.ErrorList) {
+	errs = field.ErrorList{}
+
+	validate := func(list corev1.ResourceList, listPath *field.Path) {
+		names := make([]string, 0, len(list))
+		for name := range list {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			q := list[corev1.ResourceName(name)]
+			if err := validQuantity(q); err != nil {
+				errs = append(errs, field.Invalid(listPath.Key(name), "", err.Error()))
+				continue
+			}
+			if q.Sign() < 0 {
+				errs = append(errs, field.Invalid(listPath.Key(name), q.String(), "must be greater than or equal to 0"))
+			}
+		}
+	}
+	validate(resources.Requests, fldPath.Child("requests"))
+	validate(resources.Limits, fldPath.Child("limits"))
+
+	names := make([]string, 0, len(resources.Requests))
+	for name := range resources.Requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request := resources.Requests[corev1.ResourceName(name)]
+		if limit, ok := resources.Limits[corev1.ResourceName(name)]; ok && validQuantity(request) == nil && validQuantity(limit) == nil && request.Cmp(limit) > 0 {
+			errs = append(errs, field.Invalid(fldPath.Child("requests").Key(name), request.String(), fmt.Sprintf("must be less than or equal to %s limit of %s", name, limit.String())))
+		}
+	}
+
+	return errs
+}
+
+// validQuantity permit to check that quantity can be marshaled and parsed back
+// Quantities merged from other objects can be broken, and they panic when they are marshaled.
+func validQuantity(q resource.Quantity) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("invalid quantity: %v", r)
+		}
+	}()
+
+	_, err = resource.ParseQuantity(q.String())
+
+	return err
+}
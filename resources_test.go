@@ -0,0 +1,113 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNormalizeResources(t *testing.T) {
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("1"),
+		},
+	}
+
+	NormalizeResources(resources, &ResourceNormalization{
+		LimitRatios: map[corev1.ResourceName]float64{
+			corev1.ResourceMemory: 2,
+		},
+	})
+	assert.True(t, resource.MustParse("1").Equal(resources.Requests[corev1.ResourceCPU]))
+	assert.True(t, resource.MustParse("512Mi").Equal(resources.Limits[corev1.ResourceMemory]))
+
+	resources = &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+	NormalizeResources(resources, &ResourceNormalization{
+		RequestRatios: map[corev1.ResourceName]float64{
+			corev1.ResourceCPU: 0.5,
+		},
+		RequestsFromLimits: true,
+	})
+	assert.True(t, resource.MustParse("500m").Equal(resources.Requests[corev1.ResourceCPU]))
+	assert.True(t, resource.MustParse("1Gi").Equal(resources.Requests[corev1.ResourceMemory]))
+}
+
+func TestParseResourceRequirements(t *testing.T) {
+	// When valid
+	resources, errs := ParseResourceRequirements(
+		map[string]string{"cpu": "500m", "memory": "256Mi"},
+		map[string]string{"memory": "1Gi"},
+		field.NewPath("resources"),
+	)
+	assert.Empty(t, errs)
+	assert.True(t, resource.MustParse("500m").Equal(resources.Requests[corev1.ResourceCPU]))
+	assert.True(t, resource.MustParse("1Gi").Equal(resources.Limits[corev1.ResourceMemory]))
+
+	// When invalid
+	_, errs = ParseResourceRequirements(
+		map[string]string{"cpu": "half", "memory": "256Mi"},
+		map[string]string{"memory": "1 Gi"},
+		field.NewPath("resources"),
+	)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	assert.Equal(t, []string{"resources.requests[cpu]", "resources.limits[memory]"}, fields)
+}
+
+func TestPodTemplateBuilderWithContainerResources(t *testing.T) {
+	b := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app"}, {Name: "sidecar"}})
+
+	// When valid
+	pts, err := b.WithContainerResources("sidecar", map[string]string{"cpu": "100m"}, map[string]string{"cpu": "200m"}).Build()
+	assert.NoError(t, err)
+	assert.True(t, resource.MustParse("100m").Equal(pts.Spec.Containers[1].Resources.Requests[corev1.ResourceCPU]))
+	assert.Empty(t, pts.Spec.Containers[0].Resources)
+
+	// When quantity is invalid, the error give the container and the field
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app"}, {Name: "sidecar"}}).
+		WithContainerResources("sidecar", map[string]string{"memory": "12 MB"}, nil).
+		Build()
+	assert.ErrorContains(t, err, "container sidecar")
+	assert.ErrorContains(t, err, "spec.containers[1].resources.requests[memory]")
+
+	// When container not exist
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainerResources("app", map[string]string{"cpu": "1"}, nil).
+		Build()
+	assert.Error(t, err)
+
+	// When requests are greater than limits
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app"}}).
+		WithContainerResources("app", map[string]string{"cpu": "2"}, map[string]string{"cpu": "1"}).
+		Build()
+	assert.ErrorContains(t, err, "spec.containers[0].resources.requests[cpu]")
+
+	// When quantity is negative
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app"}}).
+		WithContainerResources("app", nil, map[string]string{"memory": "-1Gi"}).
+		Build()
+	assert.ErrorContains(t, err, "spec.containers[0].resources.limits[memory]")
+}
@@ -0,0 +1,131 @@
+package k8sbuilder
+
+import (
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Sanitize permit to remove from object the fields managed by API server, so it can be used as base of builder or compared
+// It remove status, managedFields, resourceVersion, uid, generation, creationTimestamp, the last-applied-configuration annotation,
+// the pod template fields that have the API server default value, and set empty lists and maps to nil.
+// Because of the last-applied-configuration annotation is removed, PatchAgainst can't do three-way merge with sanitized live object.
+func Sanitize(o client.Object) {
+	sanitize(o, true)
+}
+
+// sanitize permit to remove from object the fields managed by API server, with or without the status
+func sanitize(o client.Object, withoutStatus bool) {
+	if o == nil {
+		return
+	}
+
+	o.SetManagedFields(nil)
+	o.SetResourceVersion("")
+	o.SetUID("")
+	o.SetGeneration(0)
+	o.SetCreationTimestamp(metav1.Time{})
+	o.SetSelfLink("")
+	if annotations := o.GetAnnotations(); annotations != nil {
+		if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+			o.SetAnnotations(withoutKeys(annotations, []string{corev1.LastAppliedConfigAnnotation}))
+		}
+	}
+
+	if withoutStatus {
+		StripStatus(o)
+	}
+
+	switch object := o.(type) {
+	case *appsv1.Deployment:
+		StripPodTemplateDefaults(&object.Spec.Template)
+	case *appsv1.StatefulSet:
+		StripPodTemplateDefaults(&object.Spec.Template)
+	}
+
+	pruneEmptyCollections(reflect.ValueOf(o))
+}
+
+// StripStatus permit to remove the status of object, because of it's owned by controllers and not by the spec author
+// It handle typed objects with a Status field and unstructured objects.
+func StripStatus(o client.Object) {
+	if o == nil {
+		return
+	}
+
+	if u, ok := o.(*unstructured.Unstructured); ok {
+		unstructured.RemoveNestedField(u.Object, "status")
+		return
+	}
+	value := reflect.ValueOf(o)
+	if value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Struct {
+		if status := value.Elem().FieldByName("Status"); status.IsValid() && status.CanSet() {
+			status.Set(reflect.Zero(status.Type()))
+		}
+	}
+}
+
+// StripPodTemplateDefaults permit to remove the fields of pod template that have the API server default value
+// Only the defaults that never change the behavior are removed, like terminationMessagePath or schedulerName.
+func StripPodTemplateDefaults(pts *corev1.PodTemplateSpec) {
+	if pts == nil {
+		return
+	}
+
+	pts.CreationTimestamp = metav1.Time{}
+	if pts.Spec.SchedulerName == corev1.DefaultSchedulerName {
+		pts.Spec.SchedulerName = ""
+	}
+	if pts.Spec.DNSPolicy == corev1.DNSClusterFirst {
+		pts.Spec.DNSPolicy = ""
+	}
+	if pts.Spec.SecurityContext != nil && reflect.ValueOf(*pts.Spec.SecurityContext).IsZero() {
+		pts.Spec.SecurityContext = nil
+	}
+	for _, c := range allContainers(pts) {
+		if c.TerminationMessagePath == corev1.TerminationMessagePathDefault {
+			c.TerminationMessagePath = ""
+		}
+		if c.TerminationMessagePolicy == corev1.TerminationMessageReadFile {
+			c.TerminationMessagePolicy = ""
+		}
+	}
+}
+
+// pruneEmptyCollections permit to set the empty lists and maps to nil, because of they are the same once serialized
+// Pointers on empty structs are keeped, because of they can have a meaning, like emptyDir volume source.
+func pruneEmptyCollections(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			pruneEmptyCollections(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				pruneEmptyCollections(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		if v.Len() == 0 {
+			if v.CanSet() {
+				v.Set(reflect.Zero(v.Type()))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			pruneEmptyCollections(v.Index(i))
+		}
+	case reflect.Map:
+		if !v.IsNil() && v.Len() == 0 && v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSanitize(t *testing.T) {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			UID:               "uid",
+			ResourceVersion:   "1",
+			Generation:        2,
+			CreationTimestamp: metav1.Now(),
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Labels:            map[string]string{},
+			Annotations:       map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "team": "search"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					SchedulerName:   corev1.DefaultSchedulerName,
+					SecurityContext: &corev1.PodSecurityContext{},
+					Volumes:         []corev1.Volume{{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+					Containers: []corev1.Container{{
+						Name:                   "app",
+						Env:                    []corev1.EnvVar{},
+						TerminationMessagePath: corev1.TerminationMessagePathDefault,
+					}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1},
+	}
+
+	Sanitize(d)
+	assert.Equal(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{"team": "search"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes:    []corev1.Volume{{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+					Containers: []corev1.Container{{Name: "app"}},
+				},
+			},
+		},
+	}, d)
+
+	// Unstructured
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]any{"name": "test", "resourceVersion": "1"},
+		"status":     map[string]any{"phase": "Ready"},
+	}}
+	Sanitize(u)
+	assert.Equal(t, map[string]any{
+		"apiVersion": "example.com/v1",
+		"kind":       "Foo",
+		"metadata":   map[string]any{"name": "test"},
+	}, u.Object)
+}
@@ -0,0 +1,150 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SpotProviderAWS is the spot instances of EKS managed node groups
+	SpotProviderAWS = "aws"
+
+	// SpotProviderGCP is the spot VMs of GKE node pools
+	SpotProviderGCP = "gcp"
+
+	// SpotProviderAzure is the spot node pools of AKS
+	SpotProviderAzure = "azure"
+)
+
+// spotNodeLabels is the node label, and the matching taint, set by each provider on spot nodes
+var spotNodeLabels = map[string]struct {
+	key   string
+	value string
+}{
+	SpotProviderAWS:   {key: "eks.amazonaws.com/capacityType", value: "SPOT"},
+	SpotProviderGCP:   {key: "cloud.google.com/gke-spot", value: "true"},
+	SpotProviderAzure: {key: "kubernetes.azure.com/scalesetpriority", value: "spot"},
+}
+
+// AnnotationImageDigestPrefix is the prefix of annotations that store the image digest of container by architecture
+// The full key is k8sbuilder.io/image-digest.<container>.<arch>
+const AnnotationImageDigestPrefix = "k8sbuilder.io/image-digest."
+
+// WithMultiArchSupport permit to schedule the pod only on nodes with one of architectures, like amd64 or arm64
+// The kubernetes.io/arch requirement is added on all required node selector terms, and replace the existing one.
+func (h *PodTemplateBuilderDefault) WithMultiArchSupport(archs ...string) PodTemplateBuilder {
+	defer h.observe("WithMultiArchSupport")()
+
+	if len(archs) == 0 {
+		return h
+	}
+
+	h.own(sharedAffinity)
+	if h.podTemplate.Spec.Affinity == nil {
+		h.podTemplate.Spec.Affinity = &corev1.Affinity{}
+	}
+	requireNodeSelectorRequirement(h.podTemplate.Spec.Affinity, corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelArchStable,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   archs,
+	})
+
+	return h
+}
+
+// WithImageDigests permit to annotate the pod template with the image digest of container for each architecture
+// Digests is a map of architecture to digest, like amd64: sha256:abc. It permit to audit which image run on mixed clusters.
+func (h *PodTemplateBuilderDefault) WithImageDigests(container string, digests map[string]string) PodTemplateBuilder {
+	defer h.observe("WithImageDigests")()
+
+	if len(digests) == 0 {
+		return h
+	}
+
+	h.own(sharedAnnotations)
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	for arch, digest := range digests {
+		h.podTemplate.Annotations[AnnotationImageDigestPrefix+container+"."+arch] = digest
+	}
+
+	return h
+}
+
+// requireNodeSelectorRequirement permit to add requirement on all required node selector terms
+// Node selector terms are ORed, so the requirement need to be on each of them. The requirement on the same key is replaced.
+func requireNodeSelectorRequirement(affinity *corev1.Affinity, requirement corev1.NodeSelectorRequirement) {
+	if affinity.NodeAffinity == nil {
+		affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+
+	for i := range selector.NodeSelectorTerms {
+		term := &selector.NodeSelectorTerms[i]
+		expressions := make([]corev1.NodeSelectorRequirement, 0, len(term.MatchExpressions)+1)
+		for _, expression := range term.MatchExpressions {
+			if expression.Key != requirement.Key {
+				expressions = append(expressions, expression)
+			}
+		}
+		term.MatchExpressions = append(expressions, *requirement.DeepCopy())
+	}
+}
+
+// WithSpotToleration permit to run the pod on spot nodes of provider (aws, gcp or azure)
+// It add the toleration of the spot nodes taint, and a preferred node affinity on spot nodes, so the pod can still run on other nodes.
+// Tolerations and affinity are merged with the existing ones. Build return error if provider is not supported.
+func (h *PodTemplateBuilderDefault) WithSpotToleration(provider string) PodTemplateBuilder {
+	defer h.observe("WithSpotToleration")()
+
+	spotLabel, ok := spotNodeLabels[provider]
+	if !ok {
+		if h.err == nil {
+			h.err = errors.Errorf("Spot provider %s not supported", provider)
+		}
+		return h
+	}
+
+	h.WithTolerations([]corev1.Toleration{
+		{
+			Key:      spotLabel.key,
+			Operator: corev1.TolerationOpEqual,
+			Value:    spotLabel.value,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}, Merge)
+
+	h.own(sharedAffinity)
+	if h.podTemplate.Spec.Affinity == nil {
+		h.podTemplate.Spec.Affinity = &corev1.Affinity{}
+	}
+	if h.podTemplate.Spec.Affinity.NodeAffinity == nil {
+		h.podTemplate.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	term := corev1.PreferredSchedulingTerm{
+		Weight: 100,
+		Preference: corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{
+					Key:      spotLabel.key,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{spotLabel.value},
+				},
+			},
+		},
+	}
+	nodeAffinity := h.podTemplate.Spec.Affinity.NodeAffinity
+	if !funk.Contains(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term) {
+		nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, term)
+	}
+
+	return h
+}
@@ -0,0 +1,92 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodTemplateWithMultiArchSupport(t *testing.T) {
+	affinity := corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disk", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"s390x"}}}},
+				},
+			},
+		},
+	}
+	expectedAffinity := affinity.DeepCopy()
+
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithAffinity(affinity).
+		WithMultiArchSupport("amd64", "arm64").
+		Build()
+	assert.NoError(t, err)
+	archRequirement := corev1.NodeSelectorRequirement{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64", "arm64"}}
+	assert.Equal(t, []corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disk", Operator: corev1.NodeSelectorOpIn, Values: []string{"ssd"}}, archRequirement}},
+		{MatchExpressions: []corev1.NodeSelectorRequirement{archRequirement}},
+	}, pts.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+	assert.Equal(t, expectedAffinity, &affinity)
+
+	// Without affinity
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithMultiArchSupport("arm64").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.NodeSelectorTerm{
+		{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}}},
+	}, pts.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms)
+}
+
+func TestPodTemplateWithImageDigests(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithAnnotations(map[string]string{"foo": "bar"}).
+		WithImageDigests("app", map[string]string{"amd64": "sha256:aaa", "arm64": "sha256:bbb"}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"foo":                                  "bar",
+		"k8sbuilder.io/image-digest.app.amd64": "sha256:aaa",
+		"k8sbuilder.io/image-digest.app.arm64": "sha256:bbb",
+	}, pts.Annotations)
+}
+
+func TestPodTemplateWithSpotToleration(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithTolerations([]corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}).
+		WithMultiArchSupport("amd64").
+		WithSpotToleration(SpotProviderAzure).
+		WithSpotToleration(SpotProviderAzure).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpExists},
+		{Key: "kubernetes.azure.com/scalesetpriority", Operator: corev1.TolerationOpEqual, Value: "spot", Effect: corev1.TaintEffectNoSchedule},
+	}, pts.Spec.Tolerations)
+	assert.Equal(t, []corev1.PreferredSchedulingTerm{
+		{
+			Weight: 100,
+			Preference: corev1.NodeSelectorTerm{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.azure.com/scalesetpriority", Operator: corev1.NodeSelectorOpIn, Values: []string{"spot"}}},
+			},
+		},
+	}, pts.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+
+	// Required affinity is keeped
+	assert.Len(t, pts.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, 1)
+
+	// When provider is not supported
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithSpotToleration("unknown").
+		Build()
+	assert.ErrorContains(t, err, "Spot provider unknown not supported")
+}
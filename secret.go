@@ -0,0 +1,316 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SecretBuilder is the secret builder interface
+type SecretBuilder interface {
+	WithName(name string, opts ...WithOption) SecretBuilder
+	WithNamespace(namespace string, opts ...WithOption) SecretBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) SecretBuilder
+	WithoutLabels(keys ...string) SecretBuilder
+	WithFinalizer(name string) SecretBuilder
+	WithoutFinalizer(name string) SecretBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) SecretBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) SecretBuilder
+	WithoutAnnotations(keys ...string) SecretBuilder
+	WithType(secretType corev1.SecretType, opts ...WithOption) SecretBuilder
+	WithData(data map[string][]byte, opts ...WithOption) SecretBuilder
+	WithStringData(data map[string]string, opts ...WithOption) SecretBuilder
+	WithTLS(certPEM []byte, keyPEM []byte) SecretBuilder
+	WithBasicAuth(username string, password string) SecretBuilder
+	WithSSHKey(privateKey []byte) SecretBuilder
+	WithCertificateAnnotations() SecretBuilder
+	Certificate() (info *CertificateInfo, err error)
+	WithDefaults(defaults *Defaults) SecretBuilder
+	Secret() *corev1.Secret
+	Build() (s *corev1.Secret, err error)
+}
+
+// SecretBuilderDefault is the default implementation of secret builder
+type SecretBuilderDefault struct {
+	objectMeta
+	secret   *corev1.Secret
+	defaults *Defaults
+	err      error
+}
+
+// NewSecretBuilder permit to init secret builder
+func NewSecretBuilder() SecretBuilder {
+	secret := &corev1.Secret{}
+
+	return &SecretBuilderDefault{
+		objectMeta: newObjectMeta(secret),
+		secret:     secret,
+	}
+}
+
+// Secret permit to get current secret
+func (h *SecretBuilderDefault) Secret() *corev1.Secret {
+	return h.secret
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *SecretBuilderDefault) WithDefaults(defaults *Defaults) SecretBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the secret
+// It fail if the keys needed by the secret type are missing
+func (h *SecretBuilderDefault) Build() (s *corev1.Secret, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	if err = validateSecretType(h.secret); err != nil {
+		return nil, err
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.secret)
+
+	if errs := ValidateMetadata(h.secret); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.secret, nil
+}
+
+func validateSecretType(s *corev1.Secret) (err error) {
+	var requiredKeys []string
+	switch s.Type {
+	case corev1.SecretTypeTLS:
+		requiredKeys = []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}
+	case corev1.SecretTypeBasicAuth:
+		if !hasSecretKey(s, corev1.BasicAuthUsernameKey) && !hasSecretKey(s, corev1.BasicAuthPasswordKey) {
+			return errors.Errorf("Secret of type %s need key %s or %s", s.Type, corev1.BasicAuthUsernameKey, corev1.BasicAuthPasswordKey)
+		}
+	case corev1.SecretTypeSSHAuth:
+		requiredKeys = []string{corev1.SSHAuthPrivateKey}
+	case corev1.SecretTypeDockerConfigJson:
+		requiredKeys = []string{corev1.DockerConfigJsonKey}
+	}
+
+	for _, key := range requiredKeys {
+		if !hasSecretKey(s, key) {
+			return errors.Errorf("Secret of type %s need key %s", s.Type, key)
+		}
+	}
+
+	return nil
+}
+
+func hasSecretKey(s *corev1.Secret, key string) bool {
+	if _, ok := s.Data[key]; ok {
+		return true
+	}
+	_, ok := s.StringData[key]
+
+	return ok
+}
+
+// WithName permit to set name
+func (h *SecretBuilderDefault) WithName(name string, opts ...WithOption) SecretBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *SecretBuilderDefault) WithNamespace(namespace string, opts ...WithOption) SecretBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *SecretBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) SecretBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *SecretBuilderDefault) WithoutLabels(keys ...string) SecretBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *SecretBuilderDefault) WithFinalizer(name string) SecretBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *SecretBuilderDefault) WithoutFinalizer(name string) SecretBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *SecretBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) SecretBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *SecretBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) SecretBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *SecretBuilderDefault) WithoutAnnotations(keys ...string) SecretBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithType permit to set secret type
+func (h *SecretBuilderDefault) WithType(secretType corev1.SecretType, opts ...WithOption) SecretBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.secret.Type == "" {
+		h.secret.Type = secretType
+	}
+
+	return h
+}
+
+// WithData permit to set data
+// On merge, data are merged by key and new values win
+func (h *SecretBuilderDefault) WithData(data map[string][]byte, opts ...WithOption) SecretBuilder {
+
+	var tmpData map[string][]byte
+
+	// Copy to avoid overwrite data
+	if data != nil {
+		tmpData = make(map[string][]byte, len(data))
+		for key, value := range data {
+			tmpData[key] = append([]byte(nil), value...)
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.secret.Data == nil {
+		h.secret.Data = tmpData
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.secret.Data) == 0 {
+		h.secret.Data = tmpData
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for key, value := range tmpData {
+			h.secret.Data[key] = value
+		}
+	}
+
+	return h
+}
+
+// WithStringData permit to set string data
+// On merge, string data are merged by key and new values win
+func (h *SecretBuilderDefault) WithStringData(data map[string]string, opts ...WithOption) SecretBuilder {
+
+	var tmpData map[string]string
+
+	// Copy to avoid overwrite data
+	if data != nil {
+		tmpData = make(map[string]string, len(data))
+		for key, value := range data {
+			tmpData[key] = value
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.secret.StringData == nil {
+		h.secret.StringData = tmpData
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.secret.StringData) == 0 {
+		h.secret.StringData = tmpData
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for key, value := range tmpData {
+			h.secret.StringData[key] = value
+		}
+	}
+
+	return h
+}
+
+// WithTLS permit to set the TLS certificate and key, with type kubernetes.io/tls
+func (h *SecretBuilderDefault) WithTLS(certPEM []byte, keyPEM []byte) SecretBuilder {
+	return h.WithType(corev1.SecretTypeTLS).
+		WithData(map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		}, Merge)
+}
+
+// WithBasicAuth permit to set the username and password, with type kubernetes.io/basic-auth
+func (h *SecretBuilderDefault) WithBasicAuth(username string, password string) SecretBuilder {
+	return h.WithType(corev1.SecretTypeBasicAuth).
+		WithData(map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(username),
+			corev1.BasicAuthPasswordKey: []byte(password),
+		}, Merge)
+}
+
+// WithSSHKey permit to set the SSH private key, with type kubernetes.io/ssh-auth
+func (h *SecretBuilderDefault) WithSSHKey(privateKey []byte) SecretBuilder {
+	return h.WithType(corev1.SecretTypeSSHAuth).
+		WithData(map[string][]byte{
+			corev1.SSHAuthPrivateKey: privateKey,
+		}, Merge)
+}
+
+// Certificate permit to read the certificate set on tls.crt key
+func (h *SecretBuilderDefault) Certificate() (info *CertificateInfo, err error) {
+	certPEM, ok := h.secret.Data[corev1.TLSCertKey]
+	if !ok {
+		certPEM = []byte(h.secret.StringData[corev1.TLSCertKey])
+	}
+
+	return ParseCertificate(certPEM)
+}
+
+// WithCertificateAnnotations permit to merge the fingerprint and expiration date of certificate on annotations
+// Call it after WithTLS. Errors are returned by Build.
+func (h *SecretBuilderDefault) WithCertificateAnnotations() SecretBuilder {
+	info, err := h.Certificate()
+	if err != nil {
+		if h.err == nil {
+			h.err = errors.Wrap(err, "Error when read certificate")
+		}
+		return h
+	}
+
+	return h.WithAnnotations(info.Annotations(), Merge)
+}
@@ -0,0 +1,32 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSecretTypedHelpers(t *testing.T) {
+	// TLS
+	s, err := NewSecretBuilder().WithDefaults(&Defaults{}).WithTLS([]byte("cert"), []byte("key")).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeTLS, s.Type)
+	assert.Equal(t, map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")}, s.Data)
+
+	// Basic auth
+	s, err = NewSecretBuilder().WithDefaults(&Defaults{}).WithBasicAuth("user", "pass").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeBasicAuth, s.Type)
+	assert.Equal(t, map[string][]byte{"username": []byte("user"), "password": []byte("pass")}, s.Data)
+
+	// SSH
+	s, err = NewSecretBuilder().WithDefaults(&Defaults{}).WithSSHKey([]byte("key")).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeSSHAuth, s.Type)
+	assert.Equal(t, map[string][]byte{"ssh-privatekey": []byte("key")}, s.Data)
+
+	// When key is missing for type
+	_, err = NewSecretBuilder().WithType(corev1.SecretTypeTLS).WithData(map[string][]byte{"tls.crt": []byte("cert")}).Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+package k8sbuilder
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// seccompProfile permit to get the seccomp profile and the value of its legacy annotation
+func seccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) (profile *corev1.SeccompProfile, annotation string) {
+	profile = &corev1.SeccompProfile{Type: profileType}
+
+	switch profileType {
+	case corev1.SeccompProfileTypeLocalhost:
+		profile.LocalhostProfile = &localhostProfile
+		annotation = corev1.SeccompLocalhostProfileNamePrefix + localhostProfile
+	case corev1.SeccompProfileTypeUnconfined:
+		annotation = corev1.SeccompProfileNameUnconfined
+	default:
+		annotation = corev1.SeccompProfileRuntimeDefault
+	}
+
+	return profile, annotation
+}
+
+// WithSeccompProfile permit to set the seccomp profile of container. The localhost profile is only used with Localhost type
+func (h *ContainerBuilderDefault) WithSeccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) ContainerBuilder {
+	if h.container.SecurityContext == nil {
+		h.container.SecurityContext = &corev1.SecurityContext{}
+	}
+	h.container.SecurityContext.SeccompProfile, _ = seccompProfile(profileType, localhostProfile)
+
+	return h
+}
+
+// WithSeccompProfile permit to set the seccomp profile of pod. The localhost profile is only used with Localhost type
+// The legacy annotation is set too, for clusters older than 1.19 that ignore the field.
+func (h *PodTemplateBuilderDefault) WithSeccompProfile(profileType corev1.SeccompProfileType, localhostProfile string) PodTemplateBuilder {
+	defer h.observe("WithSeccompProfile")()
+
+	profile, annotation := seccompProfile(profileType, localhostProfile)
+
+	h.own(sharedSecurityContext | sharedAnnotations)
+	if h.podTemplate.Spec.SecurityContext == nil {
+		h.podTemplate.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	h.podTemplate.Spec.SecurityContext.SeccompProfile = profile
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	h.podTemplate.Annotations[corev1.SeccompPodAnnotationKey] = annotation
+
+	return h
+}
+
+// WithContainerSeccompProfile permit to set the seccomp profile of container, with its legacy annotation
+// Containers and init containers are searched by name, so call it after set them.
+func (h *PodTemplateBuilderDefault) WithContainerSeccompProfile(container string, profileType corev1.SeccompProfileType, localhostProfile string) PodTemplateBuilder {
+	defer h.observe("WithContainerSeccompProfile")()
+
+	profile, annotation := seccompProfile(profileType, localhostProfile)
+
+	h.own(sharedInitContainers | sharedContainers | sharedAnnotations)
+	for _, c := range allContainers(h.podTemplate) {
+		if c.Name != container {
+			continue
+		}
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		c.SecurityContext.SeccompProfile = profile.DeepCopy()
+	}
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	h.podTemplate.Annotations[corev1.SeccompContainerAnnotationKeyPrefix+container] = annotation
+
+	return h
+}
+
+// WithAppArmorProfile permit to set the AppArmor profile of container, like runtime/default, unconfined or the name of profile loaded on node
+// The AppArmor profile has no field on this API version, so it's set with the annotation.
+func (h *PodTemplateBuilderDefault) WithAppArmorProfile(container string, profile string) PodTemplateBuilder {
+	defer h.observe("WithAppArmorProfile")()
+
+	if profile != corev1.AppArmorBetaProfileRuntimeDefault && profile != corev1.AppArmorBetaProfileNameUnconfined && !strings.HasPrefix(profile, corev1.AppArmorBetaProfileNamePrefix) {
+		profile = corev1.AppArmorBetaProfileNamePrefix + profile
+	}
+
+	h.own(sharedAnnotations)
+	if h.podTemplate.Annotations == nil {
+		h.podTemplate.Annotations = map[string]string{}
+	}
+	h.podTemplate.Annotations[corev1.AppArmorBetaContainerAnnotationKeyPrefix+container] = profile
+
+	return h
+}
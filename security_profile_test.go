@@ -0,0 +1,37 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestContainerWithSeccompProfile(t *testing.T) {
+	c := NewContainerBuilder().
+		WithSeccompProfile(corev1.SeccompProfileTypeLocalhost, "profiles/audit.json").
+		Container()
+	assert.Equal(t, &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: pointer.String("profiles/audit.json")}, c.SecurityContext.SeccompProfile)
+}
+
+func TestPodTemplateWithSecurityProfiles(t *testing.T) {
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers([]corev1.Container{{Name: "app", Image: "nginx"}, {Name: "sidecar", Image: "envoy"}}).
+		WithSeccompProfile(corev1.SeccompProfileTypeRuntimeDefault, "").
+		WithContainerSeccompProfile("sidecar", corev1.SeccompProfileTypeLocalhost, "profiles/envoy.json").
+		WithAppArmorProfile("app", corev1.AppArmorBetaProfileRuntimeDefault).
+		WithAppArmorProfile("sidecar", "envoy").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}, pts.Spec.SecurityContext.SeccompProfile)
+	assert.Nil(t, pts.Spec.Containers[0].SecurityContext)
+	assert.Equal(t, &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: pointer.String("profiles/envoy.json")}, pts.Spec.Containers[1].SecurityContext.SeccompProfile)
+	assert.Equal(t, map[string]string{
+		"seccomp.security.alpha.kubernetes.io/pod":               "runtime/default",
+		"container.seccomp.security.alpha.kubernetes.io/sidecar": "localhost/profiles/envoy.json",
+		"container.apparmor.security.beta.kubernetes.io/app":     "runtime/default",
+		"container.apparmor.security.beta.kubernetes.io/sidecar": "localhost/envoy",
+	}, pts.Annotations)
+}
@@ -0,0 +1,364 @@
+package k8sbuilder
+
+import (
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// ServiceBuilder is the service builder interface
+type ServiceBuilder interface {
+	WithName(name string, opts ...WithOption) ServiceBuilder
+	WithNamespace(namespace string, opts ...WithOption) ServiceBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) ServiceBuilder
+	WithoutLabels(keys ...string) ServiceBuilder
+	WithFinalizer(name string) ServiceBuilder
+	WithoutFinalizer(name string) ServiceBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ServiceBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) ServiceBuilder
+	WithoutAnnotations(keys ...string) ServiceBuilder
+	WithType(serviceType corev1.ServiceType) ServiceBuilder
+	AsHeadless() ServiceBuilder
+	WithPublishNotReadyAddresses(publish bool) ServiceBuilder
+	WithExternalTrafficPolicy(policy corev1.ServiceExternalTrafficPolicyType, opts ...WithOption) ServiceBuilder
+	WithInternalTrafficPolicy(policy corev1.ServiceInternalTrafficPolicyType, opts ...WithOption) ServiceBuilder
+	WithIPFamilies(families []corev1.IPFamily, opts ...WithOption) ServiceBuilder
+	WithLoadBalancerClass(class string, opts ...WithOption) ServiceBuilder
+	WithSelector(selector map[string]string, opts ...WithOption) ServiceBuilder
+	WithPorts(ports []corev1.ServicePort, opts ...WithOption) ServiceBuilder
+	WithPortsFromPodTemplate(ptb PodTemplateBuilder) ServiceBuilder
+	WithPortsFromContainers(containers []corev1.Container) ServiceBuilder
+	WithDefaults(defaults *Defaults) ServiceBuilder
+	Service() *corev1.Service
+	Build() (s *corev1.Service, err error)
+}
+
+// ServiceBuilderDefault is the default implementation of service builder
+type ServiceBuilderDefault struct {
+	objectMeta
+	service     *corev1.Service
+	podTemplate PodTemplateBuilder
+	containers  []corev1.Container
+	defaults    *Defaults
+	err         error
+}
+
+// NewServiceBuilder permit to init service builder
+func NewServiceBuilder() ServiceBuilder {
+	service := &corev1.Service{}
+
+	return &ServiceBuilderDefault{
+		objectMeta: newObjectMeta(service),
+		service:    service,
+	}
+}
+
+// Service permit to get current service
+func (h *ServiceBuilderDefault) Service() *corev1.Service {
+	return h.service
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *ServiceBuilderDefault) WithDefaults(defaults *Defaults) ServiceBuilder {
+	h.defaults = defaults
+
+	return h
+}
+
+// Build permit to get the service
+// Ports derived from containers are computed here, so they are always in sync with the pod template.
+// Ports set with WithPorts are merged on them by name, so they can override nodePort or targetPort.
+func (h *ServiceBuilderDefault) Build() (s *corev1.Service, err error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	containers := make([]corev1.Container, 0, len(h.containers))
+	containers = append(containers, h.containers...)
+	if h.podTemplate != nil {
+		containers = append(containers, h.podTemplate.PodTemplate().Spec.Containers...)
+	}
+	if len(containers) > 0 {
+		h.service.Spec.Ports = mergeServicePorts(ServicePortsFromContainers(containers), h.service.Spec.Ports)
+	}
+
+	for _, port := range h.service.Spec.Ports {
+		if port.Port == 0 {
+			return nil, errors.Errorf("Port %s on service %s must be set", port.Name, h.service.Name)
+		}
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.service)
+
+	if errs := ValidateMetadata(h.service); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.service, nil
+}
+
+// WithName permit to set name
+func (h *ServiceBuilderDefault) WithName(name string, opts ...WithOption) ServiceBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *ServiceBuilderDefault) WithNamespace(namespace string, opts ...WithOption) ServiceBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *ServiceBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) ServiceBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *ServiceBuilderDefault) WithoutLabels(keys ...string) ServiceBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *ServiceBuilderDefault) WithFinalizer(name string) ServiceBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *ServiceBuilderDefault) WithoutFinalizer(name string) ServiceBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *ServiceBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) ServiceBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *ServiceBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) ServiceBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *ServiceBuilderDefault) WithoutAnnotations(keys ...string) ServiceBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithType permit to set the service type
+func (h *ServiceBuilderDefault) WithType(serviceType corev1.ServiceType) ServiceBuilder {
+	h.service.Spec.Type = serviceType
+
+	return h
+}
+
+// AsHeadless permit to get headless service, without cluster IP
+// It's needed to give a stable network identity to statefulset pods
+func (h *ServiceBuilderDefault) AsHeadless() ServiceBuilder {
+	h.service.Spec.Type = corev1.ServiceTypeClusterIP
+	h.service.Spec.ClusterIP = corev1.ClusterIPNone
+
+	return h
+}
+
+// WithPublishNotReadyAddresses permit to publish DNS records of pods that are not ready
+// It's usefull for cluster bootstrap, when peers need to discover each other before they are ready
+func (h *ServiceBuilderDefault) WithPublishNotReadyAddresses(publish bool) ServiceBuilder {
+	h.service.Spec.PublishNotReadyAddresses = publish
+
+	return h
+}
+
+// WithExternalTrafficPolicy permit to set the external traffic policy
+// With OverwriteIfDefaultValue, the policy is set only if it's empty or Cluster, the value defaulted by the API server
+func (h *ServiceBuilderDefault) WithExternalTrafficPolicy(policy corev1.ServiceExternalTrafficPolicyType, opts ...WithOption) ServiceBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.service.Spec.ExternalTrafficPolicy == "" || h.service.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeCluster {
+		h.service.Spec.ExternalTrafficPolicy = policy
+	}
+
+	return h
+}
+
+// WithInternalTrafficPolicy permit to set the internal traffic policy
+// With OverwriteIfDefaultValue, the policy is set only if it's empty or Cluster, the value defaulted by the API server
+func (h *ServiceBuilderDefault) WithInternalTrafficPolicy(policy corev1.ServiceInternalTrafficPolicyType, opts ...WithOption) ServiceBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.service.Spec.InternalTrafficPolicy == nil || *h.service.Spec.InternalTrafficPolicy == corev1.ServiceInternalTrafficPolicyCluster {
+		h.service.Spec.InternalTrafficPolicy = &policy
+	}
+
+	return h
+}
+
+// WithIPFamilies permit to set the IP families
+// On merge, families not yet present are added
+func (h *ServiceBuilderDefault) WithIPFamilies(families []corev1.IPFamily, opts ...WithOption) ServiceBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || h.service.Spec.IPFamilies == nil {
+		h.service.Spec.IPFamilies = families
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.service.Spec.IPFamilies) == 0 {
+		h.service.Spec.IPFamilies = families
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+	loopFamilies:
+		for _, family := range families {
+			for _, current := range h.service.Spec.IPFamilies {
+				if current == family {
+					continue loopFamilies
+				}
+			}
+			h.service.Spec.IPFamilies = append(h.service.Spec.IPFamilies, family)
+		}
+	}
+
+	return h
+}
+
+// WithLoadBalancerClass permit to set the load balancer class
+func (h *ServiceBuilderDefault) WithLoadBalancerClass(class string, opts ...WithOption) ServiceBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.service.Spec.LoadBalancerClass == nil || *h.service.Spec.LoadBalancerClass == "" {
+		h.service.Spec.LoadBalancerClass = pointer.String(class)
+	}
+
+	return h
+}
+
+// WithSelector permit to set the pod selector
+func (h *ServiceBuilderDefault) WithSelector(selector map[string]string, opts ...WithOption) ServiceBuilder {
+	h.service.Spec.Selector = withMap(h.service.Spec.Selector, selector, opts)
+
+	return h
+}
+
+// WithPorts permit to set ports
+// On merge, ports are merged by name and the non empty fields override the current port
+func (h *ServiceBuilderDefault) WithPorts(ports []corev1.ServicePort, opts ...WithOption) ServiceBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || h.service.Spec.Ports == nil {
+		h.service.Spec.Ports = ports
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.service.Spec.Ports) == 0 {
+		h.service.Spec.Ports = ports
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		h.service.Spec.Ports = mergeServicePorts(h.service.Spec.Ports, ports)
+	}
+
+	return h
+}
+
+// WithPortsFromPodTemplate permit to generate the ports from the named container ports of pod template
+// Containers are read on Build
+func (h *ServiceBuilderDefault) WithPortsFromPodTemplate(ptb PodTemplateBuilder) ServiceBuilder {
+	h.podTemplate = ptb
+
+	return h
+}
+
+// WithPortsFromContainers permit to generate the ports from the named container ports
+func (h *ServiceBuilderDefault) WithPortsFromContainers(containers []corev1.Container) ServiceBuilder {
+	h.containers = append(h.containers, containers...)
+
+	return h
+}
+
+// ServicePortsFromContainers permit to get service ports from the named container ports
+// The target port is the port name, so the container port can change without update the service.
+// Unnamed ports are skipped, and only the first port with a given name is keeped.
+func ServicePortsFromContainers(containers []corev1.Container) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, 0)
+	names := map[string]bool{}
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			if port.Name == "" || names[port.Name] {
+				continue
+			}
+			names[port.Name] = true
+			ports = append(ports, corev1.ServicePort{
+				Name:       port.Name,
+				Port:       port.ContainerPort,
+				TargetPort: intstr.FromString(port.Name),
+				Protocol:   port.Protocol,
+			})
+		}
+	}
+
+	return ports
+}
+
+// mergeServicePorts permit to merge ports by name
+// The non empty fields of ports override the current one
+func mergeServicePorts(current []corev1.ServicePort, ports []corev1.ServicePort) []corev1.ServicePort {
+	merged := make([]corev1.ServicePort, 0, len(current)+len(ports))
+	for _, port := range current {
+		merged = append(merged, *port.DeepCopy())
+	}
+
+loopPorts:
+	for _, port := range ports {
+		for i := range merged {
+			if merged[i].Name != port.Name {
+				continue
+			}
+			if port.Port != 0 {
+				merged[i].Port = port.Port
+			}
+			if port.NodePort != 0 {
+				merged[i].NodePort = port.NodePort
+			}
+			if port.TargetPort != (intstr.IntOrString{}) {
+				merged[i].TargetPort = port.TargetPort
+			}
+			if port.Protocol != "" {
+				merged[i].Protocol = port.Protocol
+			}
+			if port.AppProtocol != nil {
+				merged[i].AppProtocol = port.AppProtocol
+			}
+			continue loopPorts
+		}
+		merged = append(merged, *port.DeepCopy())
+	}
+
+	return merged
+}
@@ -0,0 +1,111 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestServiceBuilder(t *testing.T) {
+	ptb := NewPodTemplateBuilder().WithContainers([]corev1.Container{
+		{
+			Name: "app",
+			Ports: []corev1.ContainerPort{
+				{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+				{ContainerPort: 9000},
+			},
+		},
+		{
+			Name: "sidecar",
+			Ports: []corev1.ContainerPort{
+				{Name: "metrics", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+			},
+		},
+	})
+
+	s, err := NewServiceBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithType(corev1.ServiceTypeNodePort).
+		WithSelector(map[string]string{"app": "test"}).
+		WithPortsFromPodTemplate(ptb).
+		WithPorts([]corev1.ServicePort{
+			{Name: "http", Port: 80, NodePort: 30080},
+			{Name: "admin", Port: 8081, TargetPort: intstr.FromInt(8081)},
+		}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.ServicePort{
+		{Name: "http", Port: 80, NodePort: 30080, TargetPort: intstr.FromString("http"), Protocol: corev1.ProtocolTCP},
+		{Name: "metrics", Port: 9090, TargetPort: intstr.FromString("metrics"), Protocol: corev1.ProtocolTCP},
+		{Name: "admin", Port: 8081, TargetPort: intstr.FromInt(8081)},
+	}, s.Spec.Ports)
+
+	// Container ports are kept in sync when pod template change
+	ptb.PodTemplate().Spec.Containers[1].Ports[0].ContainerPort = 9100
+	s, err = NewServiceBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithPortsFromContainers(ptb.PodTemplate().Spec.Containers).
+		WithPorts([]corev1.ServicePort{{Name: "http", TargetPort: intstr.FromInt(8080)}}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.ServicePort{
+		{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP},
+		{Name: "metrics", Port: 9100, TargetPort: intstr.FromString("metrics"), Protocol: corev1.ProtocolTCP},
+	}, s.Spec.Ports)
+
+	// When port is missing
+	_, err = NewServiceBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithPorts([]corev1.ServicePort{{Name: "http"}}).
+		Build()
+	assert.Error(t, err)
+}
+
+func TestServiceBuilderTrafficPolicy(t *testing.T) {
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	cluster := corev1.ServiceInternalTrafficPolicyCluster
+
+	// Platform defaults are overwritten
+	b := NewServiceBuilder().WithDefaults(&Defaults{}).WithName("test")
+	b.Service().Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	b.Service().Spec.InternalTrafficPolicy = &cluster
+	s, err := b.
+		WithExternalTrafficPolicy(corev1.ServiceExternalTrafficPolicyTypeLocal, OverwriteIfDefaultValue).
+		WithInternalTrafficPolicy(corev1.ServiceInternalTrafficPolicyLocal, OverwriteIfDefaultValue).
+		WithIPFamilies([]corev1.IPFamily{corev1.IPv4Protocol}, OverwriteIfDefaultValue).
+		WithLoadBalancerClass("internal", OverwriteIfDefaultValue).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyTypeLocal, s.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, &local, s.Spec.InternalTrafficPolicy)
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv4Protocol}, s.Spec.IPFamilies)
+	assert.Equal(t, "internal", *s.Spec.LoadBalancerClass)
+
+	// User values are keeped
+	s, err = b.
+		WithExternalTrafficPolicy(corev1.ServiceExternalTrafficPolicyTypeCluster, OverwriteIfDefaultValue).
+		WithInternalTrafficPolicy(corev1.ServiceInternalTrafficPolicyCluster, OverwriteIfDefaultValue).
+		WithIPFamilies([]corev1.IPFamily{corev1.IPv6Protocol}, OverwriteIfDefaultValue).
+		WithLoadBalancerClass("external", OverwriteIfDefaultValue).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyTypeLocal, s.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, &local, s.Spec.InternalTrafficPolicy)
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv4Protocol}, s.Spec.IPFamilies)
+	assert.Equal(t, "internal", *s.Spec.LoadBalancerClass)
+
+	// Merge and overwrite
+	s, err = b.
+		WithIPFamilies([]corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}, Merge).
+		WithLoadBalancerClass("external").
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}, s.Spec.IPFamilies)
+	assert.Equal(t, "external", *s.Spec.LoadBalancerClass)
+}
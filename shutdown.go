@@ -0,0 +1,58 @@
+package k8sbuilder
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// PreStopSleep permit to get the preStop hook that sleep before the container receive SIGTERM
+// It let the time to load balancers and endpoints to remove the pod, so no requests are sent to a stopping container.
+// The container image need the sleep command, so it not work with distroless or shell-less images.
+// The native sleep lifecycle action need Kubernetes 1.29, and is not available on the API version used by this package.
+func PreStopSleep(seconds int64) *corev1.LifecycleHandler {
+	return &corev1.LifecycleHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"sleep", fmt.Sprintf("%d", seconds)},
+		},
+	}
+}
+
+// WithGracefulShutdown permit to set the termination grace period and a preStop sleep hook on containers, for zero-downtime rollouts
+// The hook is set on the given containers, or on all containers if not provided. Init containers are keeped as is.
+// The hook need the sleep command on container image (see PreStopSleep). For distroless or shell-less images,
+// set preStopSleepSeconds to 0: only the grace period is set, and the application must wait before stop on SIGTERM.
+// Build return error if the grace period is not greater than the preStop sleep, because of the container need time to stop after the sleep.
+func (h *PodTemplateBuilderDefault) WithGracefulShutdown(periodSeconds int64, preStopSleepSeconds int64, containers ...string) PodTemplateBuilder {
+	defer h.observe("WithGracefulShutdown")()
+
+	if periodSeconds <= preStopSleepSeconds {
+		if h.err == nil {
+			h.err = errors.Errorf("Termination grace period (%ds) must be greater than preStop sleep (%ds)", periodSeconds, preStopSleepSeconds)
+		}
+		return h
+	}
+
+	h.podTemplate.Spec.TerminationGracePeriodSeconds = pointer.Int64(periodSeconds)
+
+	if preStopSleepSeconds <= 0 {
+		return h
+	}
+
+	h.own(sharedContainers)
+	for i := range h.podTemplate.Spec.Containers {
+		c := &h.podTemplate.Spec.Containers[i]
+		if len(containers) > 0 && !funk.ContainsString(containers, c.Name) {
+			continue
+		}
+		if c.Lifecycle == nil {
+			c.Lifecycle = &corev1.Lifecycle{}
+		}
+		c.Lifecycle.PreStop = PreStopSleep(preStopSleepSeconds)
+	}
+
+	return h
+}
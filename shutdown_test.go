@@ -0,0 +1,60 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestWithGracefulShutdown(t *testing.T) {
+	postStart := &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"init"}}}
+	containers := []corev1.Container{
+		{Name: "app", Lifecycle: &corev1.Lifecycle{PostStart: postStart}},
+		{Name: "sidecar"},
+	}
+
+	// All containers
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers(containers).
+		WithGracefulShutdown(60, 15).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Int64(60), pts.Spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, PreStopSleep(15), pts.Spec.Containers[0].Lifecycle.PreStop)
+	assert.Equal(t, postStart, pts.Spec.Containers[0].Lifecycle.PostStart)
+	assert.Equal(t, []string{"sleep", "15"}, pts.Spec.Containers[1].Lifecycle.PreStop.Exec.Command)
+	// Original containers are not modified
+	assert.Nil(t, containers[0].Lifecycle.PreStop)
+
+	// Selected containers
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers(containers).
+		WithGracefulShutdown(60, 15, "sidecar").
+		Build()
+	assert.NoError(t, err)
+	assert.Nil(t, pts.Spec.Containers[0].Lifecycle.PreStop)
+	assert.NotNil(t, pts.Spec.Containers[1].Lifecycle.PreStop)
+
+	// Without preStop hook, for images without sleep command
+	pts, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers(containers).
+		WithGracefulShutdown(60, 0).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, pointer.Int64(60), pts.Spec.TerminationGracePeriodSeconds)
+	assert.Nil(t, pts.Spec.Containers[0].Lifecycle.PreStop)
+	assert.Nil(t, pts.Spec.Containers[1].Lifecycle)
+
+	// Grace period need to be greater than preStop sleep
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithContainers(containers).
+		WithGracefulShutdown(10, 10).
+		Build()
+	assert.ErrorContains(t, err, "must be greater than preStop sleep")
+}
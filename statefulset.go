@@ -0,0 +1,467 @@
+package k8sbuilder
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StatefulSetBuilder is the statefulset builder interface
+type StatefulSetBuilder interface {
+	WithName(name string, opts ...WithOption) StatefulSetBuilder
+	WithNamespace(namespace string, opts ...WithOption) StatefulSetBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) StatefulSetBuilder
+	WithoutLabels(keys ...string) StatefulSetBuilder
+	WithFinalizer(name string) StatefulSetBuilder
+	WithoutFinalizer(name string) StatefulSetBuilder
+	WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) StatefulSetBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) StatefulSetBuilder
+	WithoutAnnotations(keys ...string) StatefulSetBuilder
+	WithReplicas(nb int32, opts ...WithOption) StatefulSetBuilder
+	WithServiceName(serviceName string, opts ...WithOption) StatefulSetBuilder
+	WithUpdateStrategy(strategy appsv1.StatefulSetUpdateStrategy, opts ...WithOption) StatefulSetBuilder
+	WithSelector(selector *metav1.LabelSelector, opts ...WithOption) StatefulSetBuilder
+	WithVolumeClaimTemplates(pvcs []corev1.PersistentVolumeClaim, opts ...WithOption) StatefulSetBuilder
+	WithPodTemplate(fn func(ptb PodTemplateBuilder)) StatefulSetBuilder
+	WithDefaults(defaults *Defaults) StatefulSetBuilder
+	WithHeadlessService(fn func(sb ServiceBuilder)) StatefulSetBuilder
+	VolumeClaimTemplate(name string) VolumeClaimTemplateBuilder
+	PodTemplate() PodTemplateBuilder
+	StatefulSet() *appsv1.StatefulSet
+	Build() (sts *appsv1.StatefulSet, err error)
+	BuilderSet() *BuilderSet
+}
+
+// VolumeClaimTemplateBuilder is the fluent builder of one volume claim template of statefulset
+type VolumeClaimTemplateBuilder interface {
+	WithStorage(size string) VolumeClaimTemplateBuilder
+	WithStorageClass(storageClassName string) VolumeClaimTemplateBuilder
+	WithAccessModes(accessModes ...corev1.PersistentVolumeAccessMode) VolumeClaimTemplateBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) VolumeClaimTemplateBuilder
+	WithoutLabels(keys ...string) VolumeClaimTemplateBuilder
+	StatefulSet() StatefulSetBuilder
+}
+
+// StatefulSetBuilderDefault is the default implementation of statefulset builder
+type StatefulSetBuilderDefault struct {
+	objectMeta
+	statefulSet     *appsv1.StatefulSet
+	podTemplate     PodTemplateBuilder
+	headlessService ServiceBuilder
+	defaults        *Defaults
+	err             error
+}
+
+// VolumeClaimTemplateBuilderDefault is the default implementation of volume claim template builder
+type VolumeClaimTemplateBuilderDefault struct {
+	sts  *StatefulSetBuilderDefault
+	name string
+}
+
+// NewStatefulSetBuilder permit to init statefulset builder
+func NewStatefulSetBuilder() StatefulSetBuilder {
+	statefulSet := &appsv1.StatefulSet{}
+
+	return &StatefulSetBuilderDefault{
+		objectMeta:  newObjectMeta(statefulSet),
+		statefulSet: statefulSet,
+		podTemplate: NewPodTemplateBuilder(),
+	}
+}
+
+// PodTemplate permit to get the pod template sub-builder
+// Changes done on it are set on statefulset on Build
+func (h *StatefulSetBuilderDefault) PodTemplate() PodTemplateBuilder {
+	return h.podTemplate
+}
+
+// WithPodTemplate permit to change the pod template without break the fluent chain
+func (h *StatefulSetBuilderDefault) WithPodTemplate(fn func(ptb PodTemplateBuilder)) StatefulSetBuilder {
+	if fn != nil {
+		fn(h.podTemplate)
+	}
+
+	return h
+}
+
+// WithHeadlessService permit to emit the governing headless service with the statefulset on BuilderSet
+// The service name, namespace, labels, selector and ports are derived from the statefulset if not set by fn.
+// The service name default to the statefulset name.
+func (h *StatefulSetBuilderDefault) WithHeadlessService(fn func(sb ServiceBuilder)) StatefulSetBuilder {
+	if h.headlessService == nil {
+		h.headlessService = NewServiceBuilder().AsHeadless()
+		if h.defaults != nil {
+			h.headlessService.WithDefaults(h.defaults)
+		}
+	}
+	if fn != nil {
+		fn(h.headlessService)
+	}
+
+	return h
+}
+
+// BuilderSet permit to get the builder set of the statefulset and its headless service, if any
+// The statefulset is built only one time by the set, and the headless service is derived from the built statefulset,
+// so the set can be built with BuildAll. Get a new set after change the builder.
+func (h *StatefulSetBuilderDefault) BuilderSet() *BuilderSet {
+	var once sync.Once
+	var sts *appsv1.StatefulSet
+	var stsErr error
+	buildStatefulSet := func() (*appsv1.StatefulSet, error) {
+		once.Do(func() {
+			if h.headlessService != nil {
+				h.WithServiceName(h.statefulSet.Name, OverwriteIfDefaultValue)
+			}
+			sts, stsErr = h.Build()
+		})
+		return sts, stsErr
+	}
+
+	set := NewBuilderSet(func() (client.Object, error) {
+		return buildStatefulSet()
+	})
+
+	if h.headlessService != nil {
+		set.Add(func() (client.Object, error) {
+			sts, err := buildStatefulSet()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error when build statefulset of headless service")
+			}
+			h.headlessService.
+				WithName(sts.Spec.ServiceName, OverwriteIfDefaultValue).
+				WithNamespace(sts.Namespace, OverwriteIfDefaultValue).
+				WithLabels(mergeMap(nil, sts.Labels), OverwriteIfDefaultValue).
+				WithSelector(mergeMap(nil, sts.Spec.Selector.MatchLabels), OverwriteIfDefaultValue).
+				WithPortsFromPodTemplate(h.podTemplate)
+			return h.headlessService.Build()
+		})
+	}
+
+	return set
+}
+
+// VolumeClaimTemplate permit to get the sub-builder of the volume claim template with this name
+// The volume claim template is created if not exist, for exemple:
+// sts.VolumeClaimTemplate("data").WithStorage("50Gi").WithStorageClass("fast")
+func (h *StatefulSetBuilderDefault) VolumeClaimTemplate(name string) VolumeClaimTemplateBuilder {
+	if h.volumeClaimTemplate(name) == nil {
+		h.statefulSet.Spec.VolumeClaimTemplates = append(h.statefulSet.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+		})
+	}
+
+	return &VolumeClaimTemplateBuilderDefault{
+		sts:  h,
+		name: name,
+	}
+}
+
+// StatefulSet permit to get current statefulset
+func (h *StatefulSetBuilderDefault) StatefulSet() *appsv1.StatefulSet {
+	return h.statefulSet
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+// They are also used by the pod template sub-builder
+func (h *StatefulSetBuilderDefault) WithDefaults(defaults *Defaults) StatefulSetBuilder {
+	h.defaults = defaults
+	h.podTemplate.WithDefaults(defaults)
+	if h.headlessService != nil {
+		h.headlessService.WithDefaults(defaults)
+	}
+
+	return h
+}
+
+// Build permit to get the statefulset with the pod template built by the sub-builder
+// If no selector is set, it's derived from the stable pod template labels (see PodTemplateBuilder.Selector), else it must match them
+func (h *StatefulSetBuilderDefault) Build() (sts *appsv1.StatefulSet, err error) {
+	defer observeBuild("StatefulSet", time.Now())
+
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	pts, err := h.podTemplate.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when build pod template")
+	}
+	h.statefulSet.Spec.Template = *pts.DeepCopy()
+
+	if h.statefulSet.Spec.Selector == nil {
+		if h.statefulSet.Spec.Selector, err = h.podTemplate.Selector(); err != nil {
+			return nil, errors.Wrap(err, "Error when derive selector")
+		}
+	} else if err = ValidateSelector(h.statefulSet.Spec.Selector, pts.Labels); err != nil {
+		return nil, err
+	}
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(h.statefulSet)
+
+	if errs := ValidateMetadata(h.statefulSet); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return h.statefulSet, nil
+}
+
+// WithName permit to set name
+func (h *StatefulSetBuilderDefault) WithName(name string, opts ...WithOption) StatefulSetBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *StatefulSetBuilderDefault) WithNamespace(namespace string, opts ...WithOption) StatefulSetBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *StatefulSetBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) StatefulSetBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *StatefulSetBuilderDefault) WithoutLabels(keys ...string) StatefulSetBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *StatefulSetBuilderDefault) WithFinalizer(name string) StatefulSetBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *StatefulSetBuilderDefault) WithoutFinalizer(name string) StatefulSetBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithOwner permit to set owner reference, or controller reference if controller is true
+// Namespace must be set before, because of namespaced owner must be on the same namespace. The error is returned on Build.
+func (h *StatefulSetBuilderDefault) WithOwner(owner metav1.Object, scheme *runtime.Scheme, controller bool) StatefulSetBuilder {
+	if err := h.setOwner(owner, scheme, controller); err != nil && h.err == nil {
+		h.err = errors.Wrap(err, "Error when set owner")
+	}
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *StatefulSetBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) StatefulSetBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *StatefulSetBuilderDefault) WithoutAnnotations(keys ...string) StatefulSetBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithReplicas permit to set replicas
+func (h *StatefulSetBuilderDefault) WithReplicas(nb int32, opts ...WithOption) StatefulSetBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.statefulSet.Spec.Replicas == nil {
+		h.statefulSet.Spec.Replicas = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithServiceName permit to set the headless service name
+func (h *StatefulSetBuilderDefault) WithServiceName(serviceName string, opts ...WithOption) StatefulSetBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.statefulSet.Spec.ServiceName == "" {
+		h.statefulSet.Spec.ServiceName = serviceName
+	}
+
+	return h
+}
+
+// WithUpdateStrategy permit to set update strategy
+func (h *StatefulSetBuilderDefault) WithUpdateStrategy(strategy appsv1.StatefulSetUpdateStrategy, opts ...WithOption) StatefulSetBuilder {
+	// Overwrite
+	if IsOverwrite(opts) {
+		h.statefulSet.Spec.UpdateStrategy = strategy
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && reflect.ValueOf(h.statefulSet.Spec.UpdateStrategy).IsZero() {
+		h.statefulSet.Spec.UpdateStrategy = strategy
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		if err := MergeK8s(&h.statefulSet.Spec.UpdateStrategy, h.statefulSet.Spec.UpdateStrategy, strategy); err != nil {
+			panic(err)
+		}
+	}
+
+	return h
+}
+
+// WithSelector permit to set selector
+// If not set, it's derived from the stable pod template labels on Build
+func (h *StatefulSetBuilderDefault) WithSelector(selector *metav1.LabelSelector, opts ...WithOption) StatefulSetBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.statefulSet.Spec.Selector == nil {
+		h.statefulSet.Spec.Selector = selector
+	}
+
+	return h
+}
+
+// WithVolumeClaimTemplates permit to set volume claim templates
+// On merge, volume claim templates are merged by name
+func (h *StatefulSetBuilderDefault) WithVolumeClaimTemplates(pvcs []corev1.PersistentVolumeClaim, opts ...WithOption) StatefulSetBuilder {
+
+	var tmpPvcs []corev1.PersistentVolumeClaim
+
+	// To avoid to overwrite volume claim templates
+	if pvcs != nil {
+		tmpPvcs = make([]corev1.PersistentVolumeClaim, 0, len(pvcs))
+		for _, pvc := range pvcs {
+			tmpPvcs = append(tmpPvcs, *pvc.DeepCopy())
+		}
+	}
+
+	// Overwrite
+	if IsOverwrite(opts) || h.statefulSet.Spec.VolumeClaimTemplates == nil {
+		h.statefulSet.Spec.VolumeClaimTemplates = tmpPvcs
+		return h
+	}
+
+	// Overwrite only if not default
+	if IsOverwriteIfDefaultValue(opts) && len(h.statefulSet.Spec.VolumeClaimTemplates) == 0 {
+		h.statefulSet.Spec.VolumeClaimTemplates = tmpPvcs
+		return h
+	}
+
+	// Merge
+	if IsMerge(opts) {
+		for _, pvc := range tmpPvcs {
+			index := funk.IndexOf(h.statefulSet.Spec.VolumeClaimTemplates, func(o corev1.PersistentVolumeClaim) bool {
+				return pvc.Name == o.Name
+			})
+			if index == -1 {
+				h.statefulSet.Spec.VolumeClaimTemplates = append(h.statefulSet.Spec.VolumeClaimTemplates, pvc)
+			} else {
+				if err := MergeK8s(&h.statefulSet.Spec.VolumeClaimTemplates[index], h.statefulSet.Spec.VolumeClaimTemplates[index], pvc); err != nil {
+					panic(err)
+				}
+			}
+		}
+	}
+
+	return h
+}
+
+func (h *StatefulSetBuilderDefault) volumeClaimTemplate(name string) *corev1.PersistentVolumeClaim {
+	for i := range h.statefulSet.Spec.VolumeClaimTemplates {
+		if h.statefulSet.Spec.VolumeClaimTemplates[i].Name == name {
+			return &h.statefulSet.Spec.VolumeClaimTemplates[i]
+		}
+	}
+
+	return nil
+}
+
+// pvc permit to get the current volume claim template
+// It's searched on each call, because of the volume claim templates can be replaced on statefulset builder
+func (h *VolumeClaimTemplateBuilderDefault) pvc() *corev1.PersistentVolumeClaim {
+	pvc := h.sts.volumeClaimTemplate(h.name)
+	if pvc == nil {
+		h.sts.VolumeClaimTemplate(h.name)
+		pvc = h.sts.volumeClaimTemplate(h.name)
+	}
+
+	return pvc
+}
+
+// WithStorage permit to set the storage request, like "50Gi"
+// If size is not a valid quantity, the error is returned by Build with the volume claim template field.
+func (h *VolumeClaimTemplateBuilderDefault) WithStorage(size string) VolumeClaimTemplateBuilder {
+	pvc := h.pvc()
+
+	requests, errs := ParseResourceList(map[string]string{string(corev1.ResourceStorage): size}, field.NewPath("spec", "volumeClaimTemplates").Key(h.name).Child("spec", "resources", "requests"))
+	if len(errs) > 0 {
+		if h.sts.err == nil {
+			h.sts.err = errors.Wrapf(errs.ToAggregate(), "Error when set storage of volume claim template %s", h.name)
+		}
+		return h
+	}
+
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = requests[corev1.ResourceStorage]
+
+	return h
+}
+
+// WithStorageClass permit to set storage class name
+func (h *VolumeClaimTemplateBuilderDefault) WithStorageClass(storageClassName string) VolumeClaimTemplateBuilder {
+	h.pvc().Spec.StorageClassName = pointer.String(storageClassName)
+
+	return h
+}
+
+// WithAccessModes permit to set access modes
+func (h *VolumeClaimTemplateBuilderDefault) WithAccessModes(accessModes ...corev1.PersistentVolumeAccessMode) VolumeClaimTemplateBuilder {
+	h.pvc().Spec.AccessModes = accessModes
+
+	return h
+}
+
+// WithLabels permit to set labels of volume claim template
+func (h *VolumeClaimTemplateBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) VolumeClaimTemplateBuilder {
+	pvc := h.pvc()
+	pvc.Labels = withMap(pvc.Labels, labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *VolumeClaimTemplateBuilderDefault) WithoutLabels(keys ...string) VolumeClaimTemplateBuilder {
+	pvc := h.pvc()
+	pvc.Labels = withoutKeys(pvc.Labels, keys)
+
+	return h
+}
+
+// StatefulSet permit to go back on statefulset builder
+func (h *VolumeClaimTemplateBuilderDefault) StatefulSet() StatefulSetBuilder {
+	return h.sts
+}
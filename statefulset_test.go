@@ -0,0 +1,147 @@
+package k8sbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestStatefulSetVolumeClaimTemplateInvalidStorage(t *testing.T) {
+	_, err := NewStatefulSetBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		VolumeClaimTemplate("data").WithStorage("50 GB").
+		StatefulSet().
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		}).
+		Build()
+	assert.ErrorContains(t, err, "volume claim template data")
+	assert.ErrorContains(t, err, "spec.volumeClaimTemplates[data].spec.resources.requests[storage]")
+}
+
+func TestStatefulSetVolumeClaimTemplate(t *testing.T) {
+	b := NewStatefulSetBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithVolumeClaimTemplates([]corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				},
+			},
+		}).
+		VolumeClaimTemplate("data").WithStorage("50Gi").WithStorageClass("fast").
+		StatefulSet().
+		VolumeClaimTemplate("logs").WithStorage("1Gi").
+		StatefulSet().
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+
+	sts, err := b.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "data"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: pointer.String("fast"),
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("50Gi")},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	}, sts.Spec.VolumeClaimTemplates)
+	assert.Equal(t, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}}, sts.Spec.Selector)
+
+	// Merge by claim name
+	b.WithVolumeClaimTemplates([]corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: pointer.String("slow"),
+			},
+		},
+	}, Merge)
+	assert.Len(t, b.StatefulSet().Spec.VolumeClaimTemplates, 2)
+	assert.Equal(t, pointer.String("slow"), b.StatefulSet().Spec.VolumeClaimTemplates[1].Spec.StorageClassName)
+	assert.Equal(t, resource.MustParse("1Gi"), b.StatefulSet().Spec.VolumeClaimTemplates[1].Spec.Resources.Requests[corev1.ResourceStorage])
+}
+
+func TestStatefulSetBuilderSet(t *testing.T) {
+	b := NewStatefulSetBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithNamespace("default").
+		WithLabels(map[string]string{"app": "test"}).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"}).
+				WithContainers([]corev1.Container{{
+					Name:  "app",
+					Ports: []corev1.ContainerPort{{Name: "peer", ContainerPort: 7000}},
+				}})
+		}).
+		WithHeadlessService(func(sb ServiceBuilder) {
+			sb.WithPublishNotReadyAddresses(true)
+		})
+
+	objects, err := b.BuilderSet().Build()
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	assert.Equal(t, "test", objects[0].(*appsv1.StatefulSet).Spec.ServiceName)
+
+	s := objects[1].(*corev1.Service)
+	assert.Equal(t, "test", s.Name)
+	assert.Equal(t, "default", s.Namespace)
+	assert.Equal(t, corev1.ClusterIPNone, s.Spec.ClusterIP)
+	assert.True(t, s.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, map[string]string{"app": "test"}, s.Spec.Selector)
+	assert.Equal(t, []corev1.ServicePort{{Name: "peer", Port: 7000, TargetPort: intstr.FromString("peer")}}, s.Spec.Ports)
+
+	// Without headless service
+	objects, err = NewStatefulSetBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		}).
+		BuilderSet().
+		Build()
+	assert.NoError(t, err)
+	assert.Len(t, objects, 1)
+
+	// With BuildAll, the headless service not depend on the builders order
+	for i := 0; i < 100; i++ {
+		objects, err = NewStatefulSetBuilder().
+			WithDefaults(&Defaults{}).
+			WithName("test").
+			WithNamespace("default").
+			WithPodTemplate(func(ptb PodTemplateBuilder) {
+				ptb.WithLabels(map[string]string{"app": "test"})
+			}).
+			WithHeadlessService(nil).
+			BuilderSet().
+			BuildAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, objects, 2)
+		assert.Equal(t, "test", objects[1].GetName())
+		assert.Equal(t, map[string]string{"app": "test"}, objects[1].(*corev1.Service).Spec.Selector)
+	}
+}
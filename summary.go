@@ -0,0 +1,210 @@
+package k8sbuilder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SummarizePodTemplateChanges permit to get a short human-readable description of changes between two pod templates
+// like "container es: image updated, 2 env added; volume certs added". It's suitable for Kubernetes events.
+// It return empty string if there are no changes.
+func SummarizePodTemplateChanges(before, after *corev1.PodTemplateSpec) string {
+	if before == nil {
+		before = &corev1.PodTemplateSpec{}
+	}
+	if after == nil {
+		after = &corev1.PodTemplateSpec{}
+	}
+
+	parts := make([]string, 0)
+	if changes := summarizeMap("label", before.Labels, after.Labels); len(changes) > 0 {
+		parts = append(parts, strings.Join(changes, ", "))
+	}
+	if changes := summarizeMap("annotation", before.Annotations, after.Annotations); len(changes) > 0 {
+		parts = append(parts, strings.Join(changes, ", "))
+	}
+	parts = append(parts, summarizeContainers("init container", before.Spec.InitContainers, after.Spec.InitContainers)...)
+	parts = append(parts, summarizeContainers("container", before.Spec.Containers, after.Spec.Containers)...)
+
+	added, removed, updated := diffByName(before.Spec.Volumes, after.Spec.Volumes, func(v corev1.Volume) string { return v.Name })
+	for _, name := range added {
+		parts = append(parts, fmt.Sprintf("volume %s added", name))
+	}
+	for _, name := range removed {
+		parts = append(parts, fmt.Sprintf("volume %s removed", name))
+	}
+	for _, name := range updated {
+		parts = append(parts, fmt.Sprintf("volume %s updated", name))
+	}
+
+	// Other pod spec fields are only reported as updated
+	fields := make([]string, 0)
+	beforeSpec, afterSpec := reflect.ValueOf(before.Spec), reflect.ValueOf(after.Spec)
+	for i := 0; i < beforeSpec.NumField(); i++ {
+		field := beforeSpec.Type().Field(i)
+		switch field.Name {
+		case "InitContainers", "Containers", "Volumes":
+			continue
+		}
+		if !reflect.DeepEqual(beforeSpec.Field(i).Interface(), afterSpec.Field(i).Interface()) {
+			fields = append(fields, jsonFieldName(field))
+		}
+	}
+	if len(fields) > 0 {
+		parts = append(parts, fmt.Sprintf("%s updated", strings.Join(fields, ", ")))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// summarizeContainers permit to get the description of changes of each container
+func summarizeContainers(kind string, before, after []corev1.Container) []string {
+	parts := make([]string, 0)
+	name := func(c corev1.Container) string { return c.Name }
+
+	added, removed, updated := diffByName(before, after, name)
+	for _, name := range added {
+		parts = append(parts, fmt.Sprintf("%s %s added", kind, name))
+	}
+	for _, name := range removed {
+		parts = append(parts, fmt.Sprintf("%s %s removed", kind, name))
+	}
+	for _, containerName := range updated {
+		b := before[indexOfContainer(before, containerName)]
+		a := after[indexOfContainer(after, containerName)]
+		if changes := summarizeContainer(b, a); len(changes) > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s: %s", kind, containerName, strings.Join(changes, ", ")))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s updated", kind, containerName))
+		}
+	}
+
+	return parts
+}
+
+// summarizeContainer permit to get the list of changes of container
+func summarizeContainer(before, after corev1.Container) []string {
+	changes := make([]string, 0)
+	if before.Image != after.Image {
+		changes = append(changes, "image updated")
+	}
+	added, removed, updated := diffByName(before.Env, after.Env, func(e corev1.EnvVar) string { return e.Name })
+	changes = append(changes, summarizeCounts("env", added, removed, updated)...)
+	added, removed, updated = diffByName(before.Ports, after.Ports, func(p corev1.ContainerPort) string {
+		return fmt.Sprintf("%d/%s", p.ContainerPort, portProtocol(p))
+	})
+	changes = append(changes, summarizeCounts("port", added, removed, updated)...)
+	added, removed, updated = diffByName(before.VolumeMounts, after.VolumeMounts, func(vm corev1.VolumeMount) string { return vm.MountPath })
+	changes = append(changes, summarizeCounts("volume mount", added, removed, updated)...)
+
+	// Other container fields are only reported as updated
+	beforeValue, afterValue := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < beforeValue.NumField(); i++ {
+		field := beforeValue.Type().Field(i)
+		switch field.Name {
+		case "Name", "Image", "Env", "Ports", "VolumeMounts":
+			continue
+		}
+		if !reflect.DeepEqual(beforeValue.Field(i).Interface(), afterValue.Field(i).Interface()) {
+			changes = append(changes, fmt.Sprintf("%s updated", jsonFieldName(field)))
+		}
+	}
+
+	return changes
+}
+
+// summarizeMap permit to get the counts of added, removed and updated keys
+func summarizeMap(kind string, before, after map[string]string) []string {
+	var added, removed, updated []string
+	for key, value := range after {
+		if beforeValue, ok := before[key]; !ok {
+			added = append(added, key)
+		} else if beforeValue != value {
+			updated = append(updated, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	return summarizeCounts(kind, added, removed, updated)
+}
+
+// summarizeCounts permit to get the description of count of added, removed and updated items, like "2 env added"
+func summarizeCounts(kind string, added, removed, updated []string) []string {
+	changes := make([]string, 0)
+	if len(added) > 0 {
+		changes = append(changes, fmt.Sprintf("%d %s added", len(added), pluralize(kind, len(added))))
+	}
+	if len(removed) > 0 {
+		changes = append(changes, fmt.Sprintf("%d %s removed", len(removed), pluralize(kind, len(removed))))
+	}
+	if len(updated) > 0 {
+		changes = append(changes, fmt.Sprintf("%d %s updated", len(updated), pluralize(kind, len(updated))))
+	}
+
+	return changes
+}
+
+// pluralize permit to add the plural mark on kind, except for env that is invariable
+func pluralize(kind string, count int) string {
+	if count <= 1 || kind == "env" {
+		return kind
+	}
+
+	return kind + "s"
+}
+
+// diffByName permit to get the names of items added, removed and updated between two lists
+// Names are returned in list order.
+func diffByName[T any](before, after []T, name func(T) string) (added, removed, updated []string) {
+	beforeItems := make(map[string]T, len(before))
+	for _, item := range before {
+		beforeItems[name(item)] = item
+	}
+	afterItems := make(map[string]T, len(after))
+	for _, item := range after {
+		afterItems[name(item)] = item
+	}
+
+	for _, item := range after {
+		beforeItem, ok := beforeItems[name(item)]
+		if !ok {
+			added = append(added, name(item))
+		} else if !reflect.DeepEqual(beforeItem, item) {
+			updated = append(updated, name(item))
+		}
+	}
+	for _, item := range before {
+		if _, ok := afterItems[name(item)]; !ok {
+			removed = append(removed, name(item))
+		}
+	}
+
+	return added, removed, updated
+}
+
+// jsonFieldName permit to get the JSON name of struct field
+func jsonFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+// Summary permit to get a short human-readable description of changes done on pod template since the first Merge of WithPodTemplateSpec
+// Use it to explain on events why the operator update a workload. See SummarizePodTemplateChanges.
+// It return empty string if WithPodTemplateSpec was not called with Merge.
+func (h *PodTemplateBuilderDefault) Summary() string {
+	if h.mergeBase == nil {
+		return ""
+	}
+
+	return SummarizePodTemplateChanges(h.mergeBase, h.podTemplate)
+}
@@ -0,0 +1,57 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizePodTemplateChanges(t *testing.T) {
+	before := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "es"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "es", Image: "es:8.0", Env: []corev1.EnvVar{{Name: "A", Value: "a"}}},
+				{Name: "old"},
+			},
+			Volumes: []corev1.Volume{{Name: "data"}},
+		},
+	}
+	after := before.DeepCopy()
+	after.Labels["version"] = "8.1"
+	after.Spec.Containers = []corev1.Container{
+		{Name: "es", Image: "es:8.1", Env: []corev1.EnvVar{{Name: "A", Value: "a"}, {Name: "B", Value: "b"}, {Name: "C", Value: "c"}}, WorkingDir: "/usr/share"},
+	}
+	after.Spec.Volumes = append(after.Spec.Volumes, corev1.Volume{Name: "certs"})
+	after.Spec.ServiceAccountName = "es"
+
+	assert.Equal(t,
+		"1 label added; container old removed; container es: image updated, 2 env added, workingDir updated; volume certs added; serviceAccountName updated",
+		SummarizePodTemplateChanges(before, after),
+	)
+	assert.Empty(t, SummarizePodTemplateChanges(before, before.DeepCopy()))
+}
+
+func TestPodTemplateBuilderSummary(t *testing.T) {
+	live := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:1"}},
+		},
+	}
+	expected := &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "sidecar", Image: "sidecar:1"}},
+		},
+	}
+
+	// Without merge
+	b := NewPodTemplateBuilder().WithPodTemplateSpec(live)
+	assert.Empty(t, b.Summary())
+
+	b.WithPodTemplateSpec(expected, Merge)
+	assert.Equal(t, "1 label added; container sidecar added", b.Summary())
+	assert.Equal(t, "app:1", live.Spec.Containers[0].Image)
+}
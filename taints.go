@@ -0,0 +1,82 @@
+package k8sbuilder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/thoas/go-funk"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// transientTaintPrefixes is the prefixes of taints set by Kubernetes on node conditions, like not-ready or unschedulable
+// They are not tolerated, because of pods must not be scheduled on unhealthy nodes.
+var transientTaintPrefixes = []string{
+	"node.kubernetes.io/",
+	"node.cloudprovider.kubernetes.io/",
+}
+
+// TolerationsForTaints permit to get the tolerations that match taints
+// Taints with value are tolerated with Equal operator, others with Exists operator.
+// Taints set by Kubernetes on node conditions are ignored, and duplicated tolerations are removed.
+func TolerationsForTaints(taints []corev1.Taint) []corev1.Toleration {
+	var tolerations []corev1.Toleration
+
+loopTaint:
+	for _, taint := range taints {
+		for _, prefix := range transientTaintPrefixes {
+			if strings.HasPrefix(taint.Key, prefix) {
+				continue loopTaint
+			}
+		}
+
+		toleration := corev1.Toleration{
+			Key:      taint.Key,
+			Operator: corev1.TolerationOpExists,
+			Effect:   taint.Effect,
+		}
+		if taint.Value != "" {
+			toleration.Operator = corev1.TolerationOpEqual
+			toleration.Value = taint.Value
+		}
+
+		if !funk.Contains(tolerations, toleration) {
+			tolerations = append(tolerations, toleration)
+		}
+	}
+
+	return tolerations
+}
+
+// TolerationsForNodePool permit to get the tolerations that match the taints of nodes selected by label selector
+func TolerationsForNodePool(ctx context.Context, c client.Reader, selector labels.Selector) (tolerations []corev1.Toleration, err error) {
+	nodes := &corev1.NodeList{}
+	if err = c.List(ctx, nodes, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrapf(err, "Error when list nodes with selector %s", selector.String())
+	}
+
+	taints := make([]corev1.Taint, 0)
+	for _, node := range nodes.Items {
+		taints = append(taints, node.Spec.Taints...)
+	}
+
+	return TolerationsForTaints(taints), nil
+}
+
+// WithTolerationsForNodePool permit to merge the tolerations that match the taints of nodes selected by label selector
+// Build fail if nodes can't be read.
+func (h *PodTemplateBuilderDefault) WithTolerationsForNodePool(ctx context.Context, c client.Reader, selector labels.Selector) PodTemplateBuilder {
+	defer h.observe("WithTolerationsForNodePool")()
+
+	tolerations, err := TolerationsForNodePool(ctx, c, selector)
+	if err != nil {
+		if h.err == nil {
+			h.err = err
+		}
+		return h
+	}
+
+	return h.WithTolerations(tolerations, Merge)
+}
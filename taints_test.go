@@ -0,0 +1,70 @@
+package k8sbuilder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTolerationsForTaints(t *testing.T) {
+	tolerations := TolerationsForTaints([]corev1.Taint{
+		{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+		{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule},
+	})
+
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "db", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	}, tolerations)
+	assert.Nil(t, TolerationsForTaints(nil))
+}
+
+func TestWithTolerationsForNodePool(t *testing.T) {
+	nodes := []runtime.Object{
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-1", Labels: map[string]string{"pool": "db"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-2", Labels: map[string]string{"pool": "db"}},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "dedicated", Value: "db", Effect: corev1.TaintEffectNoSchedule},
+				{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute},
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-1", Labels: map[string]string{"pool": "default"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(nodes...).Build()
+	existing := corev1.Toleration{Key: "spot", Operator: corev1.TolerationOpExists}
+
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithTolerations([]corev1.Toleration{existing}).
+		WithTolerationsForNodePool(context.Background(), c, labels.SelectorFromSet(labels.Set{"pool": "db"})).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.Toleration{
+		existing,
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "db", Effect: corev1.TaintEffectNoSchedule},
+	}, pts.Spec.Tolerations)
+
+	// Build fail when nodes can't be read
+	c = fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	_, err = NewPodTemplateBuilder().
+		WithDefaults(&Defaults{}).
+		WithTolerationsForNodePool(context.Background(), c, labels.Everything()).
+		Build()
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package k8sbuilder
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// LabelVariant is the label set on variant deployment, its pod template and its selector
+	LabelVariant = "k8sbuilder.io/variant"
+
+	// AnnotationVariantWeight is the annotation that contain the weight of variant, to be read by traffic routers
+	AnnotationVariantWeight = "k8sbuilder.io/weight"
+)
+
+// Variant is one variant of deployment, like stable or canary
+// Override is merged on the base deployment, so only fields to change need to be set on it.
+type Variant struct {
+	Name     string
+	Weight   int32
+	Override *appsv1.Deployment
+}
+
+// StableAndCanary permit to get the common stable and canary variants
+// The canary get canaryWeight percent of traffic and the stable get the rest.
+func StableAndCanary(canaryWeight int32, stable, canary *appsv1.Deployment) []Variant {
+	return []Variant{
+		{
+			Name:     "stable",
+			Weight:   100 - canaryWeight,
+			Override: stable,
+		},
+		{
+			Name:     "canary",
+			Weight:   canaryWeight,
+			Override: canary,
+		},
+	}
+}
+
+// Variants permit to build the base deployment and generate one deployment per variant
+// Each variant get the name suffix and the variant label on deployment, pod template and selector, so they not overlap.
+// The weights must be between 0 and 100, and their sum must be 100.
+func (h *DeploymentBuilderDefault) Variants(variants ...Variant) (deployments []*appsv1.Deployment, err error) {
+	if len(variants) == 0 {
+		return nil, errors.New("Variants can't be empty")
+	}
+
+	var totalWeight int32
+	names := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if variant.Name == "" {
+			return nil, errors.New("Variant name can't be empty")
+		}
+		if names[variant.Name] {
+			return nil, errors.Errorf("Variant %s is duplicated", variant.Name)
+		}
+		names[variant.Name] = true
+		if variant.Weight < 0 || variant.Weight > 100 {
+			return nil, errors.Errorf("Weight of variant %s must be between 0 and 100", variant.Name)
+		}
+		totalWeight += variant.Weight
+	}
+	if totalWeight != 100 {
+		return nil, errors.Errorf("Sum of variant weights must be 100, found %d", totalWeight)
+	}
+
+	base, err := h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	deployments = make([]*appsv1.Deployment, 0, len(variants))
+	for _, variant := range variants {
+		d := base.DeepCopy()
+		if variant.Override != nil {
+			if err = MergeK8s(d, d, variant.Override); err != nil {
+				return nil, errors.Wrapf(err, "Error when merge override of variant %s", variant.Name)
+			}
+		}
+
+		d.Name = base.Name + "-" + variant.Name
+		d.Labels = withKey(d.Labels, LabelVariant, variant.Name)
+		d.Annotations = withKey(d.Annotations, AnnotationVariantWeight, strconv.Itoa(int(variant.Weight)))
+		d.Spec.Template.Labels = withKey(d.Spec.Template.Labels, LabelVariant, variant.Name)
+		if d.Spec.Selector != nil {
+			d.Spec.Selector.MatchLabels = withKey(d.Spec.Selector.MatchLabels, LabelVariant, variant.Name)
+		}
+
+		deployments = append(deployments, d)
+	}
+
+	return deployments, nil
+}
+
+func withKey(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[key] = value
+
+	return m
+}
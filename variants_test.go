@@ -0,0 +1,58 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestDeploymentVariants(t *testing.T) {
+	b := NewDeploymentBuilder().
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithReplicas(9).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"}).
+				WithContainers([]corev1.Container{{Name: "test", Image: "test:1.0.0"}})
+		})
+
+	deployments, err := b.Variants(StableAndCanary(10, nil, &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(1),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test", Image: "test:2.0.0"}},
+				},
+			},
+		},
+	})...)
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 2)
+
+	stable := deployments[0]
+	assert.Equal(t, "test-stable", stable.Name)
+	assert.Equal(t, "90", stable.Annotations[AnnotationVariantWeight])
+	assert.Equal(t, int32(9), *stable.Spec.Replicas)
+	assert.Equal(t, "test:1.0.0", stable.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, map[string]string{"app": "test", LabelVariant: "stable"}, stable.Spec.Selector.MatchLabels)
+	assert.Equal(t, map[string]string{"app": "test", LabelVariant: "stable"}, stable.Spec.Template.Labels)
+
+	canary := deployments[1]
+	assert.Equal(t, "test-canary", canary.Name)
+	assert.Equal(t, "10", canary.Annotations[AnnotationVariantWeight])
+	assert.Equal(t, map[string]string{LabelVariant: "canary"}, canary.Labels)
+	assert.Equal(t, int32(1), *canary.Spec.Replicas)
+	assert.Equal(t, "test:2.0.0", canary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, map[string]string{"app": "test", LabelVariant: "canary"}, canary.Spec.Selector.MatchLabels)
+
+	// When weights not sum to 100
+	_, err = b.Variants(Variant{Name: "stable", Weight: 50})
+	assert.Error(t, err)
+
+	// When duplicated variant
+	_, err = b.Variants(Variant{Name: "stable", Weight: 50}, Variant{Name: "stable", Weight: 50})
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package k8sbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ admission.Handler = &PodMutatingWebhook{}
+
+// PodMutatingWebhook is a mutating admission handler that apply a builder pipeline on incoming pods
+// So the same layers and defaults can be used by the operator and by the webhook.
+type PodMutatingWebhook struct {
+	layers   []podTemplateLayer
+	defaults *Defaults
+}
+
+// podTemplateLayer is a step of pipeline: a pod template merged with option, or a function that use the builder
+type podTemplateLayer struct {
+	podTemplate *corev1.PodTemplateSpec
+	opts        []WithOption
+	fn          func(ptb PodTemplateBuilder)
+}
+
+// NewPodMutatingWebhook permit to init the pod mutating webhook
+// Register it with `mgr.GetWebhookServer().Register(path, &webhook.Admission{Handler: h})`
+func NewPodMutatingWebhook() *PodMutatingWebhook {
+	return &PodMutatingWebhook{
+		layers: make([]podTemplateLayer, 0),
+	}
+}
+
+// WithLayer permit to add pod template applied on incoming pods, in registration order
+// Default option is Merge, so only the fields set on the layer are changed.
+func (h *PodMutatingWebhook) WithLayer(pts *corev1.PodTemplateSpec, opts ...WithOption) *PodMutatingWebhook {
+	if len(opts) == 0 {
+		opts = []WithOption{Merge}
+	}
+	h.layers = append(h.layers, podTemplateLayer{podTemplate: pts, opts: opts})
+
+	return h
+}
+
+// WithPodTemplate permit to add function applied on the builder of incoming pods, in registration order
+func (h *PodMutatingWebhook) WithPodTemplate(fn func(ptb PodTemplateBuilder)) *PodMutatingWebhook {
+	h.layers = append(h.layers, podTemplateLayer{fn: fn})
+
+	return h
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+func (h *PodMutatingWebhook) WithDefaults(defaults *Defaults) *PodMutatingWebhook {
+	h.defaults = defaults
+
+	return h
+}
+
+// Mutate permit to apply the pipeline on pod
+// It's used by Handle, and can be used directly to test the pipeline.
+func (h *PodMutatingWebhook) Mutate(pod *corev1.Pod) (mutated *corev1.Pod, err error) {
+	ptb := NewPodTemplateBuilder().
+		WithPodTemplateSpec(&corev1.PodTemplateSpec{
+			ObjectMeta: pod.ObjectMeta,
+			Spec:       pod.Spec,
+		})
+	if h.defaults != nil {
+		ptb.WithDefaults(h.defaults)
+	}
+
+	for _, layer := range h.layers {
+		if layer.fn != nil {
+			layer.fn(ptb)
+		} else {
+			ptb.WithPodTemplateSpec(layer.podTemplate, layer.opts...)
+		}
+	}
+
+	pts, err := ptb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	mutated = pod.DeepCopy()
+	mutated.ObjectMeta = *pts.ObjectMeta.DeepCopy()
+	mutated.Spec = *pts.Spec.DeepCopy()
+
+	return mutated, nil
+}
+
+// Handle permit to mutate the incoming pod and respond with the JSON patch
+// Other kinds are allowed without changes.
+func (h *PodMutatingWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Group != "" || req.Kind.Kind != "Pod" {
+		return admission.Allowed("Not a pod")
+	}
+
+	pod := &corev1.Pod{}
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, "Error when decode pod"))
+	}
+
+	mutated, err := h.Mutate(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, "Error when build pod"))
+	}
+
+	mutatedRaw, err := json.Marshal(mutated)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, "Error when encode pod"))
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutatedRaw)
+}
@@ -0,0 +1,72 @@
+package k8sbuilder
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestPodMutatingWebhook(t *testing.T) {
+	h := NewPodMutatingWebhook().
+		WithDefaults(&Defaults{}).
+		WithLayer(&corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "search"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Env: []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}}}},
+			},
+		}).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithNodeSelector(map[string]string{"pool": "search"})
+		})
+
+	pod := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:1"}},
+		},
+	}
+
+	// Mutate
+	mutated, err := h.Mutate(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", mutated.Name)
+	assert.Equal(t, map[string]string{"team": "search"}, mutated.Labels)
+	assert.Equal(t, map[string]string{"pool": "search"}, mutated.Spec.NodeSelector)
+	assert.Len(t, mutated.Spec.Containers, 1)
+	assert.Equal(t, "app:1", mutated.Spec.Containers[0].Image)
+	assert.Equal(t, []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}}, mutated.Spec.Containers[0].Env)
+	// Original pod is not modified
+	assert.Nil(t, pod.Labels)
+
+	// Handle pod
+	raw, err := json.Marshal(pod)
+	assert.NoError(t, err)
+	res := h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object: runtime.RawExtension{Raw: raw},
+	}})
+	assert.True(t, res.Allowed)
+	assert.NotEmpty(t, res.Patches)
+
+	// Handle other kinds
+	res = h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind: metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}})
+	assert.True(t, res.Allowed)
+	assert.Empty(t, res.Patches)
+
+	// Invalid pod
+	res = h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Object: runtime.RawExtension{Raw: []byte("{")},
+	}})
+	assert.False(t, res.Allowed)
+}
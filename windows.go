@@ -0,0 +1,73 @@
+package k8sbuilder
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WithWindowsOptions permit to set the Windows options of container security context, like the GMSA credential spec or the user name
+func (h *ContainerBuilderDefault) WithWindowsOptions(options *corev1.WindowsSecurityContextOptions) ContainerBuilder {
+	if h.container.SecurityContext == nil {
+		h.container.SecurityContext = &corev1.SecurityContext{}
+	}
+	h.container.SecurityContext.WindowsOptions = options
+
+	return h
+}
+
+// WithWindowsOptions permit to set the Windows options of pod security context, applied on all containers
+func (h *PodTemplateBuilderDefault) WithWindowsOptions(options *corev1.WindowsSecurityContextOptions) PodTemplateBuilder {
+	defer h.observe("WithWindowsOptions")()
+
+	h.own(sharedSecurityContext)
+	if h.podTemplate.Spec.SecurityContext == nil {
+		h.podTemplate.Spec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	h.podTemplate.Spec.SecurityContext.WindowsOptions = options
+
+	return h
+}
+
+// ForWindows permit to run the pod on Windows nodes
+// It set the pod OS and the kubernetes.io/os node selector. The Linux only fields are removed on Build,
+// after the defaults are applied, so the Linux security context of defaults or policies not reject the pod.
+func (h *PodTemplateBuilderDefault) ForWindows() PodTemplateBuilder {
+	defer h.observe("ForWindows")()
+
+	h.windows = true
+	h.podTemplate.Spec.OS = &corev1.PodOS{Name: corev1.Windows}
+	h.WithNodeSelector(map[string]string{corev1.LabelOSStable: string(corev1.Windows)}, Merge)
+
+	return h
+}
+
+// removeLinuxOnlyFields permit to remove the fields not supported when pod OS is windows
+func removeLinuxOnlyFields(pts *corev1.PodTemplateSpec) {
+	pts.Spec.HostPID = false
+	pts.Spec.HostIPC = false
+	pts.Spec.ShareProcessNamespace = nil
+
+	if sc := pts.Spec.SecurityContext; sc != nil {
+		sc.SELinuxOptions = nil
+		sc.SeccompProfile = nil
+		sc.RunAsUser = nil
+		sc.RunAsGroup = nil
+		sc.FSGroup = nil
+		sc.FSGroupChangePolicy = nil
+		sc.SupplementalGroups = nil
+		sc.Sysctls = nil
+	}
+
+	for _, c := range allContainers(pts) {
+		if sc := c.SecurityContext; sc != nil {
+			sc.SELinuxOptions = nil
+			sc.SeccompProfile = nil
+			sc.Capabilities = nil
+			sc.ReadOnlyRootFilesystem = nil
+			sc.Privileged = nil
+			sc.AllowPrivilegeEscalation = nil
+			sc.ProcMount = nil
+			sc.RunAsUser = nil
+			sc.RunAsGroup = nil
+		}
+	}
+}
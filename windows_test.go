@@ -0,0 +1,47 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestContainerWithWindowsOptions(t *testing.T) {
+	options := &corev1.WindowsSecurityContextOptions{RunAsUserName: pointer.String("ContainerUser")}
+	c := NewContainerBuilder().
+		WithWindowsOptions(options).
+		Container()
+	assert.Equal(t, options, c.SecurityContext.WindowsOptions)
+}
+
+func TestPodTemplateForWindows(t *testing.T) {
+	options := &corev1.WindowsSecurityContextOptions{GMSACredentialSpecName: pointer.String("gmsa")}
+	pts, err := NewPodTemplateBuilder().
+		WithDefaults(&Defaults{
+			SecurityContext: &corev1.SecurityContext{
+				RunAsNonRoot:           pointer.Bool(true),
+				ReadOnlyRootFilesystem: pointer.Bool(true),
+				Capabilities:           &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		}).
+		ForWindows().
+		WithNodeSelector(map[string]string{"pool": "win"}, Merge).
+		WithSecurityContext(&corev1.PodSecurityContext{
+			RunAsUser:    pointer.Int64(1000),
+			FSGroup:      pointer.Int64(1000),
+			RunAsNonRoot: pointer.Bool(true),
+		}).
+		WithWindowsOptions(options).
+		WithContainers([]corev1.Container{{Name: "app", Image: "mcr.microsoft.com/windows/servercore"}}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, &corev1.PodOS{Name: corev1.Windows}, pts.Spec.OS)
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "windows", "pool": "win"}, pts.Spec.NodeSelector)
+	assert.Equal(t, &corev1.PodSecurityContext{
+		RunAsNonRoot:   pointer.Bool(true),
+		WindowsOptions: options,
+	}, pts.Spec.SecurityContext)
+	assert.Equal(t, &corev1.SecurityContext{RunAsNonRoot: pointer.Bool(true)}, pts.Spec.Containers[0].SecurityContext)
+}
@@ -0,0 +1,350 @@
+package k8sbuilder
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadKind is the kind of object emitted by workload builder
+type WorkloadKind string
+
+const (
+	WorkloadDeployment  WorkloadKind = "Deployment"
+	WorkloadStatefulSet WorkloadKind = "StatefulSet"
+	WorkloadDaemonSet   WorkloadKind = "DaemonSet"
+)
+
+// WorkloadUpdateStrategyType is the update strategy shared by all workload kinds
+type WorkloadUpdateStrategyType string
+
+const (
+	// WorkloadRollingUpdate is supported by all workload kinds
+	WorkloadRollingUpdate WorkloadUpdateStrategyType = "RollingUpdate"
+
+	// WorkloadRecreate is only supported by Deployment
+	WorkloadRecreate WorkloadUpdateStrategyType = "Recreate"
+
+	// WorkloadOnDelete is only supported by StatefulSet and DaemonSet
+	WorkloadOnDelete WorkloadUpdateStrategyType = "OnDelete"
+)
+
+// WorkloadUpdateStrategy is the update strategy translated to each workload kind on Build
+// MaxSurge is ignored for StatefulSet and Partition is only used by StatefulSet.
+type WorkloadUpdateStrategy struct {
+	Type           WorkloadUpdateStrategyType
+	MaxUnavailable *intstr.IntOrString
+	MaxSurge       *intstr.IntOrString
+	Partition      *int32
+}
+
+// WorkloadBuilder is the builder interface of workload where the kind is a mode
+// It permit to offer the kind of workload as option without duplicate code for each kind.
+type WorkloadBuilder interface {
+	WithKind(kind WorkloadKind) WorkloadBuilder
+	WithName(name string, opts ...WithOption) WorkloadBuilder
+	WithNamespace(namespace string, opts ...WithOption) WorkloadBuilder
+	WithLabels(labels map[string]string, opts ...WithOption) WorkloadBuilder
+	WithoutLabels(keys ...string) WorkloadBuilder
+	WithFinalizer(name string) WorkloadBuilder
+	WithoutFinalizer(name string) WorkloadBuilder
+	WithAnnotations(annotations map[string]string, opts ...WithOption) WorkloadBuilder
+	WithoutAnnotations(keys ...string) WorkloadBuilder
+	WithReplicas(nb int32, opts ...WithOption) WorkloadBuilder
+	WithUpdateStrategy(strategy WorkloadUpdateStrategy) WorkloadBuilder
+	WithSelector(selector *metav1.LabelSelector, opts ...WithOption) WorkloadBuilder
+	WithServiceName(serviceName string, opts ...WithOption) WorkloadBuilder
+	WithPodTemplate(fn func(ptb PodTemplateBuilder)) WorkloadBuilder
+	WithDefaults(defaults *Defaults) WorkloadBuilder
+	PodTemplate() PodTemplateBuilder
+	Build() (o client.Object, err error)
+	PodDisruptionBudget(minAvailablePercent int32) (pdb PodDisruptionBudgetBuilder, err error)
+}
+
+// WorkloadBuilderDefault is the default implementation of workload builder
+type WorkloadBuilderDefault struct {
+	objectMeta
+	kind           WorkloadKind
+	replicas       *int32
+	updateStrategy *WorkloadUpdateStrategy
+	selector       *metav1.LabelSelector
+	serviceName    string
+	podTemplate    PodTemplateBuilder
+	defaults       *Defaults
+}
+
+// NewWorkloadBuilder permit to init workload builder for the kind
+func NewWorkloadBuilder(kind WorkloadKind) WorkloadBuilder {
+	return &WorkloadBuilderDefault{
+		objectMeta:  newObjectMeta(&metav1.ObjectMeta{}),
+		kind:        kind,
+		podTemplate: NewPodTemplateBuilder(),
+	}
+}
+
+// WithKind permit to change the kind of workload
+func (h *WorkloadBuilderDefault) WithKind(kind WorkloadKind) WorkloadBuilder {
+	h.kind = kind
+
+	return h
+}
+
+// PodTemplate permit to get the pod template sub-builder
+func (h *WorkloadBuilderDefault) PodTemplate() PodTemplateBuilder {
+	return h.podTemplate
+}
+
+// WithPodTemplate permit to change the pod template without break the fluent chain
+func (h *WorkloadBuilderDefault) WithPodTemplate(fn func(ptb PodTemplateBuilder)) WorkloadBuilder {
+	if fn != nil {
+		fn(h.podTemplate)
+	}
+
+	return h
+}
+
+// WithDefaults permit to use own defaults instead the global defaults
+// They are also used by the pod template sub-builder
+func (h *WorkloadBuilderDefault) WithDefaults(defaults *Defaults) WorkloadBuilder {
+	h.defaults = defaults
+	h.podTemplate.WithDefaults(defaults)
+
+	return h
+}
+
+// Build permit to get the Deployment, StatefulSet or DaemonSet, depending of kind
+// It fail if the update strategy is not supported by the kind
+func (h *WorkloadBuilderDefault) Build() (o client.Object, err error) {
+	defer observeBuild("Workload", time.Now())
+
+	pts, err := h.podTemplate.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error when build pod template")
+	}
+
+	selector := h.selector
+	if selector == nil {
+		if selector, err = h.podTemplate.Selector(); err != nil {
+			return nil, errors.Wrap(err, "Error when derive selector")
+		}
+	} else if err = ValidateSelector(selector, pts.Labels); err != nil {
+		return nil, err
+	}
+
+	meta := *h.meta.DeepCopy()
+
+	switch h.kind {
+	case WorkloadDeployment:
+		d := &appsv1.Deployment{
+			ObjectMeta: meta,
+			Spec: appsv1.DeploymentSpec{
+				Replicas: h.replicas,
+				Selector: selector,
+				Template: *pts.DeepCopy(),
+			},
+		}
+		if h.updateStrategy != nil {
+			switch h.updateStrategy.Type {
+			case WorkloadRollingUpdate:
+				d.Spec.Strategy = appsv1.DeploymentStrategy{
+					Type: appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{
+						MaxUnavailable: h.updateStrategy.MaxUnavailable,
+						MaxSurge:       h.updateStrategy.MaxSurge,
+					},
+				}
+			case WorkloadRecreate:
+				d.Spec.Strategy = appsv1.DeploymentStrategy{
+					Type: appsv1.RecreateDeploymentStrategyType,
+				}
+			default:
+				return nil, errors.Errorf("Update strategy %s is not supported by %s", h.updateStrategy.Type, h.kind)
+			}
+		}
+		o = d
+	case WorkloadStatefulSet:
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: meta,
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:    h.replicas,
+				Selector:    selector,
+				Template:    *pts.DeepCopy(),
+				ServiceName: h.serviceName,
+			},
+		}
+		if h.updateStrategy != nil {
+			switch h.updateStrategy.Type {
+			case WorkloadRollingUpdate:
+				sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+						Partition:      h.updateStrategy.Partition,
+						MaxUnavailable: h.updateStrategy.MaxUnavailable,
+					},
+				}
+			case WorkloadOnDelete:
+				sts.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+					Type: appsv1.OnDeleteStatefulSetStrategyType,
+				}
+			default:
+				return nil, errors.Errorf("Update strategy %s is not supported by %s", h.updateStrategy.Type, h.kind)
+			}
+		}
+		o = sts
+	case WorkloadDaemonSet:
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: meta,
+			Spec: appsv1.DaemonSetSpec{
+				Selector: selector,
+				Template: *pts.DeepCopy(),
+			},
+		}
+		if h.updateStrategy != nil {
+			switch h.updateStrategy.Type {
+			case WorkloadRollingUpdate:
+				ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+					Type: appsv1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+						MaxUnavailable: h.updateStrategy.MaxUnavailable,
+						MaxSurge:       h.updateStrategy.MaxSurge,
+					},
+				}
+			case WorkloadOnDelete:
+				ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+					Type: appsv1.OnDeleteDaemonSetStrategyType,
+				}
+			default:
+				return nil, errors.Errorf("Update strategy %s is not supported by %s", h.updateStrategy.Type, h.kind)
+			}
+		}
+		o = ds
+	default:
+		return nil, errors.Errorf("Workload kind %s is not supported", h.kind)
+	}
+
+	o.GetObjectKind().SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(string(h.kind)))
+
+	defaults := h.defaults
+	if defaults == nil {
+		defaults = GlobalDefaults
+	}
+	defaults.ApplyToObject(o)
+
+	if errs := ValidateMetadata(o); len(errs) > 0 {
+		return nil, errors.Wrap(errs.ToAggregate(), "Metadata is invalid")
+	}
+
+	return o, nil
+}
+
+// PodDisruptionBudget permit to build the workload and derive the PDB builder from it
+// See NewPodDisruptionBudgetBuilderFromWorkload to know how minAvailable is computed
+func (h *WorkloadBuilderDefault) PodDisruptionBudget(minAvailablePercent int32) (pdb PodDisruptionBudgetBuilder, err error) {
+	o, err := h.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPodDisruptionBudgetBuilderFromWorkload(o, minAvailablePercent)
+}
+
+// WithName permit to set name
+func (h *WorkloadBuilderDefault) WithName(name string, opts ...WithOption) WorkloadBuilder {
+	h.setName(name, opts)
+
+	return h
+}
+
+// WithNamespace permit to set namespace
+func (h *WorkloadBuilderDefault) WithNamespace(namespace string, opts ...WithOption) WorkloadBuilder {
+	h.setNamespace(namespace, opts)
+
+	return h
+}
+
+// WithLabels permit to set labels
+func (h *WorkloadBuilderDefault) WithLabels(labels map[string]string, opts ...WithOption) WorkloadBuilder {
+	h.setLabels(labels, opts)
+
+	return h
+}
+
+// WithoutLabels permit to remove labels, like the labels inherited from other layers
+func (h *WorkloadBuilderDefault) WithoutLabels(keys ...string) WorkloadBuilder {
+	h.removeLabels(keys)
+
+	return h
+}
+
+// WithFinalizer permit to add finalizer, if not already set
+func (h *WorkloadBuilderDefault) WithFinalizer(name string) WorkloadBuilder {
+	h.addFinalizer(name)
+
+	return h
+}
+
+// WithoutFinalizer permit to remove finalizer, if set
+func (h *WorkloadBuilderDefault) WithoutFinalizer(name string) WorkloadBuilder {
+	h.removeFinalizer(name)
+
+	return h
+}
+
+// WithAnnotations permit to set annotations
+func (h *WorkloadBuilderDefault) WithAnnotations(annotations map[string]string, opts ...WithOption) WorkloadBuilder {
+	h.setAnnotations(annotations, opts)
+
+	return h
+}
+
+// WithoutAnnotations permit to remove annotations, like the annotations inherited from other layers
+func (h *WorkloadBuilderDefault) WithoutAnnotations(keys ...string) WorkloadBuilder {
+	h.removeAnnotations(keys)
+
+	return h
+}
+
+// WithReplicas permit to set replicas
+// It's ignored for DaemonSet
+func (h *WorkloadBuilderDefault) WithReplicas(nb int32, opts ...WithOption) WorkloadBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.replicas == nil {
+		h.replicas = pointer.Int32(nb)
+	}
+
+	return h
+}
+
+// WithUpdateStrategy permit to set the update strategy, translated to each kind on Build
+func (h *WorkloadBuilderDefault) WithUpdateStrategy(strategy WorkloadUpdateStrategy) WorkloadBuilder {
+	h.updateStrategy = &strategy
+
+	return h
+}
+
+// WithSelector permit to set selector
+// If not set, it's derived from the stable pod template labels on Build
+func (h *WorkloadBuilderDefault) WithSelector(selector *metav1.LabelSelector, opts ...WithOption) WorkloadBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.selector == nil {
+		h.selector = selector
+	}
+
+	return h
+}
+
+// WithServiceName permit to set the headless service name
+// It's only used for StatefulSet
+func (h *WorkloadBuilderDefault) WithServiceName(serviceName string, opts ...WithOption) WorkloadBuilder {
+	// Overwrite
+	if IsOverwrite(opts) || IsMerge(opts) || h.serviceName == "" {
+		h.serviceName = serviceName
+	}
+
+	return h
+}
@@ -0,0 +1,59 @@
+package k8sbuilder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestWorkloadBuilder(t *testing.T) {
+	maxUnavailable := intstr.FromInt(1)
+	b := NewWorkloadBuilder(WorkloadDeployment).
+		WithDefaults(&Defaults{}).
+		WithName("test").
+		WithReplicas(3).
+		WithServiceName("test-headless").
+		WithUpdateStrategy(WorkloadUpdateStrategy{
+			Type:           WorkloadRollingUpdate,
+			MaxUnavailable: &maxUnavailable,
+		}).
+		WithPodTemplate(func(ptb PodTemplateBuilder) {
+			ptb.WithLabels(map[string]string{"app": "test"})
+		})
+
+	// Deployment
+	o, err := b.Build()
+	assert.NoError(t, err)
+	d := o.(*appsv1.Deployment)
+	assert.Equal(t, "Deployment", d.Kind)
+	assert.Equal(t, "test", d.Name)
+	assert.Equal(t, pointer.Int32(3), d.Spec.Replicas)
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, d.Spec.Strategy.Type)
+	assert.Equal(t, &maxUnavailable, d.Spec.Strategy.RollingUpdate.MaxUnavailable)
+
+	// StatefulSet
+	o, err = b.WithKind(WorkloadStatefulSet).Build()
+	assert.NoError(t, err)
+	sts := o.(*appsv1.StatefulSet)
+	assert.Equal(t, "test-headless", sts.Spec.ServiceName)
+	assert.Equal(t, pointer.Int32(3), sts.Spec.Replicas)
+	assert.Equal(t, &maxUnavailable, sts.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable)
+
+	// DaemonSet
+	o, err = b.WithKind(WorkloadDaemonSet).Build()
+	assert.NoError(t, err)
+	ds := o.(*appsv1.DaemonSet)
+	assert.Equal(t, map[string]string{"app": "test"}, ds.Spec.Selector.MatchLabels)
+	assert.Equal(t, appsv1.RollingUpdateDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+
+	// When strategy not supported by kind
+	_, err = b.WithUpdateStrategy(WorkloadUpdateStrategy{Type: WorkloadRecreate}).Build()
+	assert.Error(t, err)
+
+	// When unknown kind
+	_, err = b.WithKind("Job").Build()
+	assert.Error(t, err)
+}